@@ -1,39 +1,61 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"people-enricher/data"
 	"people-enricher/internal/adapter/repository"
+	"people-enricher/internal/auth"
 	"people-enricher/internal/client"
 	"people-enricher/internal/config"
+	"people-enricher/internal/entity"
+	"people-enricher/internal/events"
+	"people-enricher/internal/gql"
+	"people-enricher/internal/grpcserver"
 	"people-enricher/internal/handler"
+	"people-enricher/internal/outbox"
+	"people-enricher/internal/ratelimit"
 	"people-enricher/internal/service"
+	"people-enricher/internal/tenant"
+	"people-enricher/internal/webhook"
 	"people-enricher/pkg/database"
 	"people-enricher/pkg/logger"
-	"strconv"
-	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	_ "people-enricher/docs"
 
+	"github.com/sirupsen/logrus"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"google.golang.org/grpc"
 )
 
 // @title           People Information API
 // @version         1.0
-// @description     API for managing and enriching people data
+// @description     API for managing and enriching people data. Requests act on the tenant of their credentials, credentials not bound to a tenant pick one with the X-Tenant-ID header. Clients are rate limited by address and separately for reads and writes (GraphQL queries are reads), see the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers, exceeding a limit answers 429
 // @host            localhost:8080
 // @BasePath        /
 
-func main() {
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description API key or JWT as "Bearer <token>"
 
-	type key string
+// @security ApiKeyAuth
+// @security BearerAuth
 
-	const (
-		personKey key = "personID"
-	)
+func main() {
 	log := logger.NewLogger()
 
 	cfg, err := config.LoadCfg(".env")
@@ -48,60 +70,244 @@ func main() {
 	}
 	defer dbpool.Close()
 
+	appCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	repo := repository.NewPersonRepo(dbpool, log)
-	enricherService := client.NewEnricher(cfg.ExternalAPI, log)
-	personService := service.NewPersonService(*repo, enricherService, log)
+	usageRepo := repository.NewAPIKeyUsageRepo(dbpool, log)
+	providers, fallback, err := enrichmentProviders(cfg, usageRepo, log)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to set up enrichment providers")
+	}
+	rateLimitRepo := repository.NewRateLimitRepo(dbpool, log)
+	var providerLimits ratelimit.Store = ratelimit.NewMemory()
+	if cfg.ProviderLimits.Store == "postgres" {
+		providerLimits = rateLimitRepo
+	}
+	providers = client.Cached(client.Coalesced(client.RateLimited(providers, cfg.ProviderLimits, providerLimits)), cfg.Enrichment.CacheTTL, cfg.Enrichment.CacheSize)
+	fallback = client.Cached(client.Coalesced(client.RateLimited(fallback, cfg.ProviderLimits, providerLimits)), cfg.Enrichment.CacheTTL, cfg.Enrichment.CacheSize)
+	enricherService := client.NewEnricher(providers, fallback, cfg.Gender, cfg.Resolution, log)
+	tenantRepo := repository.NewTenantRepo(dbpool, log)
+	tenants := tenant.NewResolver(tenantRepo)
+	personService := service.NewPersonService(*repo, tenantRepo, enricherService, cfg.Duplicates, cfg.Translit, log)
 	personHandler := handler.NewPersonHandler(personService, log)
 
-	mux := http.NewServeMux()
+	webhookRepo := repository.NewWebhookRepo(dbpool, log)
+	webhookService := service.NewWebhookService(webhookRepo, webhook.Targets{AllowPrivate: cfg.Webhook.AllowPrivateTargets}, log)
+	webhookHandler := handler.NewWebhookHandler(webhookService, log)
 
-	// Swagger
-	mux.Handle("/swagger/", httpSwagger.WrapHandler)
-
-	mux.HandleFunc("/persons", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodPost:
-			personHandler.Create(w, r)
-		case http.MethodGet:
-			personHandler.List(w, r)
-		default:
-			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	sinks := outbox.MultiSink{webhook.NewDispatcher(webhookRepo, log)}
+	if cfg.Outbox.Sink != "" {
+		sink, err := outbox.NewSink(cfg.Outbox, log)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to create outbox sink")
 		}
-	})
+		sinks = append(sinks, sink)
+	}
+	defer sinks.Close()
+
+	outboxRepo := repository.NewOutboxRepo(dbpool, log)
+	relay := outbox.NewRelay(outboxRepo, sinks, cfg.Outbox, log)
+	// Background work spans the tenants, row-level security must let it.
+	// It is awaited on shutdown, so batches are not cut off halfway.
+	workerCtx := entity.WithAllTenants(appCtx)
+	var workers sync.WaitGroup
+	background := func(run func(context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
+	background(relay.Run)
+	background(webhook.NewWorker(webhookRepo, cfg.Webhook, log).Run)
+
+	broker := events.NewBroker(dbpool, outboxRepo, log)
+	background(broker.Run)
+	eventsHandler := handler.NewEventsHandler(broker, log)
+
+	schema, err := gql.NewSchema(personService, log)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to build GraphQL schema")
+	}
+	graphqlHandler := gql.NewHandler(schema, gql.Limits{
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+	}, log)
 
-	mux.HandleFunc("/persons/", func(w http.ResponseWriter, r *http.Request) {
+	apiKeyRepo := repository.NewAPIKeyRepo(dbpool, log)
+	apiKeyHandler := handler.NewAPIKeyHandler(service.NewAPIKeyService(apiKeyRepo, log), log)
+	tenantHandler := handler.NewTenantHandler(service.NewTenantService(tenantRepo, tenants, log), log)
 
-		path := strings.TrimPrefix(r.URL.Path, "/persons/")
-		id, err := strconv.ParseInt(path, 10, 64)
+	middleware := []handler.Middleware{handler.Logging(log), handler.Recovery(log)}
+	// Without authentication every caller is an admin, services deny
+	// requests without a principal
+	var authenticator handler.Authenticator = auth.Anonymous{}
+	if cfg.Auth.Enabled {
+		verifier, err := auth.NewJWTVerifier(cfg.Auth, log)
 		if err != nil {
-			http.Error(w, "Invalid ID in URL", http.StatusBadRequest)
-			return
+			log.WithError(err).Fatal("Failed to set up JWT verification")
 		}
+		authenticator = auth.NewAuthenticator(apiKeyRepo, verifier, cfg.Auth.BootstrapKey, log)
+	} else {
+		log.Warn("Authentication is disabled, the API is open to everyone")
+	}
+	if cfg.HTTPLimits.Enabled {
+		var httpLimits ratelimit.Store = ratelimit.NewMemory()
+		if cfg.HTTPLimits.Store == "postgres" {
+			httpLimits = rateLimitRepo
+			background(func(ctx context.Context) { pruneRateLimits(ctx, rateLimitRepo, log) })
+		}
+		limits := handler.RateLimits{
+			Read:           ratelimit.Bucket{Rate: cfg.HTTPLimits.ReadRate, Burst: cfg.HTTPLimits.ReadBurst},
+			Write:          ratelimit.Bucket{Rate: cfg.HTTPLimits.WriteRate, Burst: cfg.HTTPLimits.WriteBurst},
+			IP:             ratelimit.Bucket{Rate: cfg.HTTPLimits.IPRate, Burst: cfg.HTTPLimits.IPBurst},
+			TrustedProxies: cfg.HTTPLimits.TrustedProxies,
+			IsMutation:     gql.IsMutation,
+		}
+		// Addresses are limited before their credentials are checked, so
+		// guessing keys is too, clients by their principal after
+		middleware = append(middleware,
+			handler.RateLimitIP(httpLimits, limits, log),
+			handler.Authenticate(authenticator, log),
+			handler.RateLimit(httpLimits, limits, log),
+		)
+	} else {
+		middleware = append(middleware, handler.Authenticate(authenticator, log))
+	}
+	middleware = append(middleware, handler.ResolveTenant(tenants, log))
 
-		switch r.Method {
-		case http.MethodGet:
-			person, err := personHandler.GetByID(r.Context(), id)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusNotFound)
-				return
-			}
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(person)
-		case http.MethodPut:
-			r = r.WithContext(context.WithValue(r.Context(), personKey, id))
-			personHandler.Update(w, r)
-		case http.MethodDelete:
-			r = r.WithContext(context.WithValue(r.Context(), personKey, id))
-			personHandler.Delete(w, r)
-		default:
-			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	router := handler.NewRouter(handler.Handlers{
+		Person:  personHandler,
+		Webhook: webhookHandler,
+		Events:  eventsHandler,
+		APIKeys: apiKeyHandler,
+		Tenants: tenantHandler,
+		GraphQL: graphqlHandler,
+		Swagger: httpSwagger.WrapHandler,
+	}, middleware...)
+
+	lis, err := net.Listen("tcp", cfg.GRPC.Addr)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to listen for gRPC")
+	}
+	grpcServer := grpcserver.NewServer(grpcserver.NewPersonServer(personService, log), authenticator, tenants, log)
+	go func() {
+		log.Infof("Starting gRPC server on %s", cfg.GRPC.Addr)
+		if err := grpcServer.Serve(lis); err != nil {
+			log.WithError(err).Error("gRPC server failed")
 		}
-	})
+	}()
 
 	port := 8080
-	log.Infof("Starting server on :%d", port)
-	err = http.ListenAndServe(fmt.Sprintf(":%d", port), mux)
-	if err != nil {
-		log.WithError(err).Fatal("Server failed")
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: router,
+	}
+	go func() {
+		log.Infof("Starting server on :%d", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Fatal("Server failed")
+		}
+	}()
+
+	<-appCtx.Done()
+	// A second signal kills the process right away
+	stop()
+	log.Info("Shutting down")
+	shutdown(server, grpcServer, &workers, shutdownTimeout, log)
+}
+
+// shutdownTimeout bounds how long running requests and background batches
+// may take once a termination signal arrives
+const shutdownTimeout = 15 * time.Second
+
+// shutdown stops accepting requests and waits up to timeout for the running
+// ones, then cuts the remaining connections. Within the same timeout it waits
+// for the background workers to finish their batches.
+func shutdown(server *http.Server, grpcServer *grpc.Server, workers *sync.WaitGroup, timeout time.Duration, log *logrus.Entry) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.WithError(err).Warn("HTTP server did not shut down in time")
+		server.Close()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Warn("gRPC server did not shut down in time")
+		grpcServer.Stop()
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		log.Warn("Background workers did not stop in time")
+	}
+}
+
+// enrichmentProviders builds the providers of the configured enrichment mode
+// and the fallback providers asked for what they could not estimate
+func enrichmentProviders(cfg *config.Config, usage client.UsageStore, log *logrus.Entry) (providers, fallback []client.Provider, err error) {
+	var dataset *client.Dataset
+	if cfg.Enrichment.Mode != "online" {
+		if len(cfg.Enrichment.Datasets) > 0 {
+			dataset, err = client.LoadDataset(cfg.Enrichment.Datasets...)
+		} else {
+			dataset, err = client.ReadDataset(bytes.NewReader(data.Names))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		log.WithField("names", dataset.Len()).Info("Loaded enrichment dataset")
+	}
+
+	switch cfg.Enrichment.Mode {
+	case "offline":
+		providers = []client.Provider{dataset}
+	case "fallback":
+		providers = client.HTTPProviders(cfg.ExternalAPI, usage, log)
+		fallback = []client.Provider{dataset}
+	default:
+		providers = client.HTTPProviders(cfg.ExternalAPI, usage, log)
+	}
+
+	if cfg.Nationality.SurnameDictionary != "" {
+		dictionary, err := client.LoadSurnameDictionary(cfg.Nationality.SurnameDictionary)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.WithField("surnames", dictionary.Len()).Info("Loaded surname dictionary")
+		providers = append(providers, dictionary)
+	}
+	return providers, fallback, nil
+}
+
+// pruneRateLimits drops the shared buckets of clients gone for a day, every
+// hour until ctx is done
+func pruneRateLimits(ctx context.Context, repo *repository.RateLimitRepo, log *logrus.Entry) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if pruned, err := repo.Prune(ctx, 24*time.Hour); err == nil && pruned > 0 {
+				log.WithField("buckets", pruned).Debug("Pruned idle rate limit buckets")
+			}
+		}
 	}
 }
@@ -0,0 +1,10 @@
+// Package data bundles the datasets shipped with the service
+package data
+
+import _ "embed"
+
+// Names holds first name statistics for the offline enrichment provider, in
+// the CSV format read by client.ReadDataset
+//
+//go:embed names.csv
+var Names []byte
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"people-enricher/internal/entity"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+const apiKeyColumns = "id, name, prefix, role, tenant_id, created_at, last_used_at, revoked_at"
+
+// APIKeyRepo stores the API keys clients authenticate with
+type APIKeyRepo struct {
+	pool   *pgxpool.Pool
+	logger *logrus.Entry
+}
+
+func NewAPIKeyRepo(pool *pgxpool.Pool, logger *logrus.Entry) *APIKeyRepo {
+	return &APIKeyRepo{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func scanAPIKey(row pgx.Row) (*entity.APIKey, error) {
+	var key entity.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.Role,
+		&key.TenantID,
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Create stores a key by its hash
+func (r *APIKeyRepo) Create(ctx context.Context, key *entity.APIKey, hash string) (*entity.APIKey, error) {
+	logger := r.logger.WithField("operation", "CreateAPIKey")
+
+	created, err := scanAPIKey(r.pool.QueryRow(ctx, `
+		INSERT INTO api_keys(name, prefix, role, tenant_id, key_hash)
+		VALUES($1, $2, $3, $4, $5)
+		RETURNING `+apiKeyColumns,
+		key.Name, key.Prefix, key.Role, key.TenantID, hash,
+	))
+	if err != nil {
+		logger.WithError(err).Error("Failed creating API key")
+		return nil, fmt.Errorf("creating API key: %w", err)
+	}
+
+	logger.WithField("api_key_id", created.ID).Info("Successfully created API key")
+	return created, nil
+}
+
+// List returns the keys of the tenant of ctx
+func (r *APIKeyRepo) List(ctx context.Context) ([]*entity.APIKey, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE tenant_id = $1 ORDER BY id",
+		entity.TenantID(ctx),
+	)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed listing API keys")
+		return nil, fmt.Errorf("listing API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*entity.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke disables a key of the tenant of ctx, revoking it again is a no-op
+func (r *APIKeyRepo) Revoke(ctx context.Context, id int64) error {
+	logger := r.logger.WithField("operation", "RevokeAPIKey").WithField("api_key_id", id)
+
+	cmdTag, err := r.pool.Exec(ctx,
+		"UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1 AND tenant_id = $2",
+		id, entity.TenantID(ctx),
+	)
+	if err != nil {
+		logger.WithError(err).Error("Failed revoking API key")
+		return fmt.Errorf("revoking API key: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		logger.Warn("API key not found")
+		return &entity.NotFoundError{Resource: "API key", ID: id}
+	}
+
+	logger.Info("Successfully revoked API key")
+	return nil
+}
+
+// Authenticate returns the active key with the given hash and records its
+// use, entity.ErrUnauthorized when there is none
+func (r *APIKeyRepo) Authenticate(ctx context.Context, hash string) (*entity.APIKey, error) {
+	// The key decides the tenant, it is looked up among all of them
+	key, err := scanAPIKey(r.pool.QueryRow(entity.WithAllTenants(ctx), `
+		UPDATE api_keys SET last_used_at = now()
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns,
+		hash,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrUnauthorized
+		}
+		r.logger.WithError(err).Error("Failed authenticating API key")
+		return nil, fmt.Errorf("authenticating API key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"people-enricher/internal/entity"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type APIKeyUsageRepo struct {
+	pool   *pgxpool.Pool
+	logger *logrus.Entry
+}
+
+func NewAPIKeyUsageRepo(pool *pgxpool.Pool, logger *logrus.Entry) *APIKeyUsageRepo {
+	return &APIKeyUsageRepo{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// AddRequests counts requests made with a key on day and returns the total
+func (r *APIKeyUsageRepo) AddRequests(ctx context.Context, provider, keyID string, day time.Time, requests, dailyLimit int) (int, error) {
+	var total int
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO api_key_usage(provider, key_id, day, requests, daily_limit)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (provider, key_id, day) DO UPDATE
+		SET requests = api_key_usage.requests + EXCLUDED.requests,
+			daily_limit = EXCLUDED.daily_limit,
+			updated_at = now()
+		RETURNING requests
+	`, provider, keyID, day, requests, dailyLimit).Scan(&total)
+	if err != nil {
+		r.logger.WithError(err).WithField("provider", provider).Error("Error counting API key usage")
+		return 0, fmt.Errorf("counting API key usage: %w", err)
+	}
+	return total, nil
+}
+
+// MarkExhausted records that the API refused a key for the rest of day
+func (r *APIKeyUsageRepo) MarkExhausted(ctx context.Context, provider, keyID string, day time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO api_key_usage(provider, key_id, day, exhausted_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (provider, key_id, day) DO UPDATE
+		SET exhausted_at = coalesce(api_key_usage.exhausted_at, now()),
+			updated_at = now()
+	`, provider, keyID, day)
+	if err != nil {
+		r.logger.WithError(err).WithField("provider", provider).Error("Error marking API key exhausted")
+		return fmt.Errorf("marking API key exhausted: %w", err)
+	}
+	return nil
+}
+
+// DailyUsage returns the usage of all keys of a provider on day
+func (r *APIKeyUsageRepo) DailyUsage(ctx context.Context, provider string, day time.Time) ([]entity.APIKeyUsage, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT provider, key_id, day, requests, daily_limit, exhausted_at
+		FROM api_key_usage
+		WHERE provider = $1 AND day = $2
+	`, provider, day)
+	if err != nil {
+		r.logger.WithError(err).WithField("provider", provider).Error("Error getting API key usage")
+		return nil, fmt.Errorf("getting API key usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []entity.APIKeyUsage{}
+	for rows.Next() {
+		var u entity.APIKeyUsage
+		if err := rows.Scan(&u.Provider, &u.KeyID, &u.Day, &u.Requests, &u.DailyLimit, &u.ExhaustedAt); err != nil {
+			return nil, fmt.Errorf("scanning API key usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading API key usage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"fmt"
+	"people-enricher/internal/entity"
+	"strings"
+)
+
+// nullableColumns maps filterable null-check fields to their columns.
+// Only columns listed here may ever be interpolated into a NULL check.
+var nullableColumns = map[string]string{
+	"patronymic":              "patronymic",
+	"age":                     "age",
+	"gender":                  "gender",
+	"nationality":             "nationality",
+	"nationality_probability": "nationality_probability",
+}
+
+// sortColumns maps sortable fields to their columns
+var sortColumns = map[string]string{
+	"id":                      "id",
+	"name":                    "name",
+	"surname":                 "surname",
+	"age":                     "age",
+	"nationality_probability": "nationality_probability",
+	"created_at":              "created_at",
+	"updated_at":              "updated_at",
+}
+
+// orderBy builds the ORDER BY clause for the filter, newest first by default.
+// id is always the last key so pages stay stable.
+func orderBy(filter *entity.PersonFilter) (string, error) {
+	if filter.SortBy == "" {
+		return "ORDER BY id DESC", nil
+	}
+	column, ok := sortColumns[filter.SortBy]
+	if !ok {
+		return "", fmt.Errorf("field %q can not be used for sorting", filter.SortBy)
+	}
+
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+	if column == "id" {
+		return "ORDER BY id " + direction, nil
+	}
+	return fmt.Sprintf("ORDER BY %s %s NULLS LAST, id %s", column, direction, direction), nil
+}
+
+// conditionBuilder collects WHERE conditions together with their positional
+// arguments. Column names are always supplied by the repository itself,
+// user values only ever travel as $n parameters.
+type conditionBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (b *conditionBuilder) placeholder(value interface{}) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *conditionBuilder) ilike(column, value string) {
+	b.conditions = append(b.conditions, fmt.Sprintf("%s ILIKE %s", column, b.placeholder("%"+value+"%")))
+}
+
+// ilikeEither matches value against either of two columns, such as a name
+// and its transliteration
+func (b *conditionBuilder) ilikeEither(column, other, value string) {
+	pattern := b.placeholder("%" + value + "%")
+	b.conditions = append(b.conditions, fmt.Sprintf("(%s ILIKE %s OR %s ILIKE %s)", column, pattern, other, pattern))
+}
+
+func (b *conditionBuilder) compare(column, operator string, value interface{}) {
+	b.conditions = append(b.conditions, fmt.Sprintf("%s %s %s", column, operator, b.placeholder(value)))
+}
+
+func (b *conditionBuilder) in(column string, values []string) {
+	if len(values) == 1 {
+		b.compare(column, "=", values[0])
+		return
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf("%s = ANY(%s)", column, b.placeholder(values)))
+}
+
+func (b *conditionBuilder) null(field string, isNull bool) error {
+	column, ok := nullableColumns[field]
+	if !ok {
+		return fmt.Errorf("field %q can not be checked for null", field)
+	}
+	if isNull {
+		b.conditions = append(b.conditions, column+" IS NULL")
+	} else {
+		b.conditions = append(b.conditions, column+" IS NOT NULL")
+	}
+	return nil
+}
+
+func (b *conditionBuilder) where() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// nextPlaceholder returns the index of the next positional argument
+func (b *conditionBuilder) nextPlaceholder() int {
+	return len(b.args) + 1
+}
+
+// buildPersonConditions matches the people of the tenant passing the filter
+func buildPersonConditions(tenantID string, filter *entity.PersonFilter) (*conditionBuilder, error) {
+	b := &conditionBuilder{}
+	b.compare("tenant_id", "=", tenantID)
+
+	if filter.Name != nil {
+		b.ilikeEither("name", "name_latin", *filter.Name)
+	}
+	if filter.Surname != nil {
+		b.ilikeEither("surname", "surname_latin", *filter.Surname)
+	}
+	if filter.Patronymic != nil {
+		b.ilikeEither("patronymic", "patronymic_latin", *filter.Patronymic)
+	}
+	if len(filter.Gender) > 0 {
+		b.in("gender", filter.Gender)
+	}
+	if filter.AgeFrom != nil {
+		b.compare("age", ">=", *filter.AgeFrom)
+	}
+	if filter.AgeTo != nil {
+		b.compare("age", "<=", *filter.AgeTo)
+	}
+	if len(filter.Nationality) > 0 {
+		b.in("nationality", filter.Nationality)
+	}
+	if filter.NationalityProbabilityFrom != nil {
+		b.compare("nationality_probability", ">=", *filter.NationalityProbabilityFrom)
+	}
+	if filter.NationalityProbabilityTo != nil {
+		b.compare("nationality_probability", "<=", *filter.NationalityProbabilityTo)
+	}
+	if filter.CreatedAfter != nil {
+		b.compare("created_at", ">=", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		b.compare("created_at", "<", *filter.CreatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		b.compare("updated_at", ">=", *filter.UpdatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		b.compare("updated_at", "<", *filter.UpdatedBefore)
+	}
+	for _, field := range filter.NullFields {
+		if err := b.null(field, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range filter.NotNullFields {
+		if err := b.null(field, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"people-enricher/internal/entity"
+)
+
+func TestBuildPersonConditions(t *testing.T) {
+	name := "iv"
+	age18, age30 := 18, 30
+	probability := 0.5
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		filter    entity.PersonFilter
+		wantWhere string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "tenant only",
+			wantWhere: "WHERE tenant_id = $1",
+			wantArgs:  []interface{}{"acme"},
+		},
+		{
+			name:      "name matches the transliteration too",
+			filter:    entity.PersonFilter{Name: &name},
+			wantWhere: "WHERE tenant_id = $1 AND (name ILIKE $2 OR name_latin ILIKE $2)",
+			wantArgs:  []interface{}{"acme", "%iv%"},
+		},
+		{
+			name:      "single value",
+			filter:    entity.PersonFilter{Gender: []string{"male"}},
+			wantWhere: "WHERE tenant_id = $1 AND gender = $2",
+			wantArgs:  []interface{}{"acme", "male"},
+		},
+		{
+			name:      "multiple values",
+			filter:    entity.PersonFilter{Nationality: []string{"RU", "UA"}},
+			wantWhere: "WHERE tenant_id = $1 AND nationality = ANY($2)",
+			wantArgs:  []interface{}{"acme", []string{"RU", "UA"}},
+		},
+		{
+			name:      "ranges",
+			filter:    entity.PersonFilter{AgeFrom: &age18, AgeTo: &age30, NationalityProbabilityFrom: &probability},
+			wantWhere: "WHERE tenant_id = $1 AND age >= $2 AND age <= $3 AND nationality_probability >= $4",
+			wantArgs:  []interface{}{"acme", 18, 30, 0.5},
+		},
+		{
+			name:      "dates",
+			filter:    entity.PersonFilter{CreatedAfter: &day, UpdatedBefore: &day},
+			wantWhere: "WHERE tenant_id = $1 AND created_at >= $2 AND updated_at < $3",
+			wantArgs:  []interface{}{"acme", day, day},
+		},
+		{
+			name:      "null checks",
+			filter:    entity.PersonFilter{NullFields: []string{"age"}, NotNullFields: []string{"gender"}},
+			wantWhere: "WHERE tenant_id = $1 AND age IS NULL AND gender IS NOT NULL",
+			wantArgs:  []interface{}{"acme"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := buildPersonConditions("acme", &tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.where(); got != tt.wantWhere {
+				t.Errorf("where = %q, want %q", got, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(b.args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", b.args, tt.wantArgs)
+			}
+			if next := b.nextPlaceholder(); next != len(tt.wantArgs)+1 {
+				t.Errorf("next placeholder = %d, want %d", next, len(tt.wantArgs)+1)
+			}
+		})
+	}
+}
+
+func TestBuildPersonConditionsRejectsUnknownNullField(t *testing.T) {
+	filter := &entity.PersonFilter{NullFields: []string{"name; DROP TABLE people"}}
+	if _, err := buildPersonConditions("acme", filter); err == nil {
+		t.Error("unknown null field was accepted")
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		sortBy string
+		desc   bool
+		want   string
+	}{
+		{"", false, "ORDER BY id DESC"},
+		{"id", false, "ORDER BY id ASC"},
+		{"id", true, "ORDER BY id DESC"},
+		{"age", false, "ORDER BY age ASC NULLS LAST, id ASC"},
+		{"created_at", true, "ORDER BY created_at DESC NULLS LAST, id DESC"},
+	}
+	for _, tt := range tests {
+		got, err := orderBy(&entity.PersonFilter{SortBy: tt.sortBy, SortDesc: tt.desc})
+		if err != nil {
+			t.Fatalf("orderBy(%q): %v", tt.sortBy, err)
+		}
+		if got != tt.want {
+			t.Errorf("orderBy(%q, %v) = %q, want %q", tt.sortBy, tt.desc, got, tt.want)
+		}
+	}
+
+	if _, err := orderBy(&entity.PersonFilter{SortBy: "secret"}); err == nil {
+		t.Error("unknown sort field was accepted")
+	}
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"people-enricher/internal/entity"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// outboxRelayLock is the advisory lock key held by the replica relaying events
+const outboxRelayLock = 7305001
+
+// outboxSequenceLock serializes the assignment of event sequence numbers
+const outboxSequenceLock = 7305002
+
+const eventColumns = "id, seq, tenant_id, aggregate_id, event_type, payload, created_at"
+
+// AddEvent writes a person event to the outbox. Call it inside WithTx so the
+// event is stored atomically with the change it describes.
+func (r *PersonRepo) AddEvent(ctx context.Context, eventType string, person *entity.Person) error {
+	logger := r.logger.WithFields(logrus.Fields{
+		"operation":  "AddEvent",
+		"event_type": eventType,
+		"person_id":  person.ID,
+	})
+
+	payload, err := json.Marshal(person)
+	if err != nil {
+		return fmt.Errorf("encoding %s event: %w", eventType, err)
+	}
+
+	_, err = r.db.Exec(ctx,
+		"INSERT INTO outbox_events(aggregate_id, event_type, payload, tenant_id) VALUES($1, $2, $3, $4)",
+		person.ID, eventType, payload, entity.TenantID(ctx),
+	)
+	if err != nil {
+		logger.WithError(err).Error("Error writing outbox event")
+		return fmt.Errorf("writing %s event: %w", eventType, err)
+	}
+
+	logger.Debug("Event written to outbox")
+	return nil
+}
+
+type OutboxRepo struct {
+	pool   *pgxpool.Pool
+	logger *logrus.Entry
+}
+
+func NewOutboxRepo(pool *pgxpool.Pool, logger *logrus.Entry) *OutboxRepo {
+	return &OutboxRepo{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// ProcessBatch loads up to limit unpublished events in insertion order and
+// hands them to publish, which reports a delivery error per event ID (nil on
+// success). Delivered events are marked as published, failed ones get their
+// attempt counter bumped and stay in the outbox until they failed maxAttempts
+// times, then they are marked failed and skipped from then on.
+//
+// The batch runs under a transaction-scoped advisory lock, so only one
+// replica relays at a time. ok is false when another replica holds the lock.
+func (r *OutboxRepo) ProcessBatch(ctx context.Context, limit, maxAttempts int, publish func(events []entity.Event) map[int64]error) (processed int, ok bool, err error) {
+	logger := r.logger.WithField("operation", "ProcessBatch")
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Error starting transaction")
+		return 0, false, fmt.Errorf("starting outbox transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", outboxRelayLock).Scan(&ok); err != nil {
+		logger.WithError(err).Error("Error taking relay lock")
+		return 0, false, fmt.Errorf("taking outbox relay lock: %w", err)
+	}
+	if !ok {
+		return 0, false, nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT `+eventColumns+`
+		FROM outbox_events
+		WHERE published_at IS NULL AND failed_at IS NULL
+		ORDER BY id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		logger.WithError(err).Error("Error loading outbox events")
+		return 0, true, fmt.Errorf("loading outbox events: %w", err)
+	}
+
+	events := []entity.Event{}
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			rows.Close()
+			logger.WithError(err).Error("Error scanning outbox event")
+			return 0, true, err
+		}
+		events = append(events, *event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("Error reading outbox events")
+		return 0, true, fmt.Errorf("reading outbox events: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, true, nil
+	}
+
+	results := publish(events)
+
+	for _, event := range events {
+		deliveryErr, attempted := results[event.ID]
+		switch {
+		case !attempted:
+			continue
+		case deliveryErr == nil:
+			_, err = tx.Exec(ctx, "UPDATE outbox_events SET published_at = now(), attempts = attempts + 1 WHERE id = $1", event.ID)
+			processed++
+		default:
+			var failed bool
+			err = tx.QueryRow(ctx, `
+				UPDATE outbox_events
+				SET attempts = attempts + 1, last_error = $2,
+					failed_at = CASE WHEN attempts + 1 >= $3 THEN now() END
+				WHERE id = $1
+				RETURNING failed_at IS NOT NULL
+			`, event.ID, deliveryErr.Error(), maxAttempts).Scan(&failed)
+			if err == nil && failed {
+				logger.WithFields(logrus.Fields{
+					"event_id":  event.ID,
+					"person_id": event.PersonID,
+					"attempts":  maxAttempts,
+				}).Error("Outbox event failed too often, giving up")
+			}
+		}
+		if err != nil {
+			logger.WithError(err).WithField("event_id", event.ID).Error("Error updating outbox event")
+			return 0, true, fmt.Errorf("updating outbox event %d: %w", event.ID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.WithError(err).Error("Error committing outbox batch")
+		return 0, true, fmt.Errorf("committing outbox batch: %w", err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"loaded":    len(events),
+		"published": processed,
+	}).Debug("Outbox batch processed")
+	return processed, true, nil
+}
+
+func scanEvent(row pgx.Row) (*entity.Event, error) {
+	var event entity.Event
+	var payload []byte
+	if err := row.Scan(&event.ID, &event.Seq, &event.TenantID, &event.PersonID, &event.Type, &payload, &event.OccurredAt); err != nil {
+		return nil, fmt.Errorf("scanning outbox event: %w", err)
+	}
+	if err := json.Unmarshal(payload, &event.Person); err != nil {
+		return nil, fmt.Errorf("decoding outbox event %d: %w", event.ID, err)
+	}
+	return &event, nil
+}
+
+// SequenceEvents numbers the committed events that have no sequence number
+// yet, in ID order. It holds a lock while doing so, so numbers assigned by
+// later calls are greater and only become visible after the earlier ones:
+// unlike IDs, sequence numbers are never skipped by readers resuming after
+// the last one they saw.
+func (r *OutboxRepo) SequenceEvents(ctx context.Context) (int64, error) {
+	logger := r.logger.WithField("operation", "SequenceEvents")
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Error starting transaction")
+		return 0, fmt.Errorf("starting sequence transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", outboxSequenceLock); err != nil {
+		logger.WithError(err).Error("Error taking sequence lock")
+		return 0, fmt.Errorf("taking outbox sequence lock: %w", err)
+	}
+
+	cmdTag, err := tx.Exec(ctx, `
+		UPDATE outbox_events o SET seq = s.seq
+		FROM (
+			SELECT id, nextval('outbox_events_seq') AS seq
+			FROM (SELECT id FROM outbox_events WHERE seq IS NULL ORDER BY id) pending
+		) s
+		WHERE o.id = s.id
+	`)
+	if err != nil {
+		logger.WithError(err).Error("Error sequencing events")
+		return 0, fmt.Errorf("sequencing outbox events: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.WithError(err).Error("Error committing event sequence")
+		return 0, fmt.Errorf("committing outbox sequence: %w", err)
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// LastSeq returns the highest assigned sequence number, 0 when there is none
+func (r *OutboxRepo) LastSeq(ctx context.Context) (int64, error) {
+	var seq int64
+	if err := r.pool.QueryRow(ctx, "SELECT coalesce(max(seq), 0) FROM outbox_events").Scan(&seq); err != nil {
+		r.logger.WithError(err).Error("Error loading last event sequence")
+		return 0, fmt.Errorf("loading last event sequence: %w", err)
+	}
+	return seq, nil
+}
+
+// EventsAfter returns up to limit events of the tenant of ctx with a
+// sequence number greater than afterSeq, in sequence order
+func (r *OutboxRepo) EventsAfter(ctx context.Context, afterSeq int64, limit int) ([]entity.Event, error) {
+	return r.eventsAfter(ctx, `
+		SELECT `+eventColumns+`
+		FROM outbox_events
+		WHERE seq > $1 AND tenant_id = $3
+		ORDER BY seq
+		LIMIT $2
+	`, afterSeq, limit, entity.TenantID(ctx))
+}
+
+// AllEventsAfter is EventsAfter across all tenants
+func (r *OutboxRepo) AllEventsAfter(ctx context.Context, afterSeq int64, limit int) ([]entity.Event, error) {
+	return r.eventsAfter(ctx, `
+		SELECT `+eventColumns+`
+		FROM outbox_events
+		WHERE seq > $1
+		ORDER BY seq
+		LIMIT $2
+	`, afterSeq, limit)
+}
+
+func (r *OutboxRepo) eventsAfter(ctx context.Context, query string, afterSeq int64, args ...any) ([]entity.Event, error) {
+	rows, err := r.pool.Query(ctx, query, append([]any{afterSeq}, args...)...)
+	if err != nil {
+		r.logger.WithError(err).Error("Error loading events")
+		return nil, fmt.Errorf("loading events after %d: %w", afterSeq, err)
+	}
+	defer rows.Close()
+
+	events := []entity.Event{}
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading events: %w", err)
+	}
+	return events, nil
+}
+
+// History returns the latest events of a person, newest first
+func (r *PersonRepo) History(ctx context.Context, personID int64, limit int) ([]entity.Event, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+eventColumns+`
+		FROM outbox_events
+		WHERE aggregate_id = $1 AND tenant_id = $3
+		ORDER BY id DESC
+		LIMIT $2
+	`, personID, limit, entity.TenantID(ctx))
+	if err != nil {
+		r.logger.WithError(err).WithField("person_id", personID).Error("Error loading person history")
+		return nil, fmt.Errorf("loading history of person %d: %w", personID, err)
+	}
+	defer rows.Close()
+
+	events := []entity.Event{}
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading person history: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"people-enricher/internal/entity"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const personColumns = "id, tenant_id, name, surname, patronymic, name_latin, surname_latin, patronymic_latin, age, gender, nationality, nationality_probability, resolutions, created_at, updated_at"
+
+func scanPerson(row pgx.Row) (*entity.Person, error) {
+	var person entity.Person
+	err := row.Scan(
+		&person.ID,
+		&person.TenantID,
+		&person.Name,
+		&person.Surname,
+		&person.Patronymic,
+		&person.NameLatin,
+		&person.SurnameLatin,
+		&person.PatronymicLatin,
+		&person.Age,
+		&person.Gender,
+		&person.Nationality,
+		&person.NationalityProbability,
+		&person.Resolutions,
+		&person.CreatedAt,
+		&person.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &person, nil
+}
+
+// FindDuplicates returns people whose full name equals or is similar to the
+// given person's, ordered by similarity. Fuzzy matching relies on pg_trgm, its
+// % operator is set to the threshold for the index to find all candidates.
+func (r *PersonRepo) FindDuplicates(ctx context.Context, person *entity.Person, threshold float64) ([]entity.DuplicateCandidate, error) {
+	logger := r.logger.WithField("operation", "FindDuplicates")
+	logger.Debug("Searching duplicates of person")
+
+	patronymic := ""
+	if person.Patronymic != nil {
+		patronymic = *person.Patronymic
+	}
+	fullName := person.Name + " " + person.Surname + " " + patronymic
+
+	query := `
+		SELECT id, name, surname, patronymic, similarity, exact
+		FROM (
+			SELECT id, name, surname, patronymic,
+				similarity(lower(name || ' ' || surname || ' ' || coalesce(patronymic, '')), lower($1)) AS similarity,
+				lower(name) = lower($2) AND lower(surname) = lower($3)
+					AND lower(coalesce(patronymic, '')) = lower($4) AS exact
+			FROM people
+			WHERE tenant_id = $6 AND (
+				lower(name || ' ' || surname || ' ' || coalesce(patronymic, '')) % lower($1)
+				OR (lower(name) = lower($2) AND lower(surname) = lower($3))
+			)
+		) candidates
+		WHERE exact OR similarity >= $5
+		ORDER BY exact DESC, similarity DESC
+		LIMIT 10
+	`
+
+	candidates := []entity.DuplicateCandidate{}
+	err := r.WithTx(ctx, func(repo *PersonRepo) error {
+		// % filters with pg_trgm.similarity_threshold, 0.3 unless set, which
+		// would hide candidates of lower configured thresholds
+		_, err := repo.db.Exec(ctx, "SELECT set_config('pg_trgm.similarity_threshold', $1, true)", strconv.FormatFloat(threshold, 'f', -1, 64))
+		if err != nil {
+			logger.WithError(err).Error("Error setting similarity threshold")
+			return fmt.Errorf("setting similarity threshold: %w", err)
+		}
+
+		rows, err := repo.db.Query(ctx, query, fullName, person.Name, person.Surname, patronymic, threshold, entity.TenantID(ctx))
+		if err != nil {
+			logger.WithError(err).Error("Error searching duplicates")
+			return fmt.Errorf("searching duplicates: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var candidate entity.DuplicateCandidate
+			err := rows.Scan(
+				&candidate.ID,
+				&candidate.Name,
+				&candidate.Surname,
+				&candidate.Patronymic,
+				&candidate.Similarity,
+				&candidate.Exact,
+			)
+			if err != nil {
+				logger.WithError(err).Error("Error scanning duplicate")
+				return fmt.Errorf("scanning duplicate: %w", err)
+			}
+			candidates = append(candidates, candidate)
+		}
+		if err := rows.Err(); err != nil {
+			logger.WithError(err).Error("Error reading duplicates")
+			return fmt.Errorf("reading duplicates: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.WithField("count", len(candidates)).Debug("Duplicates found")
+	return candidates, nil
+}
+
+// Merge folds the source person into the target one: empty target fields are
+// taken from the source, the source snapshot is kept in person_merges and the
+// source row is removed. Earlier merges into the source are redirected too.
+// All of it happens in one transaction, joining the caller's one if any.
+func (r *PersonRepo) Merge(ctx context.Context, sourceID, targetID int64) (*entity.Person, error) {
+	logger := r.logger.WithField("operation", "Merge").WithField("source_id", sourceID).WithField("target_id", targetID)
+	logger.Debug("Merging persons")
+
+	var merged *entity.Person
+	err := r.WithTx(ctx, func(tx *PersonRepo) error {
+		lockQuery := "SELECT " + personColumns + " FROM people WHERE id = $1 AND tenant_id = $2 FOR UPDATE"
+		tenantID := entity.TenantID(ctx)
+
+		source, err := scanPerson(tx.db.QueryRow(ctx, lockQuery, sourceID, tenantID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				logger.Warn("Source person not found")
+				return &entity.NotFoundError{Resource: "person", ID: sourceID}
+			}
+			return fmt.Errorf("locking source person: %w", err)
+		}
+		target, err := scanPerson(tx.db.QueryRow(ctx, lockQuery, targetID, tenantID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				logger.Warn("Target person not found")
+				return &entity.NotFoundError{Resource: "person", ID: targetID}
+			}
+			return fmt.Errorf("locking target person: %w", err)
+		}
+
+		if target.Patronymic == nil {
+			target.Patronymic = source.Patronymic
+			target.PatronymicLatin = source.PatronymicLatin
+		}
+		if target.Age == nil && source.Age != nil {
+			target.Age = source.Age
+			takeResolution(target, source, "age")
+		}
+		if target.Gender == nil && source.Gender != nil {
+			target.Gender = source.Gender
+			takeResolution(target, source, "gender")
+		}
+		if target.Nationality == nil && source.Nationality != nil {
+			target.Nationality = source.Nationality
+			target.NationalityProbability = source.NationalityProbability
+			takeResolution(target, source, "nationality")
+		}
+
+		merged, err = scanPerson(tx.db.QueryRow(ctx, `
+			UPDATE people
+			SET patronymic = $1,
+				patronymic_latin = $2,
+				age = $3,
+				gender = $4,
+				nationality = $5,
+				nationality_probability = $6,
+				resolutions = $7,
+				updated_at = $8
+			WHERE id = $9
+			RETURNING `+personColumns,
+			target.Patronymic,
+			target.PatronymicLatin,
+			target.Age,
+			target.Gender,
+			target.Nationality,
+			target.NationalityProbability,
+			target.Resolutions,
+			time.Now(),
+			target.ID,
+		))
+		if err != nil {
+			logger.WithError(err).Error("Error updating target person")
+			return fmt.Errorf("updating merge target: %w", err)
+		}
+
+		snapshot, err := json.Marshal(source)
+		if err != nil {
+			return fmt.Errorf("encoding source snapshot: %w", err)
+		}
+
+		if _, err := tx.db.Exec(ctx, "UPDATE person_merges SET target_id = $1 WHERE target_id = $2", targetID, sourceID); err != nil {
+			logger.WithError(err).Error("Error redirecting earlier merges")
+			return fmt.Errorf("redirecting earlier merges: %w", err)
+		}
+		if _, err := tx.db.Exec(ctx,
+			"INSERT INTO person_merges(source_id, target_id, source_snapshot, tenant_id) VALUES($1, $2, $3, $4)",
+			sourceID, targetID, snapshot, tenantID,
+		); err != nil {
+			logger.WithError(err).Error("Error saving merge audit")
+			return fmt.Errorf("saving merge audit: %w", err)
+		}
+		if _, err := tx.db.Exec(ctx, "DELETE FROM people WHERE id = $1", sourceID); err != nil {
+			logger.WithError(err).Error("Error removing source person")
+			return fmt.Errorf("removing merged person: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Successfully merged persons")
+	return merged, nil
+}
+
+// MergedInto returns the ID the given person was merged into, or 0 when the
+// ID was never merged.
+func (r *PersonRepo) MergedInto(ctx context.Context, id int64) (int64, error) {
+	var targetID int64
+	err := r.db.QueryRow(ctx,
+		"SELECT target_id FROM person_merges WHERE source_id = $1 AND tenant_id = $2",
+		id, entity.TenantID(ctx),
+	).Scan(&targetID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		r.logger.WithError(err).WithField("person_id", id).Error("Error looking up merge redirect")
+		return 0, fmt.Errorf("looking up merge of person %d: %w", id, err)
+	}
+	return targetID, nil
+}
+
+// takeResolution copies how a field taken over from source was resolved
+func takeResolution(target, source *entity.Person, field string) {
+	resolution, ok := source.Resolutions[field]
+	if !ok {
+		return
+	}
+	if target.Resolutions == nil {
+		target.Resolutions = map[string]entity.FieldResolution{}
+	}
+	target.Resolutions[field] = resolution
+}
@@ -5,75 +5,112 @@ import (
 	"errors"
 	"fmt"
 	"people-enricher/internal/entity"
-	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 )
 
+// querier is the part of the pgx API shared by *pgxpool.Pool and pgx.Tx
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// PersonRepo stores people. Every query is scoped to the tenant of its
+// context, see entity.TenantID.
 type PersonRepo struct {
 	pool   *pgxpool.Pool
+	db     querier
+	inTx   bool
 	logger *logrus.Entry
 }
 
 func NewPersonRepo(pool *pgxpool.Pool, logger *logrus.Entry) *PersonRepo {
 	return &PersonRepo{
 		pool:   pool,
+		db:     pool,
 		logger: logger,
 	}
 }
 
+// WithTx runs fn with a repository bound to a single transaction. The
+// transaction is committed when fn returns nil and rolled back otherwise.
+// Calling WithTx on a repository that is already in a transaction reuses it.
+func (r *PersonRepo) WithTx(ctx context.Context, fn func(repo *PersonRepo) error) error {
+	if r.inTx {
+		return fn(r)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.WithError(err).Error("Error starting transaction")
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	txRepo := &PersonRepo{
+		pool:   r.pool,
+		db:     tx,
+		inTx:   true,
+		logger: r.logger.WithField("tx", true),
+	}
+	if err := fn(txRepo); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.WithError(err).Error("Error committing transaction")
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
 func (r *PersonRepo) Create(ctx context.Context, person *entity.Person) (*entity.Person, error) {
 	logger := r.logger.WithField("operation", "Create")
 	logger.Debug("Creating new record about person")
 
 	query := `
         INSERT INTO people(
-            name, surname, patronymic, age, gender, nationality, nationality_probability, created_at, updated_at
+            name, surname, patronymic, name_latin, surname_latin, patronymic_latin,
+            age, gender, nationality, nationality_probability, resolutions, created_at, updated_at, tenant_id
         )   VALUES(
-            $1, $2, $3, $4, $5, $6, $7, $8, $9   
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
         )
-            RETURNING id, name, surname, patronymic, age, gender, nationality, nationality_probability, created_at, updated_at
-    `
+            RETURNING ` + personColumns
 	now := time.Now()
 	person.CreatedAt = now
 	person.UpdatedAt = now
 
-	row := r.pool.QueryRow(
+	row := r.db.QueryRow(
 		ctx,
 		query,
 		person.Name,
 		person.Surname,
 		person.Patronymic,
+		person.NameLatin,
+		person.SurnameLatin,
+		person.PatronymicLatin,
 		person.Age,
 		person.Gender,
 		person.Nationality,
 		person.NationalityProbability,
+		person.Resolutions,
 		person.CreatedAt,
 		person.UpdatedAt,
+		entity.TenantID(ctx),
 	)
 
-	var ceatedPerson entity.Person
-	err := row.Scan(
-		&ceatedPerson.ID,
-		&ceatedPerson.Name,
-		&ceatedPerson.Surname,
-		&ceatedPerson.Patronymic,
-		&ceatedPerson.Age,
-		&ceatedPerson.Gender,
-		&ceatedPerson.Nationality,
-		&ceatedPerson.NationalityProbability,
-		&ceatedPerson.CreatedAt,
-		&ceatedPerson.UpdatedAt,
-	)
+	ceatedPerson, err := scanPerson(row)
 	if err != nil {
 		logger.WithError(err).Error("Failed Creating record about person")
 		return nil, fmt.Errorf("creating record about person: %w", err)
 	}
 	logger.WithField("person_id", ceatedPerson.ID).Info("Successfull creating person")
-	return &ceatedPerson, nil
+	return ceatedPerson, nil
 }
 
 func (r *PersonRepo) Update(ctx context.Context, person *entity.Person) (*entity.Person, error) {
@@ -85,62 +122,58 @@ func (r *PersonRepo) Update(ctx context.Context, person *entity.Person) (*entity
 		SET name = $1,
 			surname = $2,
 			patronymic = $3,
-			age = $4,
-			gender = $5,
-			nationality = $6,
-			nationality_probability = $7,
-			updated_at = $8
-		WHERE id = $9
-		RETURNING id, name, surname, patronymic, age, gender, nationality, nationality_probability, created_at, updated_at
-	`
+			name_latin = $4,
+			surname_latin = $5,
+			patronymic_latin = $6,
+			age = $7,
+			gender = $8,
+			nationality = $9,
+			nationality_probability = $10,
+			resolutions = $11,
+			updated_at = $12
+		WHERE id = $13 AND tenant_id = $14
+		RETURNING ` + personColumns
 	person.UpdatedAt = time.Now()
 
-	row := r.pool.QueryRow(
+	row := r.db.QueryRow(
 		ctx,
 		query,
 		person.Name,
 		person.Surname,
 		person.Patronymic,
+		person.NameLatin,
+		person.SurnameLatin,
+		person.PatronymicLatin,
 		person.Age,
 		person.Gender,
 		person.Nationality,
 		person.NationalityProbability,
+		person.Resolutions,
 		person.UpdatedAt,
 		person.ID,
+		entity.TenantID(ctx),
 	)
 
-	var updatedPerson entity.Person
-	err := row.Scan(
-		&updatedPerson.ID,
-		&updatedPerson.Name,
-		&updatedPerson.Surname,
-		&updatedPerson.Patronymic,
-		&updatedPerson.Age,
-		&updatedPerson.Gender,
-		&updatedPerson.Nationality,
-		&updatedPerson.NationalityProbability,
-		&updatedPerson.CreatedAt,
-		&updatedPerson.UpdatedAt,
-	)
+	updatedPerson, err := scanPerson(row)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			logger.WithError(err).Warn("Person not found")
-			return nil, errors.New("person not found")
+			return nil, &entity.NotFoundError{Resource: "person", ID: person.ID}
 		}
 		logger.WithError(err).Error("error update person")
 		return nil, fmt.Errorf("update record about person: %w", err)
 	}
 
 	logger.Info("Successfully updated person")
-	return &updatedPerson, nil
+	return updatedPerson, nil
 }
 func (r *PersonRepo) Delete(ctx context.Context, id int64) error {
 	logger := r.logger.WithField("operation", "Delete").WithField("person_id", id)
 	logger.Debug("Remove person ")
 
-	query := "DELETE FROM people WHERE id = $1"
+	query := "DELETE FROM people WHERE id = $1 AND tenant_id = $2"
 
-	cmdTag, err := r.pool.Exec(ctx, query, id)
+	cmdTag, err := r.db.Exec(ctx, query, id, entity.TenantID(ctx))
 	if err != nil {
 		logger.WithError(err).Error("Error delete person")
 		return fmt.Errorf("removing person: %w", err)
@@ -148,7 +181,7 @@ func (r *PersonRepo) Delete(ctx context.Context, id int64) error {
 
 	if cmdTag.RowsAffected() == 0 {
 		logger.Warn("Person not found")
-		return errors.New("person not found")
+		return &entity.NotFoundError{Resource: "person", ID: id}
 	}
 
 	logger.Info("Successfully remove person")
@@ -159,100 +192,44 @@ func (r *PersonRepo) GetByID(ctx context.Context, id int64) (*entity.Person, err
 	logger := r.logger.WithField("operation", "GetByID").WithField("person_id", id)
 	logger.Debug("Получение записи о человеке по ID")
 
-	query := `
-        SELECT id, name, surname, patronymic, age, gender, nationality, nationality_probability, created_at, updated_at
-        FROM people
-        WHERE id = $1
-    `
-
-	row := r.pool.QueryRow(ctx, query, id)
-
-	var person entity.Person
-	err := row.Scan(
-		&person.ID,
-		&person.Name,
-		&person.Surname,
-		&person.Patronymic,
-		&person.Age,
-		&person.Gender,
-		&person.Nationality,
-		&person.NationalityProbability,
-		&person.CreatedAt,
-		&person.UpdatedAt,
-	)
+	query := "SELECT " + personColumns + " FROM people WHERE id = $1 AND tenant_id = $2"
 
+	person, err := scanPerson(r.db.QueryRow(ctx, query, id, entity.TenantID(ctx)))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			logger.WithError(err).Warn("Person not found")
-			return nil, fmt.Errorf("person not found with ID %d", id)
+			return nil, &entity.NotFoundError{Resource: "person", ID: id}
 		}
 		logger.WithError(err).Error("Error executing query or scanning result")
 		return nil, fmt.Errorf("error getting person with ID %d: %w", id, err)
 	}
 
 	logger.Info("Successfully retrieved person")
-	return &person, nil
+	return person, nil
 }
 
 func (r *PersonRepo) List(ctx context.Context, filter *entity.PersonFilter) ([]*entity.Person, int, error) {
 	logger := r.logger.WithField("operation", "List")
 	logger.WithField("filter", filter).Debug("Getting person list")
 
-	whereConditions := []string{}
-	args := []interface{}{}
-	argCounter := 1
-
-	if filter.Name != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("name ILIKE $%d", argCounter))
-		args = append(args, "%"+*filter.Name+"%")
-		argCounter++
-	}
-
-	if filter.Surname != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("surname ILIKE $%d", argCounter))
-		args = append(args, "%"+*filter.Surname+"%")
-		argCounter++
-	}
-
-	if filter.Patronymic != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("patronymic ILIKE $%d", argCounter))
-		args = append(args, "%"+*filter.Patronymic+"%")
-		argCounter++
-	}
-
-	if filter.Gender != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("gender = $%d", argCounter))
-		args = append(args, *filter.Gender)
-		argCounter++
-	}
-
-	if filter.AgeFrom != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("age >= $%d", argCounter))
-		args = append(args, *filter.AgeFrom)
-		argCounter++
-	}
-
-	if filter.AgeTo != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("age <= $%d", argCounter))
-		args = append(args, *filter.AgeTo)
-		argCounter++
-	}
-
-	if filter.Nationality != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("nationality = $%d", argCounter))
-		args = append(args, *filter.Nationality)
-		argCounter++
+	conditions, err := buildPersonConditions(entity.TenantID(ctx), filter)
+	if err != nil {
+		logger.WithError(err).Warn("Invalid filter")
+		return nil, 0, fmt.Errorf("building filter conditions: %w", err)
 	}
+	whereClause := conditions.where()
+	args := conditions.args
 
-	whereClause := ""
-	if len(whereConditions) > 0 {
-		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	order, err := orderBy(filter)
+	if err != nil {
+		logger.WithError(err).Warn("Invalid sort")
+		return nil, 0, err
 	}
 
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM people %s", whereClause)
 
 	var total int
-	err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total)
+	err = r.db.QueryRow(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		logger.WithError(err).Error("Error getting all lists")
 		return nil, 0, fmt.Errorf("getting all lists: %w", err)
@@ -268,16 +245,16 @@ func (r *PersonRepo) List(ctx context.Context, filter *entity.PersonFilter) ([]*
 	offset := (filter.Page - 1) * filter.PageSize
 
 	query := fmt.Sprintf(`
-		SELECT id, name, surname, patronymic, age, gender, nationality, nationality_probability, created_at, updated_at
+		SELECT `+personColumns+`
 		FROM people
 		%s
-		ORDER BY id DESC
+		%s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, argCounter, argCounter+1)
+	`, whereClause, order, conditions.nextPlaceholder(), conditions.nextPlaceholder()+1)
 
 	args = append(args, filter.PageSize, offset)
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		logger.WithError(err).Error("Error getting lists")
 		return nil, 0, fmt.Errorf("getting lists: %w", err)
@@ -286,24 +263,12 @@ func (r *PersonRepo) List(ctx context.Context, filter *entity.PersonFilter) ([]*
 
 	people := []*entity.Person{}
 	for rows.Next() {
-		var person entity.Person
-		err := rows.Scan(
-			&person.ID,
-			&person.Name,
-			&person.Surname,
-			&person.Patronymic,
-			&person.Age,
-			&person.Gender,
-			&person.Nationality,
-			&person.NationalityProbability,
-			&person.CreatedAt,
-			&person.UpdatedAt,
-		)
+		person, err := scanPerson(rows)
 		if err != nil {
 			logger.WithError(err).Error("Error scanning rows")
 			return nil, 0, fmt.Errorf("scannig rows: %w", err)
 		}
-		people = append(people, &person)
+		people = append(people, person)
 	}
 
 	if err := rows.Err(); err != nil {
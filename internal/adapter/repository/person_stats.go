@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"people-enricher/internal/entity"
+	"strconv"
+)
+
+// Stats counts people matching the filter by gender, nationality and age
+// bucket. ageBuckets are ascending lower bounds of the buckets, people
+// younger than the first one fall into an open "<first" bucket.
+func (r *PersonRepo) Stats(ctx context.Context, filter *entity.PersonFilter, ageBuckets []int) (*entity.PersonStats, error) {
+	logger := r.logger.WithField("operation", "Stats")
+	logger.WithField("filter", filter).Debug("Collecting person statistics")
+
+	conditions, err := buildPersonConditions(entity.TenantID(ctx), filter)
+	if err != nil {
+		logger.WithError(err).Warn("Invalid filter")
+		return nil, fmt.Errorf("building filter conditions: %w", err)
+	}
+	whereClause := conditions.where()
+
+	stats := &entity.PersonStats{Coverage: map[string]float64{}}
+
+	var patronymic, age, gender, nationality, probability int
+	coverageQuery := fmt.Sprintf(`
+		SELECT COUNT(*), COUNT(patronymic), COUNT(age), COUNT(gender), COUNT(nationality), COUNT(nationality_probability)
+		FROM people
+		%s
+	`, whereClause)
+	err = r.db.QueryRow(ctx, coverageQuery, conditions.args...).Scan(
+		&stats.Total, &patronymic, &age, &gender, &nationality, &probability,
+	)
+	if err != nil {
+		logger.WithError(err).Error("Error counting coverage")
+		return nil, fmt.Errorf("counting coverage: %w", err)
+	}
+
+	for field, populated := range map[string]int{
+		"patronymic":              patronymic,
+		"age":                     age,
+		"gender":                  gender,
+		"nationality":             nationality,
+		"nationality_probability": probability,
+	} {
+		stats.Coverage[field] = percent(populated, stats.Total)
+	}
+
+	if stats.ByGender, err = r.countByColumn(ctx, "gender", whereClause, conditions.args); err != nil {
+		logger.WithError(err).Error("Error counting by gender")
+		return nil, err
+	}
+	if stats.ByNationality, err = r.countByColumn(ctx, "nationality", whereClause, conditions.args); err != nil {
+		logger.WithError(err).Error("Error counting by nationality")
+		return nil, err
+	}
+
+	bucketQuery := fmt.Sprintf(`
+		SELECT width_bucket(age, $%d::int[]) AS bucket, COUNT(*)
+		FROM people
+		%s
+		GROUP BY bucket
+	`, conditions.nextPlaceholder(), whereClause)
+	args := append(append([]interface{}{}, conditions.args...), ageBuckets)
+
+	rows, err := r.db.Query(ctx, bucketQuery, args...)
+	if err != nil {
+		logger.WithError(err).Error("Error counting by age bucket")
+		return nil, fmt.Errorf("counting by age bucket: %w", err)
+	}
+	defer rows.Close()
+
+	stats.ByAgeBucket = newAgeBuckets(ageBuckets)
+	unknown := len(stats.ByAgeBucket) - 1
+	for rows.Next() {
+		var bucket *int
+		var count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			logger.WithError(err).Error("Error scanning age bucket")
+			return nil, fmt.Errorf("scanning age bucket: %w", err)
+		}
+		if bucket == nil {
+			stats.ByAgeBucket[unknown].Count = count
+			continue
+		}
+		stats.ByAgeBucket[*bucket].Count = count
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("Error reading age buckets")
+		return nil, fmt.Errorf("reading age buckets: %w", err)
+	}
+
+	logger.WithField("total", stats.Total).Info("Successfully collected person statistics")
+	return stats, nil
+}
+
+func (r *PersonRepo) countByColumn(ctx context.Context, column, whereClause string, args []interface{}) ([]entity.ValueCount, error) {
+	query := fmt.Sprintf(`
+		SELECT %s, COUNT(*) AS count
+		FROM people
+		%s
+		GROUP BY %s
+		ORDER BY count DESC, %s
+	`, column, whereClause, column, column)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("counting by %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	counts := []entity.ValueCount{}
+	for rows.Next() {
+		var count entity.ValueCount
+		if err := rows.Scan(&count.Value, &count.Count); err != nil {
+			return nil, fmt.Errorf("scanning %s count: %w", column, err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s counts: %w", column, err)
+	}
+	return counts, nil
+}
+
+// newAgeBuckets lays out the buckets in width_bucket order: index 0 is below
+// the first boundary, index i is [bounds[i-1], bounds[i]) and the last one
+// holds people with unknown age.
+func newAgeBuckets(bounds []int) []entity.AgeBucketCount {
+	buckets := make([]entity.AgeBucketCount, 0, len(bounds)+2)
+	for i := 0; i <= len(bounds); i++ {
+		var bucket entity.AgeBucketCount
+		switch {
+		case i == 0:
+			bucket.To = &bounds[0]
+			bucket.Label = "<" + strconv.Itoa(bounds[0])
+		case i == len(bounds):
+			bucket.From = &bounds[i-1]
+			bucket.Label = strconv.Itoa(bounds[i-1]) + "+"
+		default:
+			bucket.From = &bounds[i-1]
+			bucket.To = &bounds[i]
+			bucket.Label = fmt.Sprintf("%d-%d", bounds[i-1], bounds[i]-1)
+		}
+		buckets = append(buckets, bucket)
+	}
+	return append(buckets, entity.AgeBucketCount{Label: "unknown"})
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
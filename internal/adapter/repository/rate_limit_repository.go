@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"people-enricher/internal/ratelimit"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimitRepo keeps token buckets in Postgres so all replicas share them
+type RateLimitRepo struct {
+	pool   *pgxpool.Pool
+	logger *logrus.Entry
+}
+
+func NewRateLimitRepo(pool *pgxpool.Pool, logger *logrus.Entry) *RateLimitRepo {
+	return &RateLimitRepo{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// Take takes a token from the bucket of key. The row is locked while the
+// bucket is refilled and the database clock is used, so replicas agree on
+// the time. The clock is read with clock_timestamp(), now() is the start of
+// the transaction, which may be before the last update of the row.
+func (r *RateLimitRepo) Take(ctx context.Context, key string, bucket ratelimit.Bucket, maxWait time.Duration) (ratelimit.State, time.Duration, bool, error) {
+	logger := r.logger.WithFields(logrus.Fields{"operation": "Take", "key": key})
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Error starting transaction")
+		return ratelimit.State{}, 0, false, fmt.Errorf("starting rate limit transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// A new bucket starts full
+	_, err = tx.Exec(ctx, `
+		INSERT INTO rate_limit_buckets(key, tokens)
+		VALUES ($1, $2)
+		ON CONFLICT (key) DO NOTHING
+	`, key, bucket.Burst)
+	if err != nil {
+		logger.WithError(err).Error("Error creating rate limit bucket")
+		return ratelimit.State{}, 0, false, fmt.Errorf("creating rate limit bucket: %w", err)
+	}
+
+	var state ratelimit.State
+	var now time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT tokens, updated_at, clock_timestamp()
+		FROM rate_limit_buckets
+		WHERE key = $1
+		FOR UPDATE
+	`, key).Scan(&state.Tokens, &state.At, &now)
+	if err != nil {
+		logger.WithError(err).Error("Error loading rate limit bucket")
+		return ratelimit.State{}, 0, false, fmt.Errorf("loading rate limit bucket: %w", err)
+	}
+
+	next, delay, ok := bucket.Take(state, now, maxWait)
+	if !ok {
+		return state, delay, false, nil
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1",
+		key, next.Tokens, next.At,
+	)
+	if err != nil {
+		logger.WithError(err).Error("Error saving rate limit bucket")
+		return ratelimit.State{}, 0, false, fmt.Errorf("saving rate limit bucket: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.WithError(err).Error("Error committing rate limit bucket")
+		return ratelimit.State{}, 0, false, fmt.Errorf("committing rate limit bucket: %w", err)
+	}
+	return next, delay, true, nil
+}
+
+// Prune removes the buckets not used for idle, which have refilled long
+// since and start full again when next used
+func (r *RateLimitRepo) Prune(ctx context.Context, idle time.Duration) (int64, error) {
+	cmdTag, err := r.pool.Exec(ctx,
+		"DELETE FROM rate_limit_buckets WHERE updated_at < now() - make_interval(secs => $1)",
+		idle.Seconds(),
+	)
+	if err != nil {
+		r.logger.WithError(err).Error("Error pruning rate limit buckets")
+		return 0, fmt.Errorf("pruning rate limit buckets: %w", err)
+	}
+	return cmdTag.RowsAffected(), nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"people-enricher/internal/entity"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+const tenantColumns = "id, name, enrichment, daily_enrichment_quota, created_at, updated_at"
+
+// TenantRepo stores tenants and counts their daily enrichments
+type TenantRepo struct {
+	pool   *pgxpool.Pool
+	logger *logrus.Entry
+}
+
+func NewTenantRepo(pool *pgxpool.Pool, logger *logrus.Entry) *TenantRepo {
+	return &TenantRepo{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func scanTenant(row pgx.Row) (*entity.Tenant, error) {
+	var tenant entity.Tenant
+	err := row.Scan(
+		&tenant.ID,
+		&tenant.Name,
+		&tenant.Enrichment,
+		&tenant.DailyEnrichmentQuota,
+		&tenant.CreatedAt,
+		&tenant.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *TenantRepo) Get(ctx context.Context, id string) (*entity.Tenant, error) {
+	tenant, err := scanTenant(r.pool.QueryRow(ctx, "SELECT "+tenantColumns+" FROM tenants WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("tenant %q %w", id, entity.ErrNotFound)
+		}
+		r.logger.WithError(err).WithField("tenant_id", id).Error("Failed getting tenant")
+		return nil, fmt.Errorf("getting tenant %s: %w", id, err)
+	}
+	return tenant, nil
+}
+
+func (r *TenantRepo) List(ctx context.Context) ([]*entity.Tenant, error) {
+	rows, err := r.pool.Query(ctx, "SELECT "+tenantColumns+" FROM tenants ORDER BY id")
+	if err != nil {
+		r.logger.WithError(err).Error("Failed listing tenants")
+		return nil, fmt.Errorf("listing tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []*entity.Tenant{}
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning tenant: %w", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// Put creates the tenant or replaces its settings
+func (r *TenantRepo) Put(ctx context.Context, tenant *entity.Tenant) (*entity.Tenant, error) {
+	logger := r.logger.WithField("operation", "PutTenant").WithField("tenant_id", tenant.ID)
+
+	stored, err := scanTenant(r.pool.QueryRow(ctx, `
+		INSERT INTO tenants(id, name, enrichment, daily_enrichment_quota)
+		VALUES($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE
+		SET name = EXCLUDED.name,
+			enrichment = EXCLUDED.enrichment,
+			daily_enrichment_quota = EXCLUDED.daily_enrichment_quota,
+			updated_at = now()
+		RETURNING `+tenantColumns,
+		tenant.ID, tenant.Name, tenant.Enrichment, tenant.DailyEnrichmentQuota,
+	))
+	if err != nil {
+		logger.WithError(err).Error("Failed storing tenant")
+		return nil, fmt.Errorf("storing tenant: %w", err)
+	}
+
+	logger.Info("Successfully stored tenant")
+	return stored, nil
+}
+
+// UseEnrichment counts one enrichment of the tenant for today. It reports
+// false, counting nothing, when limit enrichments were already made today.
+func (r *TenantRepo) UseEnrichment(ctx context.Context, tenantID string, limit int) (bool, error) {
+	cmdTag, err := r.pool.Exec(ctx, `
+		INSERT INTO tenant_usage(tenant_id, day, enrichments)
+		VALUES($1, current_date, 1)
+		ON CONFLICT (tenant_id, day) DO UPDATE
+		SET enrichments = tenant_usage.enrichments + 1
+		WHERE tenant_usage.enrichments < $2
+	`, tenantID, limit)
+	if err != nil {
+		r.logger.WithError(err).WithField("tenant_id", tenantID).Error("Failed counting tenant enrichment")
+		return false, fmt.Errorf("counting enrichment of tenant %s: %w", tenantID, err)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
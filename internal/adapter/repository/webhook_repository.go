@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"people-enricher/internal/entity"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+const webhookColumns = "id, url, events, description, active, created_at, updated_at"
+
+const deliveryColumns = "id, webhook_id, event_id, event_type, status, attempts, next_attempt_at, last_error, response_status, created_at, delivered_at"
+
+// WebhookRepo stores webhooks and their deliveries. Webhooks are managed
+// within the tenant of the context, deliveries are claimed across tenants.
+type WebhookRepo struct {
+	pool   *pgxpool.Pool
+	logger *logrus.Entry
+}
+
+func NewWebhookRepo(pool *pgxpool.Pool, logger *logrus.Entry) *WebhookRepo {
+	return &WebhookRepo{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func scanWebhook(row pgx.Row) (*entity.Webhook, error) {
+	var webhook entity.Webhook
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Events,
+		&webhook.Description,
+		&webhook.Active,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func scanDelivery(row pgx.Row) (*entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastError,
+		&delivery.ResponseStatus,
+		&delivery.CreatedAt,
+		&delivery.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *WebhookRepo) Create(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	logger := r.logger.WithField("operation", "CreateWebhook")
+	logger.Debug("Creating webhook")
+
+	created, err := scanWebhook(r.pool.QueryRow(ctx, `
+		INSERT INTO webhooks(url, secret, events, description, active, tenant_id)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING `+webhookColumns,
+		webhook.URL, webhook.Secret, webhook.Events, webhook.Description, webhook.Active, entity.TenantID(ctx),
+	))
+	if err != nil {
+		logger.WithError(err).Error("Failed creating webhook")
+		return nil, fmt.Errorf("creating webhook: %w", err)
+	}
+
+	logger.WithField("webhook_id", created.ID).Info("Successfully created webhook")
+	return created, nil
+}
+
+// Update replaces the webhook settings, an empty secret keeps the current one
+func (r *WebhookRepo) Update(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	logger := r.logger.WithField("operation", "UpdateWebhook").WithField("webhook_id", webhook.ID)
+	logger.Debug("Updating webhook")
+
+	updated, err := scanWebhook(r.pool.QueryRow(ctx, `
+		UPDATE webhooks
+		SET url = $1,
+			events = $2,
+			description = $3,
+			active = $4,
+			secret = COALESCE(NULLIF($5, ''), secret),
+			updated_at = now()
+		WHERE id = $6 AND tenant_id = $7
+		RETURNING `+webhookColumns,
+		webhook.URL, webhook.Events, webhook.Description, webhook.Active, webhook.Secret, webhook.ID, entity.TenantID(ctx),
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Warn("Webhook not found")
+			return nil, &entity.NotFoundError{Resource: "webhook", ID: webhook.ID}
+		}
+		logger.WithError(err).Error("Failed updating webhook")
+		return nil, fmt.Errorf("updating webhook: %w", err)
+	}
+
+	logger.Info("Successfully updated webhook")
+	return updated, nil
+}
+
+func (r *WebhookRepo) Delete(ctx context.Context, id int64) error {
+	logger := r.logger.WithField("operation", "DeleteWebhook").WithField("webhook_id", id)
+
+	cmdTag, err := r.pool.Exec(ctx, "DELETE FROM webhooks WHERE id = $1 AND tenant_id = $2", id, entity.TenantID(ctx))
+	if err != nil {
+		logger.WithError(err).Error("Failed removing webhook")
+		return fmt.Errorf("removing webhook: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		logger.Warn("Webhook not found")
+		return &entity.NotFoundError{Resource: "webhook", ID: id}
+	}
+
+	logger.Info("Successfully removed webhook")
+	return nil
+}
+
+func (r *WebhookRepo) GetByID(ctx context.Context, id int64) (*entity.Webhook, error) {
+	webhook, err := scanWebhook(r.pool.QueryRow(ctx,
+		"SELECT "+webhookColumns+" FROM webhooks WHERE id = $1 AND tenant_id = $2",
+		id, entity.TenantID(ctx),
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &entity.NotFoundError{Resource: "webhook", ID: id}
+		}
+		r.logger.WithError(err).WithField("webhook_id", id).Error("Failed getting webhook")
+		return nil, fmt.Errorf("getting webhook %d: %w", id, err)
+	}
+	return webhook, nil
+}
+
+func (r *WebhookRepo) List(ctx context.Context) ([]*entity.Webhook, error) {
+	rows, err := r.pool.Query(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE tenant_id = $1 ORDER BY id", entity.TenantID(ctx))
+	if err != nil {
+		r.logger.WithError(err).Error("Failed listing webhooks")
+		return nil, fmt.Errorf("listing webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*entity.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// EnqueueDeliveries creates a pending delivery of the event for every active
+// webhook of its tenant subscribed to its type. Enqueueing the same event twice is a no-op,
+// which keeps at-least-once relaying from duplicating deliveries.
+func (r *WebhookRepo) EnqueueDeliveries(ctx context.Context, event entity.Event) (int64, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("encoding event: %w", err)
+	}
+
+	cmdTag, err := r.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries(webhook_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $3
+		FROM webhooks
+		WHERE active AND $2 = ANY(events) AND tenant_id = $4
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+	`, event.ID, event.Type, payload, event.TenantID)
+	if err != nil {
+		r.logger.WithError(err).WithField("event_id", event.ID).Error("Failed enqueueing webhook deliveries")
+		return 0, fmt.Errorf("enqueueing webhook deliveries: %w", err)
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// ClaimDue leases up to limit due deliveries for the given duration, so other
+// workers skip them while they are being sent.
+func (r *WebhookRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.DueDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE status IN ('pending', 'failed') AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + $2::interval
+		FROM due, webhooks w
+		WHERE d.id = due.id AND w.id = d.webhook_id
+		RETURNING d.id, d.webhook_id, d.event_id, d.event_type, d.attempts, d.payload, w.url, w.secret
+	`, limit, lease)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed claiming webhook deliveries")
+		return nil, fmt.Errorf("claiming webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	due := []*entity.DueDelivery{}
+	for rows.Next() {
+		var delivery entity.DueDelivery
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Attempts,
+			&delivery.Payload,
+			&delivery.URL,
+			&delivery.Secret,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning webhook delivery: %w", err)
+		}
+		due = append(due, &delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading webhook deliveries: %w", err)
+	}
+	return due, nil
+}
+
+func (r *WebhookRepo) MarkSucceeded(ctx context.Context, id int64, responseStatus int) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'succeeded', attempts = attempts + 1, response_status = $2,
+			last_error = NULL, delivered_at = now(), next_attempt_at = NULL
+		WHERE id = $1
+	`, id, responseStatus)
+	if err != nil {
+		return fmt.Errorf("marking delivery %d succeeded: %w", id, err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt. A nil nextAttempt moves the delivery to
+// the dead state, otherwise it is retried at nextAttempt.
+func (r *WebhookRepo) MarkFailed(ctx context.Context, id int64, responseStatus *int, reason string, nextAttempt *time.Time) error {
+	status := entity.DeliveryFailed
+	if nextAttempt == nil {
+		status = entity.DeliveryDead
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, response_status = $3, last_error = $4, next_attempt_at = $5
+		WHERE id = $1
+	`, id, status, responseStatus, reason, nextAttempt)
+	if err != nil {
+		return fmt.Errorf("marking delivery %d failed: %w", id, err)
+	}
+	return nil
+}
+
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, webhookID int64, filter *entity.DeliveryFilter) ([]*entity.WebhookDelivery, int, error) {
+	logger := r.logger.WithField("operation", "ListDeliveries").WithField("webhook_id", webhookID)
+
+	conditions := &conditionBuilder{}
+	conditions.compare("webhook_id", "=", webhookID)
+	if filter.Status != nil {
+		conditions.compare("status", "=", *filter.Status)
+	}
+	whereClause := conditions.where()
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM webhook_deliveries "+whereClause, conditions.args...).Scan(&total); err != nil {
+		logger.WithError(err).Error("Failed counting deliveries")
+		return nil, 0, fmt.Errorf("counting deliveries: %w", err)
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 20
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM webhook_deliveries
+		%s
+		ORDER BY id DESC
+		LIMIT $%d OFFSET $%d
+	`, deliveryColumns, whereClause, conditions.nextPlaceholder(), conditions.nextPlaceholder()+1)
+	args := append(conditions.args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		logger.WithError(err).Error("Failed listing deliveries")
+		return nil, 0, fmt.Errorf("listing deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*entity.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scanning delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("reading deliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
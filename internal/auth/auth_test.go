@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"people-enricher/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"Bearer abc", "abc"},
+		{"bearer abc", "abc"},
+		{"BEARER  abc ", "abc"},
+		{"Basic YWxhZGRpbjpvcGVuc2VzYW1l", ""},
+		{"Bearer", ""},
+		{"abc", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := BearerToken(tt.header); got != tt.want {
+			t.Errorf("BearerToken(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticatorBootstrap(t *testing.T) {
+	authenticator := NewAuthenticator(nil, nil, "a-random-bootstrap-key", logrus.NewEntry(logrus.New()))
+
+	for _, credentials := range [][2]string{{"a-random-bootstrap-key", ""}, {"", "a-random-bootstrap-key"}} {
+		principal, err := authenticator.Authenticate(context.Background(), credentials[0], credentials[1])
+		if err != nil {
+			t.Fatalf("Authenticate(%q, %q): %v", credentials[0], credentials[1], err)
+		}
+		if principal.Subject != "bootstrap" || principal.Tenant != "" {
+			t.Errorf("principal = %+v, want the bootstrap admin of no tenant", principal)
+		}
+	}
+	if _, err := authenticator.Authenticate(context.Background(), "", ""); err == nil {
+		t.Error("request without credentials was authenticated")
+	}
+}
+
+// jwksServer publishes one Ed25519 key and blocks while block is not nil
+func jwksServer(t *testing.T, block chan struct{}) *httptest.Server {
+	public, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := fmt.Sprintf(`{"keys":[{"kty":"OKP","crv":"Ed25519","kid":"a","x":%q}]}`,
+		base64.RawURLEncoding.EncodeToString(public))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if block != nil {
+			<-block
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestJWKSLookup(t *testing.T) {
+	server := jwksServer(t, nil)
+	keys := newJWKS(server.URL, time.Hour, logrus.NewEntry(logrus.New()))
+
+	if got := keys.lookup(context.Background(), "a"); len(got) != 1 {
+		t.Fatalf("lookup(a) = %d keys, want 1", len(got))
+	}
+	if got := keys.lookup(context.Background(), ""); len(got) != 1 {
+		t.Errorf("lookup without key ID = %d keys, want 1", len(got))
+	}
+	// Unknown IDs do not refetch more than once per jwksRetry
+	if got := keys.lookup(context.Background(), "b"); got != nil {
+		t.Errorf("lookup(b) = %v, want no keys", got)
+	}
+}
+
+func TestJWKSRefetchDoesNotBlockKnownKeys(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	server := jwksServer(t, nil)
+	keys := newJWKS(server.URL, time.Hour, logrus.NewEntry(logrus.New()))
+	if got := keys.lookup(context.Background(), "a"); len(got) != 1 {
+		t.Fatalf("lookup(a) = %d keys, want 1", len(got))
+	}
+
+	// The keys are stale and the JWKS endpoint hangs
+	slow := jwksServer(t, block)
+	keys.mu.Lock()
+	keys.url, keys.fetched, keys.attempted = slow.URL, time.Time{}, time.Time{}
+	keys.mu.Unlock()
+
+	done := make(chan []any)
+	go func() { done <- keys.lookup(context.Background(), "a") }()
+	select {
+	case got := <-done:
+		if len(got) != 1 {
+			t.Errorf("lookup(a) during a refetch = %d keys, want 1", len(got))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lookup of a known key waited for the refetch")
+	}
+
+	// Tokens with an unknown key wait for it until they give up
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if got := keys.lookup(ctx, "b"); got != nil {
+		t.Errorf("lookup(b) = %v, want no keys", got)
+	}
+}
+
+func TestAnonymousIsAdminOfNoTenant(t *testing.T) {
+	principal, err := Anonymous{}.Authenticate(context.Background(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.Method != entity.AuthNone || principal.Tenant != "" ||
+		len(principal.Roles) != 1 || principal.Roles[0] != entity.RoleAdmin {
+		t.Errorf("principal = %+v, want an admin of no tenant", principal)
+	}
+}
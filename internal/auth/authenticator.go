@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"people-enricher/internal/entity"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// KeyStore finds the active API key with a hash
+type KeyStore interface {
+	Authenticate(ctx context.Context, hash string) (*entity.APIKey, error)
+}
+
+// Authenticator turns request credentials into a principal
+type Authenticator struct {
+	keys          KeyStore
+	jwt           *JWTVerifier
+	bootstrapHash string
+	logger        *logrus.Entry
+}
+
+// NewAuthenticator accepts the API keys in keys, JWTs when jwt is not nil and
+// bootstrapKey when it is not empty
+func NewAuthenticator(keys KeyStore, jwt *JWTVerifier, bootstrapKey string, logger *logrus.Entry) *Authenticator {
+	a := &Authenticator{keys: keys, jwt: jwt, logger: logger}
+	if bootstrapKey != "" {
+		a.bootstrapHash = HashAPIKey(bootstrapKey)
+		logger.Warn("The bootstrap key is enabled, unset AUTH_BOOTSTRAP_KEY once stored API keys exist")
+	}
+	return a
+}
+
+// Authenticate checks the API key or bearer token of a request, whichever
+// is given. Bearer tokens that look like API keys are checked as such.
+// Failures match entity.ErrUnauthorized.
+func (a *Authenticator) Authenticate(ctx context.Context, apiKey, bearer string) (*entity.Principal, error) {
+	if apiKey == "" && (IsAPIKey(bearer) || a.isBootstrap(bearer)) {
+		apiKey, bearer = bearer, ""
+	}
+
+	switch {
+	case apiKey != "":
+		if a.isBootstrap(apiKey) {
+			a.logger.Warn("Request authenticated with the bootstrap key")
+			// Not bound to a tenant, it picks one with the tenant header
+			return &entity.Principal{Subject: "bootstrap", Method: entity.AuthBootstrap, Roles: []string{entity.RoleAdmin}}, nil
+		}
+		key, err := a.keys.Authenticate(ctx, HashAPIKey(apiKey))
+		if err != nil {
+			return nil, err
+		}
+		return &entity.Principal{
+			Subject: fmt.Sprintf("api_key:%d", key.ID),
+			Method:  entity.AuthAPIKey,
+			Name:    key.Name,
+			Roles:   []string{key.Role},
+			Tenant:  key.TenantID,
+		}, nil
+	case bearer != "":
+		if a.jwt == nil {
+			return nil, fmt.Errorf("%w: bearer tokens are not accepted", entity.ErrUnauthorized)
+		}
+		return a.jwt.Verify(ctx, bearer)
+	}
+	return nil, fmt.Errorf("%w: no credentials", entity.ErrUnauthorized)
+}
+
+func (a *Authenticator) isBootstrap(key string) bool {
+	return a.bootstrapHash != "" && key != "" &&
+		subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(a.bootstrapHash)) == 1
+}
+
+// Anonymous authenticates every request as an admin of no tenant, it stands
+// in for an Authenticator when authentication is disabled
+type Anonymous struct{}
+
+func (Anonymous) Authenticate(ctx context.Context, apiKey, bearer string) (*entity.Principal, error) {
+	return &entity.Principal{Subject: "anonymous", Method: entity.AuthNone, Roles: []string{entity.RoleAdmin}}, nil
+}
+
+// BearerToken returns the token of an Authorization header value with the
+// Bearer scheme, which is matched case-insensitively (RFC 7235), and an empty
+// string for other schemes
+func BearerToken(authorization string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// jwksRetry is how often an unknown key ID may trigger a refetch, so tokens
+// with made up key IDs can not hammer the JWKS endpoint
+const jwksRetry = time.Minute
+
+// jwks caches the public keys published at a JWKS URL by key ID
+type jwks struct {
+	url        string
+	refresh    time.Duration
+	httpClient *http.Client
+	logger     *logrus.Entry
+
+	mu        sync.Mutex
+	keys      map[string]any
+	fetched   time.Time
+	attempted time.Time
+	// fetching is closed when the running fetch is done, nil when none runs
+	fetching chan struct{}
+}
+
+func newJWKS(url string, refresh time.Duration, logger *logrus.Entry) *jwks {
+	return &jwks{
+		url:        url,
+		refresh:    refresh,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		logger:     logger.WithField("jwks_url", url),
+	}
+}
+
+// lookup returns the key with the given ID, or all keys when the token did
+// not name one. Stale keys are refetched, and so are keys when the ID is
+// unknown, at most once per jwksRetry. The fetch runs without holding the
+// lock: tokens with known keys are verified with them meanwhile, only the
+// ones waiting for a new key wait for the fetch.
+func (s *jwks) lookup(ctx context.Context, kid string) []any {
+	s.mu.Lock()
+	_, known := s.keys[kid]
+	missing := len(s.keys) == 0 || (kid != "" && !known)
+	stale := time.Since(s.fetched) > s.refresh
+	if (stale || missing) && s.fetching == nil && time.Since(s.attempted) > jwksRetry {
+		s.attempted = time.Now()
+		s.fetching = make(chan struct{})
+		go s.fetch(s.fetching)
+	}
+	fetching := s.fetching
+	s.mu.Unlock()
+
+	if missing && fetching != nil {
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if kid != "" {
+		if key, ok := s.keys[kid]; ok {
+			return []any{key}
+		}
+		return nil
+	}
+	keys := make([]any, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// fetch replaces the cached keys and closes done. It does not depend on the
+// request that started it, the HTTP client timeout bounds it.
+func (s *jwks) fetch(done chan struct{}) {
+	keys, err := s.load(context.Background())
+
+	s.mu.Lock()
+	if err != nil {
+		// Keep verifying with the keys we have
+		s.logger.WithError(err).Warn("Failed to fetch JWKS")
+	} else {
+		s.keys = keys
+		s.fetched = time.Now()
+		s.logger.WithField("keys", len(keys)).Debug("Fetched JWKS")
+	}
+	s.fetching = nil
+	s.mu.Unlock()
+	close(done)
+}
+
+func (s *jwks) load(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decoding JWKS: %w", err)
+	}
+
+	keys := map[string]any{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			s.logger.WithError(err).WithField("kid", jwk.Kid).Warn("Skipping JWKS key")
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// jsonWebKey is a public key as published in a JWKS (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, fmt.Errorf("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"people-enricher/internal/config"
+	"people-enricher/internal/entity"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// publicKeyMethods are the asymmetric algorithms accepted for JWTs
+var publicKeyMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// JWTVerifier verifies JWT bearer tokens with static keys and the keys of a
+// JWKS URL
+type JWTVerifier struct {
+	rolesClaim  string
+	tenantClaim string
+	static      []jwt.VerificationKey
+	jwks        *jwks
+	parser      *jwt.Parser
+}
+
+// NewJWTVerifier returns the verifier of the configured keys, nil when no
+// key is configured and JWTs are not accepted
+func NewJWTVerifier(cfg config.AuthCfg, logger *logrus.Entry) (*JWTVerifier, error) {
+	v := &JWTVerifier{rolesClaim: cfg.JWTRolesClaim, tenantClaim: cfg.JWTTenantClaim}
+	methods := publicKeyMethods
+
+	if cfg.JWTSecret != "" {
+		v.static = append(v.static, []byte(cfg.JWTSecret))
+		methods = append([]string{"HS256", "HS384", "HS512"}, methods...)
+	}
+	for _, path := range cfg.JWTPublicKeys {
+		key, err := loadPublicKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("loading JWT public key %s: %w", path, err)
+		}
+		v.static = append(v.static, key)
+	}
+	if cfg.JWKSURL != "" {
+		v.jwks = newJWKS(cfg.JWKSURL, cfg.JWKSRefresh, logger)
+	}
+	if len(v.static) == 0 && v.jwks == nil {
+		return nil, nil
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if cfg.JWTIssuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		options = append(options, jwt.WithAudience(cfg.JWTAudience))
+	}
+	v.parser = jwt.NewParser(options...)
+	return v, nil
+}
+
+// Verify checks the signature and claims of a token and returns its subject
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*entity.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
+		keys := jwt.VerificationKeySet{Keys: append([]jwt.VerificationKey{}, v.static...)}
+		if v.jwks != nil {
+			kid, _ := token.Header["kid"].(string)
+			for _, key := range v.jwks.lookup(ctx, kid) {
+				keys.Keys = append(keys.Keys, key)
+			}
+		}
+		if len(keys.Keys) == 0 {
+			return nil, errors.New("no key to verify the token with")
+		}
+		return keys, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", entity.ErrUnauthorized, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", entity.ErrUnauthorized)
+	}
+	name, _ := claims["name"].(string)
+	if name == "" {
+		name, _ = claims["preferred_username"].(string)
+	}
+	// Tokens without a tenant act on the default one, only the bootstrap key
+	// may pick a tenant
+	tenant, _ := claims[v.tenantClaim].(string)
+	if tenant == "" {
+		tenant = entity.DefaultTenant
+	}
+	return &entity.Principal{
+		Subject: subject,
+		Method:  entity.AuthJWT,
+		Name:    name,
+		Roles:   roles(claims[v.rolesClaim]),
+		Tenant:  tenant,
+		Claims:  claims,
+	}, nil
+}
+
+// roles reads the known roles from a claim holding a list or a space or
+// comma separated string, as identity providers publish either
+func roles(claim any) []string {
+	var values []string
+	switch claim := claim.(type) {
+	case string:
+		values = strings.FieldsFunc(claim, func(r rune) bool { return r == ' ' || r == ',' })
+	case []any:
+		for _, value := range claim {
+			if value, ok := value.(string); ok {
+				values = append(values, value)
+			}
+		}
+	}
+
+	var known []string
+	for _, value := range values {
+		if slices.Contains(entity.Roles, value) {
+			known = append(known, value)
+		}
+	}
+	return known
+}
+
+// loadPublicKey reads an RSA, ECDSA or Ed25519 public key from a PEM file
+func loadPublicKey(path string) (jwt.VerificationKey, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pem); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM(pem); err == nil {
+		return key, nil
+	}
+	key, err := jwt.ParseEdPublicKeyFromPEM(pem)
+	if err != nil {
+		return nil, errors.New("not an RSA, ECDSA or Ed25519 public key")
+	}
+	return key.(ed25519.PublicKey), nil
+}
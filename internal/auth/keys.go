@@ -0,0 +1,40 @@
+// Package auth authenticates API callers by API key or JWT bearer token.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// KeyPrefix starts every API key, so keys are told apart from JWTs and are
+// easy to spot for secret scanners
+const KeyPrefix = "pe_"
+
+// DisplayPrefixLen is how much of a key is stored in the clear to tell keys
+// apart
+const DisplayPrefixLen = len(KeyPrefix) + 6
+
+// GenerateAPIKey returns a new random API key
+func GenerateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating API key: %w", err)
+	}
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashAPIKey returns the hash keys are stored and looked up by. Keys are
+// random, so a plain SHA-256 is enough.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether a credential looks like an API key
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, KeyPrefix)
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"people-enricher/internal/entity"
+	"sync"
+	"time"
+)
+
+// Cached wraps the coalescable providers, so the estimate of a name is reused
+// for ttl instead of asking the provider again. At most size estimates are
+// kept per provider, a zero ttl disables the cache.
+func Cached(providers []Provider, ttl time.Duration, size int) []Provider {
+	if ttl <= 0 {
+		return providers
+	}
+	cached := make([]Provider, 0, len(providers))
+	for _, provider := range providers {
+		if _, ok := provider.(coalescer); !ok {
+			cached = append(cached, provider)
+			continue
+		}
+		cached = append(cached, &cachedProvider{
+			Provider: provider,
+			ttl:      ttl,
+			size:     size,
+			entries:  map[string]*cacheEntry{},
+			recent:   list.New(),
+			expiring: list.New(),
+		})
+	}
+	return cached
+}
+
+// cacheEntry is linked in two lists: recent, ordered by use, and expiring,
+// ordered by expiry. All entries live for the same ttl, so refreshing an entry
+// moves it to the back of expiring.
+type cacheEntry struct {
+	key      string
+	estimate *Estimate
+	expires  time.Time
+
+	recent   *list.Element
+	expiring *list.Element
+}
+
+type cachedProvider struct {
+	Provider
+	ttl  time.Duration
+	size int
+
+	mu       sync.Mutex
+	entries  map[string]*cacheEntry
+	recent   *list.List
+	expiring *list.List
+}
+
+func (p *cachedProvider) Estimate(ctx context.Context, person *entity.Person, fields Field) (*Estimate, error) {
+	key, ok := p.Provider.(coalescer).coalesceKey(person)
+	if !ok {
+		return p.Provider.Estimate(ctx, person, fields)
+	}
+	if estimate := p.get(key); estimate != nil {
+		return estimate, nil
+	}
+
+	estimate, err := p.Provider.Estimate(ctx, person, fields)
+	if err != nil {
+		return nil, err
+	}
+	p.put(key, estimate)
+	return estimate, nil
+}
+
+func (p *cachedProvider) get(key string) *Estimate {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.entries[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expires) {
+		p.remove(entry)
+		return nil
+	}
+	p.recent.MoveToFront(entry.recent)
+	estimate := entry.estimate.clone()
+	estimate.Cached = true
+	return estimate
+}
+
+// put stores the estimate of key. A full cache first drops the expired
+// estimates, then the least recently used one.
+func (p *cachedProvider) put(key string, estimate *Estimate) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := p.entries[key]; ok {
+		entry.estimate = estimate.clone()
+		entry.expires = now.Add(p.ttl)
+		p.recent.MoveToFront(entry.recent)
+		p.expiring.MoveToBack(entry.expiring)
+		return
+	}
+
+	for len(p.entries) >= p.size && p.expiring.Len() > 0 {
+		oldest := p.expiring.Front().Value.(*cacheEntry)
+		if !now.After(oldest.expires) {
+			break
+		}
+		p.remove(oldest)
+	}
+	if len(p.entries) >= p.size && p.recent.Len() > 0 {
+		p.remove(p.recent.Back().Value.(*cacheEntry))
+	}
+
+	entry := &cacheEntry{key: key, estimate: estimate.clone(), expires: now.Add(p.ttl)}
+	entry.recent = p.recent.PushFront(entry)
+	entry.expiring = p.expiring.PushBack(entry)
+	p.entries[key] = entry
+}
+
+// remove drops an entry, p.mu must be held
+func (p *cachedProvider) remove(entry *cacheEntry) {
+	p.recent.Remove(entry.recent)
+	p.expiring.Remove(entry.expiring)
+	delete(p.entries, entry.key)
+}
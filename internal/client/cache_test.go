@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"people-enricher/internal/entity"
+)
+
+// countingProvider answers an age for every name and counts its calls
+type countingProvider struct {
+	calls int
+	err   error
+}
+
+func (p *countingProvider) Name() string  { return "counting" }
+func (p *countingProvider) Fields() Field { return FieldAge }
+
+func (p *countingProvider) Estimate(ctx context.Context, person *entity.Person, fields Field) (*Estimate, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &Estimate{Age: &AgeGuess{Age: 40, Samples: 10}}, nil
+}
+
+func (p *countingProvider) coalesceKey(person *entity.Person) (string, bool) {
+	return nameKey(p.Name(), person)
+}
+
+func TestCachedReusesEstimates(t *testing.T) {
+	upstream := &countingProvider{}
+	provider := Cached([]Provider{upstream}, time.Hour, 10)[0]
+	ctx := context.Background()
+
+	first, err := provider.Estimate(ctx, &entity.Person{Name: "Ivan"}, FieldAge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Cached {
+		t.Error("first estimate is marked cached")
+	}
+
+	// Names are compared like the providers look them up
+	second, err := provider.Estimate(ctx, &entity.Person{Name: "ivan"}, FieldAge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Cached || second.Age == nil || second.Age.Age != 40 {
+		t.Errorf("second estimate = %+v, want the cached age 40", second)
+	}
+	if upstream.calls != 1 {
+		t.Errorf("provider called %d times, want 1", upstream.calls)
+	}
+
+	// Callers own their copy
+	second.Age.Age = 1
+	third, _ := provider.Estimate(ctx, &entity.Person{Name: "Ivan"}, FieldAge)
+	if third.Age.Age != 40 {
+		t.Errorf("cached age changed to %d through a returned estimate", third.Age.Age)
+	}
+}
+
+func TestCachedSkipsErrorsAndExpires(t *testing.T) {
+	upstream := &countingProvider{err: errors.New("unavailable")}
+	provider := Cached([]Provider{upstream}, time.Millisecond, 10)[0]
+	ctx := context.Background()
+
+	if _, err := provider.Estimate(ctx, &entity.Person{Name: "Ivan"}, FieldAge); err == nil {
+		t.Fatal("error was not passed on")
+	}
+	upstream.err = nil
+	if estimate, err := provider.Estimate(ctx, &entity.Person{Name: "Ivan"}, FieldAge); err != nil || estimate.Cached {
+		t.Fatalf("estimate after an error = %+v, %v, want a fresh estimate", estimate, err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if estimate, _ := provider.Estimate(ctx, &entity.Person{Name: "Ivan"}, FieldAge); estimate.Cached {
+		t.Error("expired estimate was reused")
+	}
+	if upstream.calls != 3 {
+		t.Errorf("provider called %d times, want 3", upstream.calls)
+	}
+}
+
+func TestCachedEvictsWhenFull(t *testing.T) {
+	upstream := &countingProvider{}
+	provider := Cached([]Provider{upstream}, time.Hour, 2)[0].(*cachedProvider)
+	ctx := context.Background()
+
+	for _, name := range []string{"Ivan", "Petr", "Anna", "Olga"} {
+		if _, err := provider.Estimate(ctx, &entity.Person{Name: name}, FieldAge); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(provider.entries) != 2 {
+		t.Errorf("cache holds %d estimates, want 2", len(provider.entries))
+	}
+}
+
+func TestCachedDisabled(t *testing.T) {
+	upstream := &countingProvider{}
+	if provider := Cached([]Provider{upstream}, 0, 10)[0]; provider != upstream {
+		t.Errorf("zero ttl wrapped the provider in %T", provider)
+	}
+}
+
+func TestCachedEvictsExpiredThenLeastRecentlyUsed(t *testing.T) {
+	upstream := &countingProvider{}
+	provider := Cached([]Provider{upstream}, time.Hour, 2)[0].(*cachedProvider)
+	ctx := context.Background()
+	estimate := func(name string) *Estimate {
+		estimate, err := provider.Estimate(ctx, &entity.Person{Name: name}, FieldAge)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return estimate
+	}
+
+	// Ivan is used after Petr, Anna then replaces Petr
+	estimate("Ivan")
+	estimate("Petr")
+	estimate("Ivan")
+	estimate("Anna")
+	if !estimate("Ivan").Cached {
+		t.Error("recently used estimate was evicted")
+	}
+
+	// An expired estimate goes first, however recently it was used
+	key, _ := upstream.coalesceKey(&entity.Person{Name: "Ivan"})
+	provider.entries[key].expires = time.Now().Add(-time.Second)
+	estimate("Olga")
+	if !estimate("Anna").Cached {
+		t.Error("live estimate was evicted before an expired one")
+	}
+	if upstream.calls != 4 {
+		t.Errorf("provider called %d times, want 4", upstream.calls)
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"people-enricher/internal/entity"
+	"slices"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// coalescer is implemented by providers whose estimate only depends on part
+// of the person. Estimates with the same key give the same answer, ok is
+// false when the estimate can not be shared.
+type coalescer interface {
+	coalesceKey(person *entity.Person) (key string, ok bool)
+}
+
+// Coalesced wraps the providers that support it, so concurrent estimates of
+// the same name share one in-flight request and its result
+func Coalesced(providers []Provider) []Provider {
+	coalesced := make([]Provider, 0, len(providers))
+	for _, provider := range providers {
+		if _, ok := provider.(coalescer); !ok {
+			coalesced = append(coalesced, provider)
+			continue
+		}
+		coalesced = append(coalesced, &coalescedProvider{Provider: provider})
+	}
+	return coalesced
+}
+
+type coalescedProvider struct {
+	Provider
+	flights singleflight.Group
+}
+
+func (p *coalescedProvider) Estimate(ctx context.Context, person *entity.Person, fields Field) (*Estimate, error) {
+	key, ok := p.Provider.(coalescer).coalesceKey(person)
+	if !ok {
+		return p.Provider.Estimate(ctx, person, fields)
+	}
+
+	// The shared request must not fail because the caller that started it
+	// went away, it is still bounded by the HTTP client timeout
+	shared := context.WithoutCancel(ctx)
+	flight := p.flights.DoChan(key, func() (any, error) {
+		return p.Provider.Estimate(shared, person, fields)
+	})
+
+	select {
+	case result := <-flight:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		// Each caller gets its own copy, the estimate ends up in its person
+		return result.Val.(*Estimate).clone(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// coalesceKey keeps the wrapped provider cacheable
+func (p *coalescedProvider) coalesceKey(person *entity.Person) (string, bool) {
+	return p.Provider.(coalescer).coalesceKey(person)
+}
+
+// nameKey is the coalescing key of providers that estimate by first name
+func nameKey(provider string, person *entity.Person) (string, bool) {
+	return provider + ":" + lookupKey(firstName(person)), true
+}
+
+func (e *Estimate) clone() *Estimate {
+	clone := &Estimate{Nationalities: slices.Clone(e.Nationalities), Cached: e.Cached}
+	if e.Age != nil {
+		age := *e.Age
+		clone.Age = &age
+	}
+	if e.Gender != nil {
+		gender := *e.Gender
+		clone.Gender = &gender
+	}
+	return clone
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"people-enricher/internal/entity"
+)
+
+// blockingProvider answers once release is closed and counts its calls
+type blockingProvider struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (p *blockingProvider) Name() string  { return "blocking" }
+func (p *blockingProvider) Fields() Field { return FieldAge }
+
+func (p *blockingProvider) Estimate(ctx context.Context, person *entity.Person, fields Field) (*Estimate, error) {
+	p.calls.Add(1)
+	<-p.release
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &Estimate{Age: &AgeGuess{Age: 40}}, nil
+}
+
+func (p *blockingProvider) coalesceKey(person *entity.Person) (string, bool) {
+	return nameKey(p.Name(), person)
+}
+
+func TestCoalescedSharesConcurrentEstimates(t *testing.T) {
+	upstream := &blockingProvider{release: make(chan struct{})}
+	provider := Coalesced([]Provider{upstream})[0]
+
+	const callers = 10
+	var wg sync.WaitGroup
+	estimates := make([]*Estimate, callers)
+	errs := make([]error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			estimates[i], errs[i] = provider.Estimate(context.Background(), &entity.Person{Name: "Ivan"}, FieldAge)
+		}()
+	}
+	// Let the callers join the flight before it lands
+	time.Sleep(50 * time.Millisecond)
+	close(upstream.release)
+	wg.Wait()
+
+	if calls := upstream.calls.Load(); calls != 1 {
+		t.Errorf("provider called %d times, want 1", calls)
+	}
+	for i := range callers {
+		if errs[i] != nil || estimates[i].Age == nil || estimates[i].Age.Age != 40 {
+			t.Fatalf("estimate %d = %+v, %v, want age 40", i, estimates[i], errs[i])
+		}
+	}
+	// Callers own their copy
+	estimates[0].Age.Age = 1
+	if estimates[1].Age.Age != 40 {
+		t.Error("an estimate changed through another caller's copy")
+	}
+}
+
+func TestCoalescedCallerGivesUp(t *testing.T) {
+	upstream := &blockingProvider{release: make(chan struct{})}
+	provider := Coalesced([]Provider{upstream})[0]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	gaveUp := make(chan error)
+	go func() {
+		_, err := provider.Estimate(ctx, &entity.Person{Name: "Ivan"}, FieldAge)
+		gaveUp <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-gaveUp; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled estimate = %v, want context.Canceled", err)
+	}
+
+	// The flight goes on without the caller that started it
+	joined := make(chan error)
+	go func() {
+		_, err := provider.Estimate(context.Background(), &entity.Person{Name: "Ivan"}, FieldAge)
+		joined <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(upstream.release)
+	if err := <-joined; err != nil {
+		t.Errorf("joined estimate = %v, want the shared answer", err)
+	}
+	if calls := upstream.calls.Load(); calls != 1 {
+		t.Errorf("provider called %d times, want 1", calls)
+	}
+}
+
+func TestCoalescedKeepsOtherProviders(t *testing.T) {
+	upstream := &blockingProvider{}
+	plain := &SurnameDictionary{}
+	providers := Coalesced([]Provider{upstream, plain})
+	if _, ok := providers[0].(*coalescedProvider); !ok {
+		t.Error("coalescing provider is not wrapped")
+	}
+	if providers[1] != Provider(plain) {
+		t.Error("provider without a coalescing key is wrapped")
+	}
+}
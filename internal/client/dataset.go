@@ -0,0 +1,285 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"people-enricher/internal/entity"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// DatasetRow is one statistic about a first name. Field is "age" with the
+// mean age as Value, "gender" with male or female and the probability, or
+// "nationality" with a country code and its probability.
+type DatasetRow struct {
+	Name        string  `parquet:"name"`
+	Field       string  `parquet:"field"`
+	Value       string  `parquet:"value"`
+	Probability float64 `parquet:"probability,optional"`
+}
+
+// Dataset estimates age, gender and nationality by first name from local
+// statistics held in memory, without network access
+type Dataset struct {
+	index     map[string]uint32
+	entries   []datasetEntry
+	countries []datasetCountry
+}
+
+// datasetEntry packs the statistics of one name. Its countries are
+// countries[first:first+count].
+type datasetEntry struct {
+	age               uint8
+	gender            uint8
+	genderProbability float32
+	first             uint32
+	count             uint16
+}
+
+type datasetCountry struct {
+	code        [2]byte
+	probability float32
+}
+
+// Gender codes of datasetEntry, zero is unknown
+const (
+	datasetMale uint8 = iota + 1
+	datasetFemale
+)
+
+// LoadDataset reads the datasets at paths into one Dataset. Files ending in
+// .parquet are read as Parquet, .csv.gz as gzipped CSV and others as CSV with
+// name,field,value,probability columns. Later files override earlier ones
+// for the same name and field.
+func LoadDataset(paths ...string) (*Dataset, error) {
+	builder := newDatasetBuilder()
+	for _, path := range paths {
+		if err := loadDatasetFile(builder, path); err != nil {
+			return nil, fmt.Errorf("loading dataset %s: %w", path, err)
+		}
+	}
+	return builder.build(), nil
+}
+
+// ReadDataset reads a CSV dataset
+func ReadDataset(r io.Reader) (*Dataset, error) {
+	builder := newDatasetBuilder()
+	if err := readDatasetCSV(builder, r); err != nil {
+		return nil, err
+	}
+	return builder.build(), nil
+}
+
+func loadDatasetFile(builder *datasetBuilder, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch {
+	case strings.HasSuffix(path, ".parquet"):
+		return readDatasetParquet(builder, file)
+	case strings.HasSuffix(path, ".gz"):
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		return readDatasetCSV(builder, reader)
+	}
+	return readDatasetCSV(builder, file)
+}
+
+func readDatasetCSV(builder *datasetBuilder, r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if line == 1 && record[0] == "name" && record[1] == "field" {
+			continue
+		}
+
+		row := DatasetRow{Name: record[0], Field: record[1], Value: record[2]}
+		if record[3] != "" {
+			if row.Probability, err = strconv.ParseFloat(record[3], 64); err != nil {
+				return fmt.Errorf("line %d: invalid probability %q", line, record[3])
+			}
+		}
+		if err := builder.add(row); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+func readDatasetParquet(builder *datasetBuilder, file *os.File) error {
+	reader := parquet.NewGenericReader[DatasetRow](file)
+	defer reader.Close()
+
+	rows := make([]DatasetRow, 1024)
+	for {
+		n, err := reader.Read(rows)
+		for _, row := range rows[:n] {
+			if err := builder.add(row); err != nil {
+				return fmt.Errorf("row %q: %w", row.Name, err)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Len is the number of names in the dataset
+func (d *Dataset) Len() int {
+	return len(d.entries)
+}
+
+func (d *Dataset) Name() string  { return "dataset" }
+func (d *Dataset) Fields() Field { return AllFields }
+
+func (d *Dataset) Estimate(ctx context.Context, person *entity.Person, fields Field) (*Estimate, error) {
+	estimate := &Estimate{}
+	i, ok := d.index[lookupKey(firstName(person))]
+	if !ok {
+		return estimate, nil
+	}
+	entry := d.entries[i]
+
+	if fields.Has(FieldAge) && entry.age != 0 {
+		estimate.Age = &AgeGuess{Age: int(entry.age)}
+	}
+	if fields.Has(FieldGender) && entry.gender != 0 {
+		gender := GenderMale
+		if entry.gender == datasetFemale {
+			gender = GenderFemale
+		}
+		estimate.Gender = &GenderGuess{Gender: gender, Probability: widen(entry.genderProbability)}
+	}
+	if fields.Has(FieldNationality) {
+		for _, country := range d.countries[entry.first : entry.first+uint32(entry.count)] {
+			estimate.Nationalities = append(estimate.Nationalities, CountryProbability{
+				CountryID:   string(country.code[:]),
+				Probability: widen(country.probability),
+			})
+		}
+	}
+	return estimate, nil
+}
+
+// datasetBuilder collects rows before they are packed, as the countries of a
+// name may be spread over a file
+type datasetBuilder struct {
+	index     map[string]uint32
+	entries   []datasetEntry
+	countries [][]datasetCountry
+}
+
+func newDatasetBuilder() *datasetBuilder {
+	return &datasetBuilder{index: map[string]uint32{}}
+}
+
+func (b *datasetBuilder) add(row DatasetRow) error {
+	name := lookupKey(row.Name)
+	if name == "" {
+		return errors.New("name is empty")
+	}
+	if row.Probability < 0 || row.Probability > 1 {
+		return fmt.Errorf("probability %v is out of range 0-1", row.Probability)
+	}
+
+	i, ok := b.index[name]
+	if !ok {
+		i = uint32(len(b.entries))
+		b.index[name] = i
+		b.entries = append(b.entries, datasetEntry{})
+		b.countries = append(b.countries, nil)
+	}
+	entry := &b.entries[i]
+
+	switch strings.ToLower(row.Field) {
+	case "age":
+		age, err := strconv.ParseFloat(row.Value, 64)
+		if err != nil || age < 1 || age > math.MaxUint8 {
+			return fmt.Errorf("invalid age %q", row.Value)
+		}
+		entry.age = uint8(math.Round(age))
+	case "gender":
+		switch strings.ToLower(row.Value) {
+		case GenderMale:
+			entry.gender = datasetMale
+		case GenderFemale:
+			entry.gender = datasetFemale
+		default:
+			return fmt.Errorf("invalid gender %q", row.Value)
+		}
+		entry.genderProbability = float32(row.Probability)
+	case "nationality":
+		code := strings.ToUpper(strings.TrimSpace(row.Value))
+		if len(code) != 2 {
+			return fmt.Errorf("country %q is not an ISO 3166-1 alpha-2 code", row.Value)
+		}
+		country := datasetCountry{code: [2]byte{code[0], code[1]}, probability: float32(row.Probability)}
+		for j := range b.countries[i] {
+			if b.countries[i][j].code == country.code {
+				b.countries[i][j] = country
+				return nil
+			}
+		}
+		if len(b.countries[i]) == math.MaxUint16 {
+			return errors.New("too many countries")
+		}
+		b.countries[i] = append(b.countries[i], country)
+	default:
+		return fmt.Errorf("unknown field %q", row.Field)
+	}
+	return nil
+}
+
+// build packs the countries of all names into one slice
+func (b *datasetBuilder) build() *Dataset {
+	total := 0
+	for _, countries := range b.countries {
+		total += len(countries)
+	}
+
+	dataset := &Dataset{
+		index:     b.index,
+		entries:   b.entries,
+		countries: make([]datasetCountry, 0, total),
+	}
+	for i, countries := range b.countries {
+		dataset.entries[i].first = uint32(len(dataset.countries))
+		dataset.entries[i].count = uint16(len(countries))
+		dataset.countries = append(dataset.countries, countries...)
+	}
+	return dataset
+}
+
+// widen converts a stored probability back to the float64 closest to its
+// decimal form, so 0.35 does not come out as 0.3499999940395355
+func widen(f float32) float64 {
+	wide, _ := strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+	return wide
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"people-enricher/internal/entity"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const datasetCSV = `name,field,value,probability
+# comment
+Ivan,age,41.6,
+Ivan,gender,male,0.98
+Ivan,nationality,ru,0.35
+Ivan,nationality,UA,0.2
+Anna,gender,female,0.97
+`
+
+func TestReadDataset(t *testing.T) {
+	dataset, err := ReadDataset(strings.NewReader(datasetCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataset.Len() != 2 {
+		t.Errorf("Len = %d, want 2", dataset.Len())
+	}
+
+	estimate, err := dataset.Estimate(context.Background(), &entity.Person{Name: "IVAN"}, AllFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.Age == nil || estimate.Age.Age != 42 {
+		t.Errorf("age = %+v, want 42", estimate.Age)
+	}
+	if estimate.Gender == nil || estimate.Gender.Gender != GenderMale || estimate.Gender.Probability != 0.98 {
+		t.Errorf("gender = %+v, want male with 0.98", estimate.Gender)
+	}
+	if len(estimate.Nationalities) != 2 || estimate.Nationalities[0] != (CountryProbability{CountryID: "RU", Probability: 0.35}) {
+		t.Errorf("nationalities = %+v, want RU 0.35 and UA", estimate.Nationalities)
+	}
+
+	// Only the requested fields are estimated
+	estimate, _ = dataset.Estimate(context.Background(), &entity.Person{Name: "Ivan"}, FieldGender)
+	if estimate.Age != nil || estimate.Nationalities != nil {
+		t.Errorf("estimate of the gender = %+v, want only the gender", estimate)
+	}
+
+	estimate, _ = dataset.Estimate(context.Background(), &entity.Person{Name: "Pyotr"}, AllFields)
+	if estimate.Age != nil || estimate.Gender != nil || estimate.Nationalities != nil {
+		t.Errorf("estimate of an unknown name = %+v, want none", estimate)
+	}
+}
+
+func TestReadDatasetRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		row  string
+		want string
+	}{
+		{"empty name", " ,age,30,", "line 2: name is empty"},
+		{"bad probability", "Ivan,gender,male,likely", "line 2: invalid probability"},
+		{"probability out of range", "Ivan,gender,male,1.2", "line 2: probability 1.2 is out of range"},
+		{"bad age", "Ivan,age,old,", `line 2: invalid age "old"`},
+		{"age out of range", "Ivan,age,300,", `line 2: invalid age "300"`},
+		{"bad gender", "Ivan,gender,other,0.5", `line 2: invalid gender "other"`},
+		{"bad country", "Ivan,nationality,RUS,0.5", "line 2: country"},
+		{"unknown field", "Ivan,height,180,", `line 2: unknown field "height"`},
+		{"missing column", "Ivan,age,30", "wrong number of fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadDataset(strings.NewReader("Anna,age,30,\n" + tt.row + "\n"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadDataset = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDatasetFormats(t *testing.T) {
+	dir := t.TempDir()
+
+	gzipped := filepath.Join(dir, "names.csv.gz")
+	file, err := os.Create(gzipped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := gzip.NewWriter(file)
+	if _, err := writer.Write([]byte(datasetCSV)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	// The Parquet file comes later and overrides the gender of Ivan
+	columnar := filepath.Join(dir, "names.parquet")
+	err = parquet.WriteFile(columnar, []DatasetRow{
+		{Name: "Ivan", Field: "gender", Value: "female", Probability: 0.6},
+		{Name: "Maria", Field: "age", Value: "35"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataset, err := LoadDataset(gzipped, columnar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataset.Len() != 3 {
+		t.Errorf("Len = %d, want 3", dataset.Len())
+	}
+	estimate, _ := dataset.Estimate(context.Background(), &entity.Person{Name: "Ivan"}, AllFields)
+	if estimate.Gender == nil || estimate.Gender.Gender != GenderFemale || estimate.Age == nil {
+		t.Errorf("estimate = %+v, want the overridden gender and the age", estimate)
+	}
+
+	if err := parquet.WriteFile(columnar, []DatasetRow{{Name: "Ivan", Field: "age", Value: "0"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDataset(columnar); err == nil || !strings.Contains(err.Error(), `row "Ivan": invalid age`) {
+		t.Errorf("LoadDataset = %v, want an error about the age of Ivan", err)
+	}
+}
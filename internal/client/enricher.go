@@ -2,43 +2,21 @@ package client
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
 	"people-enricher/internal/config"
-	"time"
+	"people-enricher/internal/entity"
 
-	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
+// Enricher asks the configured providers about a person and combines their
+// answers. Fallback providers are only asked for fields none of the
+// providers could estimate.
 type Enricher struct {
-	httpClient   *http.Client
-	logger       *logrus.Entry
-	agifyURL     string
-	genderizeURL string
-	nationalize  string
-}
-
-type AgifyResponse struct {
-	Name  string `json:"name"`
-	Age   int    `json:"age"`
-	Count int    `json:"count"`
-}
-
-type GenderizeResponse struct {
-	Name        string  `json:"name"`
-	Gender      string  `json:"gender"`
-	Probability float64 `json:"probability"`
-	Count       int     `json:"count"`
-}
-
-type NationalizeResponse struct {
-	Name    string `json:"name"`
-	Country []struct {
-		CountryID   string  `json:"country_id"`
-		Probability float64 `json:"probability"`
-	} `json:"country"`
+	providers []Provider
+	fallback  []Provider
+	logger    *logrus.Entry
+	gender    config.GenderCfg
+	resolver  resolver
 }
 
 type EnrichmentResult struct {
@@ -46,153 +24,167 @@ type EnrichmentResult struct {
 	Gender                 *string  `json:"gender,omitempty"`
 	Nationality            *string  `json:"nationality,omitempty"`
 	NationalityProbability *float64 `json:"nationality_probability,omitempty"`
+	// Nationalities is the resolved distribution, most likely country first
+	Nationalities []CountryProbability `json:"nationalities,omitempty"`
+	// Resolutions tells how each found field was resolved, keyed by field
+	Resolutions map[string]entity.FieldResolution `json:"resolutions,omitempty"`
+	// Answers lists what every asked provider answered, in the order asked
+	Answers []entity.ProviderAnswer `json:"answers,omitempty"`
 }
 
-func NewEnricher(cfg config.ExternalAPIConfig, logger *logrus.Entry) *Enricher {
-
+func NewEnricher(providers, fallback []Provider, gender config.GenderCfg, resolution config.ResolutionCfg, logger *logrus.Entry) *Enricher {
 	return &Enricher{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		logger:       logger,
-		agifyURL:     cfg.AgifyURL,
-		genderizeURL: cfg.GenderizeURL,
-		nationalize:  cfg.NationalizeURL,
+		providers: providers,
+		fallback:  fallback,
+		logger:    logger,
+		gender:    gender,
+		resolver:  resolver{cfg: resolution},
 	}
-
 }
 
-func (e *Enricher) EnrichPerson(ctx context.Context, name string) (*EnrichmentResult, error) {
-	e.logger.WithField("name", name).Debug("Starting enrich for name data")
-
-	result := &EnrichmentResult{}
+// EnrichPerson looks up the age, gender and nationality of a person. The
+// answers of all providers are resolved field by field with the configured
+// strategies, the gender is then weighed against the patronymic and surname
+// rules.
+func (e *Enricher) EnrichPerson(ctx context.Context, person *entity.Person) (*EnrichmentResult, error) {
+	e.logger.WithFields(logrus.Fields{
+		"name":    firstName(person),
+		"surname": person.Surname,
+	}).Debug("Starting enrich for person data")
 
-	age, err := e.getAge(ctx, name)
-	if err != nil {
-		e.logger.WithError(err).Warn("Error getting age")
-	} else {
-		result.Age = &age
-		e.logger.WithField("age", age).Debug("Success got age")
-	}
-
-	gender, err := e.getGender(ctx, name)
-	if err != nil {
-		e.logger.WithError(err).Warn("Error getting gender")
-	} else {
-		result.Gender = &gender
-		e.logger.WithField("gender", gender).Debug("Success got gender")
-	}
+	result := &EnrichmentResult{Resolutions: map[string]entity.FieldResolution{}}
 
-	nationality, probability, err := e.getNationality(ctx, name)
-	if err != nil {
-		e.logger.WithError(err).Warn("Error getting nationality")
-	} else {
-		result.Nationality = &nationality
-		result.NationalityProbability = &probability
-		e.logger.WithFields(logrus.Fields{
-			"nationality": nationality,
-			"probability": probability,
-		}).Debug("Success got nationality")
+	want := AllFields
+	rules := GenderFromMorphology(person.Surname, person.Patronymic)
+	if e.gender.Strategy == "rules_only" || (e.gender.Strategy == "rules_first" && rules != nil) {
+		want &^= FieldGender
 	}
 
-	return result, nil
-}
-
-func (e *Enricher) getAge(ctx context.Context, name string) (int, error) {
-
-	url := fmt.Sprintf("%s?name=%s", e.agifyURL, name)
-
-	e.logger.WithField("url", url).Debug("request to API agify.io")
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return 0, errors.Wrap(err, "creating request to API agify.io")
+	if rules != nil {
+		result.Answers = append(result.Answers, entity.ProviderAnswer{
+			Provider:          "morphology",
+			Gender:            &rules.Gender,
+			GenderProbability: &rules.Probability,
+		})
 	}
 
-	resp, err := e.httpClient.Do(req)
-	if err != nil {
-		return 0, errors.Wrap(err, "request to API agify.io")
+	answers := e.collect(ctx, person, e.providers, want, false, result)
+	if missing := want &^ answered(answers); missing != 0 && len(e.fallback) > 0 {
+		e.logger.WithField("missing", missing).Debug("Asking fallback providers")
+		answers = append(answers, e.collect(ctx, person, e.fallback, missing, true, result)...)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("status code of API agify.io: %d", resp.StatusCode)
+	if age, resolution := e.resolver.age(answers); age != nil {
+		result.Age = age
+		result.Resolutions["age"] = *resolution
+		e.logger.WithFields(logrus.Fields{
+			"age":      *age,
+			"strategy": resolution.Strategy,
+		}).Debug("Success got age")
+	}
+
+	api, apiResolution := e.resolver.gender(answers)
+	if gender := e.resolveGender(api, rules); gender != nil {
+		result.Gender = &gender.Gender
+		if gender == rules {
+			apiResolution = resolution(StrategyRules, &rules.Probability, "morphology")
+		}
+		result.Resolutions["gender"] = *apiResolution
+		e.logger.WithFields(logrus.Fields{
+			"gender":      gender.Gender,
+			"probability": gender.Probability,
+			"strategy":    apiResolution.Strategy,
+		}).Debug("Success got gender")
 	}
 
-	var agifyResp AgifyResponse
-	if err := json.NewDecoder(resp.Body).Decode(&agifyResp); err != nil {
-		return 0, errors.Wrap(err, "decode response of API agify.io")
+	if nationalities, resolution := e.resolver.nationality(answers); len(nationalities) > 0 {
+		result.Nationalities = nationalities
+		result.Nationality = &nationalities[0].CountryID
+		result.NationalityProbability = &nationalities[0].Probability
+		result.Resolutions["nationality"] = *resolution
+		e.logger.WithFields(logrus.Fields{
+			"nationality": nationalities[0].CountryID,
+			"probability": nationalities[0].Probability,
+			"strategy":    resolution.Strategy,
+		}).Debug("Success got nationality")
 	}
 
-	return agifyResp.Age, nil
+	return result, nil
 }
 
-func (e *Enricher) getGender(ctx context.Context, name string) (string, error) {
-	url := fmt.Sprintf("%s?name=%s", e.genderizeURL, name)
-
-	e.logger.WithField("url", url).Debug("request to genderize.io")
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return "", errors.Wrap(err, "creating request to API genderize.io")
-	}
-
-	resp, err := e.httpClient.Do(req)
-	if err != nil {
-		return "", errors.Wrap(err, "request to API genderize.io")
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("status code of API genderize.io: %d", resp.StatusCode)
-	}
-
-	var genderizeResp GenderizeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&genderizeResp); err != nil {
-		return "", errors.Wrap(err, "decode response of API genderize.io")
-	}
-
-	return genderizeResp.Gender, nil
+// collect asks the providers for the wanted fields and records their answers
+// in result. Failing providers are logged and skipped.
+func (e *Enricher) collect(ctx context.Context, person *entity.Person, providers []Provider, want Field, fallback bool, result *EnrichmentResult) []answer {
+	var answers []answer
+	for _, provider := range providers {
+		fields := provider.Fields() & want
+		if fields == 0 {
+			continue
+		}
+
+		estimate, err := provider.Estimate(ctx, person, fields)
+		if err != nil {
+			e.logger.WithField("provider", provider.Name()).WithError(err).Warn("Error getting estimate")
+			result.Answers = append(result.Answers, entity.ProviderAnswer{Provider: provider.Name(), Fallback: fallback, Error: err.Error()})
+			continue
+		}
+		answers = append(answers, answer{provider: provider.Name(), estimate: estimate})
+		result.Answers = append(result.Answers, providerAnswer(provider.Name(), fallback, estimate))
+	}
+	return answers
 }
 
-func (e *Enricher) getNationality(ctx context.Context, name string) (string, float64, error) {
-	url := fmt.Sprintf("%s?name=%s", e.nationalize, name)
-
-	e.logger.WithField("url", url).Debug("request to API nationalize.io")
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return "", 0, errors.Wrap(err, "creating requset to API nationalize.io")
-	}
-
-	resp, err := e.httpClient.Do(req)
-	if err != nil {
-		return "", 0, errors.Wrap(err, "request to API nationalize.io")
+func providerAnswer(provider string, fallback bool, estimate *Estimate) entity.ProviderAnswer {
+	a := entity.ProviderAnswer{Provider: provider, Fallback: fallback, Cached: estimate.Cached, Nationalities: estimate.Nationalities}
+	if estimate.Age != nil {
+		a.Age = &estimate.Age.Age
+		a.AgeSamples = &estimate.Age.Samples
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", 0, fmt.Errorf("status code of API nationalize.io: %d", resp.StatusCode)
-	}
-
-	var nationalizeResp NationalizeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&nationalizeResp); err != nil {
-		return "", 0, errors.Wrap(err, "decode reponse of API nationalize.io")
+	if estimate.Gender != nil {
+		a.Gender = &estimate.Gender.Gender
+		a.GenderProbability = &estimate.Gender.Probability
 	}
+	return a
+}
 
-	if len(nationalizeResp.Country) == 0 {
-		return "", 0, errors.New("no data about of nationality")
-	}
-
-	countryID := nationalizeResp.Country[0].CountryID
-	probability := nationalizeResp.Country[0].Probability
-
-	return countryID, probability, nil
+// answered lists the fields at least one answer has
+func answered(answers []answer) Field {
+	var fields Field
+	for _, a := range answers {
+		if a.estimate.Age != nil {
+			fields |= FieldAge
+		}
+		if a.estimate.Gender != nil {
+			fields |= FieldGender
+		}
+		if len(a.estimate.Nationalities) > 0 {
+			fields |= FieldNationality
+		}
+	}
+	return fields
 }
 
-// func sanitizeName(name string) string {
-// 	// Удаляем пробелы и лишние слэши
-// 	name = strings.TrimSpace(name)
-// 	name = strings.Trim(name, "/")
-// 	return name
-// }
+// resolveGender combines the providers' answer with the patronymic and
+// surname rules as configured
+func (e *Enricher) resolveGender(api, rules *GenderGuess) *GenderGuess {
+	switch e.gender.Strategy {
+	case "rules_only":
+		return rules
+	case "rules_first":
+		if rules != nil {
+			return rules
+		}
+		return api
+	case "genderize_only":
+		return api
+	case "confidence":
+		if api == nil || (rules != nil && rules.Probability > api.Probability) {
+			return rules
+		}
+		return api
+	}
+	if api == nil {
+		return rules
+	}
+	return api
+}
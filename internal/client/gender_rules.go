@@ -0,0 +1,122 @@
+package client
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Genders as reported by genderize.io
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+)
+
+// Probabilities of rule based guesses. A patronymic suffix is close to
+// certain, a surname ending is weaker as foreign surnames share them.
+const (
+	patronymicProbability = 0.99
+	surnameProbability    = 0.9
+)
+
+// genderSuffix maps a name ending to the gender it marks
+type genderSuffix struct {
+	suffix string
+	gender string
+}
+
+// Endings are checked in order, so longer ones sharing a tail come first.
+// Each ending is listed in Cyrillic and in the Latin spellings produced by
+// the supported transliteration schemes.
+var patronymicSuffixes = []genderSuffix{
+	{"ovich", GenderMale}, {"evich", GenderMale}, {"ich", GenderMale},
+	{"ovič", GenderMale}, {"evič", GenderMale}, {"ič", GenderMale},
+	{"ович", GenderMale}, {"евич", GenderMale}, {"ич", GenderMale},
+	{"ogly", GenderMale}, {"oglu", GenderMale}, {"оглы", GenderMale}, {"улы", GenderMale}, {"uly", GenderMale},
+
+	{"ovna", GenderFemale}, {"evna", GenderFemale}, {"ichna", GenderFemale}, {"inichna", GenderFemale},
+	{"ična", GenderFemale}, {"inična", GenderFemale},
+	{"овна", GenderFemale}, {"евна", GenderFemale}, {"ична", GenderFemale}, {"инична", GenderFemale},
+	{"kyzy", GenderFemale}, {"gyzy", GenderFemale}, {"кызы", GenderFemale}, {"гызы", GenderFemale},
+}
+
+// Latin surname endings such as "ina" or "in" are common in Western surnames
+// too (Molina, Martin), so they only count next to a patronymic.
+var surnameSuffixes = []genderSuffix{
+	{"skaya", GenderFemale}, {"skaia", GenderFemale}, {"skaâ", GenderFemale}, {"ckaâ", GenderFemale}, {"ska", GenderFemale},
+	{"ская", GenderFemale}, {"цкая", GenderFemale},
+	{"ova", GenderFemale}, {"eva", GenderFemale}, {"ëva", GenderFemale}, {"ina", GenderFemale}, {"yna", GenderFemale},
+	{"ова", GenderFemale}, {"ева", GenderFemale}, {"ёва", GenderFemale}, {"ина", GenderFemale}, {"ына", GenderFemale},
+
+	{"skiy", GenderMale}, {"skii", GenderMale}, {"skij", GenderMale}, {"sky", GenderMale}, {"ski", GenderMale},
+	{"ckij", GenderMale},
+	{"ский", GenderMale}, {"цкий", GenderMale},
+	{"ov", GenderMale}, {"ev", GenderMale}, {"ëv", GenderMale}, {"in", GenderMale}, {"yn", GenderMale},
+	{"ов", GenderMale}, {"ев", GenderMale}, {"ёв", GenderMale}, {"ин", GenderMale}, {"ын", GenderMale},
+}
+
+// GenderGuess is a gender with the probability it is right
+type GenderGuess struct {
+	Gender      string
+	Probability float64
+}
+
+// GenderFromMorphology guesses the gender of a Slavic or Turkic
+// name from the patronymic suffix and, when there is none, from the surname
+// ending. Both Cyrillic and transliterated names are understood, a Latin
+// surname only with a patronymic marking the name as Eastern Slavic. It
+// returns nil when neither part gives a signal. No network access is needed.
+func GenderFromMorphology(surname string, patronymic *string) *GenderGuess {
+	if patronymic != nil {
+		if gender := matchSuffix(*patronymic, patronymicSuffixes); gender != "" {
+			return &GenderGuess{Gender: gender, Probability: patronymicProbability}
+		}
+	}
+	if patronymic == nil && !isCyrillic(surname) {
+		return nil
+	}
+	if gender := matchSuffix(surname, surnameSuffixes); gender != "" {
+		return &GenderGuess{Gender: gender, Probability: surnameProbability}
+	}
+	return nil
+}
+
+// matchSuffix returns the gender of the first suffix the last word of name
+// ends with. Words need a stem of at least two letters before the suffix,
+// so short names such as "Lin" or "Eva" are not taken for endings.
+func matchSuffix(name string, suffixes []genderSuffix) string {
+	words := strings.Fields(strings.ToLower(name))
+	if len(words) == 0 {
+		return ""
+	}
+	word := words[len(words)-1]
+	// Hyphenated surnames inflect the last part
+	if i := strings.LastIndex(word, "-"); i >= 0 {
+		word = word[i+1:]
+	}
+
+	for _, s := range suffixes {
+		if strings.HasSuffix(word, s.suffix) && utf8.RuneCountInString(word)-utf8.RuneCountInString(s.suffix) >= 2 {
+			return s.gender
+		}
+	}
+	// Turkic patronymics may end in a separate word: "Ali ogly"
+	if len(words) > 1 {
+		for _, s := range suffixes {
+			if words[len(words)-1] == s.suffix {
+				return s.gender
+			}
+		}
+	}
+	return ""
+}
+
+// isCyrillic tells whether the name is written in Cyrillic letters
+func isCyrillic(name string) bool {
+	for _, r := range name {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package client
+
+import "testing"
+
+func TestGenderFromMorphology(t *testing.T) {
+	tests := []struct {
+		name        string
+		surname     string
+		patronymic  string
+		gender      string
+		probability float64
+	}{
+		{"Cyrillic patronymic", "", "Иванович", GenderMale, patronymicProbability},
+		{"transliterated patronymic", "", "Ivanovna", GenderFemale, patronymicProbability},
+		{"ISO 9 patronymic", "", "Il'inična", GenderFemale, patronymicProbability},
+		{"Turkic patronymic word", "", "Ali ogly", GenderMale, patronymicProbability},
+		{"Turkic patronymic suffix", "", "Rashidkyzy", GenderFemale, patronymicProbability},
+		{"patronymic wins", "Petrova", "Ivanovich", GenderMale, patronymicProbability},
+		{"unknown patronymic", "Ivanova", "John", GenderFemale, surnameProbability},
+
+		{"Cyrillic surname", "Иванова", "", GenderFemale, surnameProbability},
+		{"capitals", "ИВАНОВ", "", GenderMale, surnameProbability},
+		{"Cyrillic adjectival surname", "Троицкая", "", GenderFemale, surnameProbability},
+		{"hyphenated surname", "Смит-Петрова", "", GenderFemale, surnameProbability},
+		{"Latin surname with patronymic", "Petrov", "Ali", GenderMale, surnameProbability},
+		{"Latin adjectival surname with patronymic", "Dostoevskiy", "Ali", GenderMale, surnameProbability},
+
+		// Latin surnames alone may be Western ones sharing the endings
+		{"Latin surname alone", "Petrova", "", "", 0},
+		{"Spanish Molina", "Molina", "", "", 0},
+		{"Spanish Medina", "Medina", "", "", 0},
+		{"Italian Messina", "Messina", "", "", 0},
+		{"English Martin", "Martin", "", "", 0},
+		{"English Franklin", "Franklin", "", "", 0},
+		{"English Austin", "Austin", "", "", 0},
+		{"Polish Kowalska", "Kowalska", "", "", 0},
+
+		// Short words are no endings
+		{"short surname", "Lin", "", "", 0},
+		{"first name like ending", "Eva", "", "", 0},
+		{"no signal", "Smith", "", "", 0},
+		{"empty", "", "", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patronymic *string
+			if tt.patronymic != "" {
+				patronymic = &tt.patronymic
+			}
+			guess := GenderFromMorphology(tt.surname, patronymic)
+			if tt.gender == "" {
+				if guess != nil {
+					t.Errorf("guess = %+v, want none", guess)
+				}
+				return
+			}
+			if guess == nil || guess.Gender != tt.gender || guess.Probability != tt.probability {
+				t.Errorf("guess = %+v, want %s with %v", guess, tt.gender, tt.probability)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"people-enricher/internal/config"
+	"people-enricher/internal/entity"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type AgifyResponse struct {
+	Name  string `json:"name"`
+	Age   *int   `json:"age"`
+	Count int    `json:"count"`
+}
+
+type GenderizeResponse struct {
+	Name        string  `json:"name"`
+	Gender      string  `json:"gender"`
+	Probability float64 `json:"probability"`
+	Count       int     `json:"count"`
+}
+
+type NationalizeResponse struct {
+	Name    string               `json:"name"`
+	Country []CountryProbability `json:"country"`
+}
+
+// HTTPProviders returns the agify.io, genderize.io and nationalize.io
+// providers sharing one HTTP client. Usage of their API keys is counted in
+// usage.
+func HTTPProviders(cfg config.ExternalAPIConfig, usage UsageStore, logger *logrus.Entry) []Provider {
+	httpClient := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+	keys := func(provider string, keys []string) *KeyPool {
+		return NewKeyPool(provider, keys, cfg.KeyDailyLimit, cfg.KeyAlertThreshold, usage, logger)
+	}
+	return []Provider{
+		&AgifyProvider{httpClient: httpClient, url: cfg.AgifyURL, keys: keys("agify", cfg.AgifyKeys), logger: logger},
+		&GenderizeProvider{httpClient: httpClient, url: cfg.GenderizeURL, keys: keys("genderize", cfg.GenderizeKeys), logger: logger},
+		&NationalizeProvider{httpClient: httpClient, url: cfg.NationalizeURL, keys: keys("nationalize", cfg.NationalizeKeys), logger: logger},
+	}
+}
+
+// AgifyProvider estimates the age by first name with agify.io
+type AgifyProvider struct {
+	httpClient *http.Client
+	url        string
+	keys       *KeyPool
+	logger     *logrus.Entry
+}
+
+func (p *AgifyProvider) Name() string  { return "agify" }
+func (p *AgifyProvider) Fields() Field { return FieldAge }
+
+func (p *AgifyProvider) coalesceKey(person *entity.Person) (string, bool) {
+	return nameKey(p.Name(), person)
+}
+
+func (p *AgifyProvider) Estimate(ctx context.Context, person *entity.Person, fields Field) (*Estimate, error) {
+	var agifyResp AgifyResponse
+	if err := getJSON(ctx, p.httpClient, p.logger, p.keys, "agify.io", p.url, firstName(person), &agifyResp); err != nil {
+		return nil, err
+	}
+	// agify.io answers with a null age for names it does not know
+	if agifyResp.Age == nil {
+		return nil, errors.New("no data about of age")
+	}
+	return &Estimate{Age: &AgeGuess{Age: *agifyResp.Age, Samples: agifyResp.Count}}, nil
+}
+
+// GenderizeProvider estimates the gender by first name with genderize.io
+type GenderizeProvider struct {
+	httpClient *http.Client
+	url        string
+	keys       *KeyPool
+	logger     *logrus.Entry
+}
+
+func (p *GenderizeProvider) Name() string  { return "genderize" }
+func (p *GenderizeProvider) Fields() Field { return FieldGender }
+
+func (p *GenderizeProvider) coalesceKey(person *entity.Person) (string, bool) {
+	return nameKey(p.Name(), person)
+}
+
+func (p *GenderizeProvider) Estimate(ctx context.Context, person *entity.Person, fields Field) (*Estimate, error) {
+	var genderizeResp GenderizeResponse
+	if err := getJSON(ctx, p.httpClient, p.logger, p.keys, "genderize.io", p.url, firstName(person), &genderizeResp); err != nil {
+		return nil, err
+	}
+
+	// genderize.io answers with a null gender for names it does not know
+	if genderizeResp.Gender == "" {
+		return nil, errors.New("no data about of gender")
+	}
+	return &Estimate{Gender: &GenderGuess{Gender: genderizeResp.Gender, Probability: genderizeResp.Probability}}, nil
+}
+
+// NationalizeProvider estimates the nationality by first name with
+// nationalize.io
+type NationalizeProvider struct {
+	httpClient *http.Client
+	url        string
+	keys       *KeyPool
+	logger     *logrus.Entry
+}
+
+func (p *NationalizeProvider) Name() string  { return "nationalize" }
+func (p *NationalizeProvider) Fields() Field { return FieldNationality }
+
+func (p *NationalizeProvider) coalesceKey(person *entity.Person) (string, bool) {
+	return nameKey(p.Name(), person)
+}
+
+func (p *NationalizeProvider) Estimate(ctx context.Context, person *entity.Person, fields Field) (*Estimate, error) {
+	var nationalizeResp NationalizeResponse
+	if err := getJSON(ctx, p.httpClient, p.logger, p.keys, "nationalize.io", p.url, firstName(person), &nationalizeResp); err != nil {
+		return nil, err
+	}
+
+	if len(nationalizeResp.Country) == 0 {
+		return nil, errors.New("no data about of nationality")
+	}
+	return &Estimate{Nationalities: nationalizeResp.Country}, nil
+}
+
+// getJSON queries api for name and decodes the JSON answer into out. With
+// keys the request carries an API key, and is retried with the next key when
+// the API refuses the current one for quota.
+func getJSON(ctx context.Context, httpClient *http.Client, logger *logrus.Entry, keys *KeyPool, api, base, name string, out any) error {
+	for {
+		var key apiKey
+		if keys != nil {
+			var err error
+			if key, err = keys.acquire(ctx); err != nil {
+				return err
+			}
+		}
+
+		resp, err := request(ctx, httpClient, logger, api, base, name, key.value)
+		if err != nil {
+			return err
+		}
+		if keys != nil && quotaRefused(resp.StatusCode) {
+			resp.Body.Close()
+			keys.refused(ctx, key)
+			continue
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("status code of API %s: %d", api, resp.StatusCode)
+		}
+		if keys != nil {
+			keys.used(ctx, key)
+			// The APIs report what is left of the key's quota
+			if resp.Header.Get("X-Rate-Limit-Remaining") == "0" {
+				keys.refused(ctx, key)
+			}
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return errors.Wrapf(err, "decode response of API %s", api)
+		}
+		return nil
+	}
+}
+
+func request(ctx context.Context, httpClient *http.Client, logger *logrus.Entry, api, base, name, key string) (*http.Response, error) {
+	endpoint, err := nameURL(base, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "building request to API %s", api)
+	}
+
+	logger.WithField("url", endpoint).Debugf("request to API %s", api)
+
+	if key != "" {
+		// Added after logging, so keys stay out of the logs
+		endpoint += "&apikey=" + url.QueryEscape(key)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating request to API %s", api)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "request to API %s", api)
+	}
+	return resp, nil
+}
+
+// quotaRefused reports whether a status means the API key can not be used
+// any more today: invalid, without an active subscription or over its limit
+func quotaRefused(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusPaymentRequired || status == http.StatusTooManyRequests
+}
+
+// nameURL adds the name to an API URL as an escaped query parameter, keeping
+// any parameters the configured URL already has
+func nameURL(base, name string) (string, error) {
+	endpoint, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	query := endpoint.Query()
+	query.Set("name", name)
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String(), nil
+}
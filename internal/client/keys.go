@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"people-enricher/internal/entity"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// UsageStore keeps the daily request counts of API keys, shared by all
+// instances of the service
+type UsageStore interface {
+	AddRequests(ctx context.Context, provider, keyID string, day time.Time, requests, dailyLimit int) (int, error)
+	MarkExhausted(ctx context.Context, provider, keyID string, day time.Time) error
+	DailyUsage(ctx context.Context, provider string, day time.Time) ([]entity.APIKeyUsage, error)
+}
+
+// ErrKeysExhausted is returned when every key of a provider is used up for
+// the day
+var ErrKeysExhausted = errors.New("all API keys are exhausted for today")
+
+type apiKey struct {
+	value string
+	// id identifies the key in logs and the usage store without revealing it
+	id string
+}
+
+// KeyPool hands out the API keys of one provider in order, moving to the next
+// key once the current one has reached the daily limit or was refused by the
+// API. Usage is counted in the store so all instances see the same numbers.
+type KeyPool struct {
+	provider   string
+	keys       []apiKey
+	dailyLimit int
+	alertAt    int
+	store      UsageStore
+	logger     *logrus.Entry
+
+	mu        sync.Mutex
+	day       time.Time
+	loaded    bool
+	exhausted map[string]bool
+	alerted   map[string]bool
+}
+
+// NewKeyPool returns the pool of a provider's keys, nil when there are none
+// and requests go out anonymously
+func NewKeyPool(provider string, keys []string, dailyLimit int, alertThreshold float64, store UsageStore, logger *logrus.Entry) *KeyPool {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	pool := &KeyPool{
+		provider:   provider,
+		dailyLimit: dailyLimit,
+		alertAt:    int(float64(dailyLimit) * alertThreshold),
+		store:      store,
+		logger:     logger.WithField("provider", provider),
+	}
+	for _, key := range keys {
+		sum := sha256.Sum256([]byte(key))
+		pool.keys = append(pool.keys, apiKey{value: key, id: hex.EncodeToString(sum[:])[:12]})
+	}
+	return pool
+}
+
+// acquire returns the first key that is not exhausted today
+func (p *KeyPool) acquire(ctx context.Context) (apiKey, error) {
+	p.mu.Lock()
+	p.rollover()
+	day, loaded := p.day, p.loaded
+	p.mu.Unlock()
+
+	if !loaded {
+		p.load(ctx, day)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rollover()
+	for _, key := range p.keys {
+		if !p.exhausted[key.id] {
+			return key, nil
+		}
+	}
+	return apiKey{}, fmt.Errorf("%s: %w", p.provider, ErrKeysExhausted)
+}
+
+// used counts a request made with key, retiring the key at the daily limit
+// and warning once it comes close
+func (p *KeyPool) used(ctx context.Context, key apiKey) {
+	day := today()
+	total, err := p.store.AddRequests(ctx, p.provider, key.id, day, 1, p.dailyLimit)
+	if err != nil {
+		p.logger.WithError(err).Warn("Failed to count API key usage")
+		return
+	}
+	if p.dailyLimit == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rollover()
+
+	logger := p.logger.WithFields(logrus.Fields{"key_id": key.id, "requests": total, "daily_limit": p.dailyLimit})
+	if total >= p.dailyLimit && !p.exhausted[key.id] {
+		p.exhausted[key.id] = true
+		logger.Warn("API key reached its daily limit, rotating to the next key")
+		p.warnIfLast()
+		return
+	}
+	if total >= p.alertAt && !p.alerted[key.id] {
+		p.alerted[key.id] = true
+		logger.Warn("API key is close to its daily limit")
+	}
+}
+
+// refused retires a key the API refused for quota reasons
+func (p *KeyPool) refused(ctx context.Context, key apiKey) {
+	if err := p.store.MarkExhausted(ctx, p.provider, key.id, today()); err != nil {
+		p.logger.WithError(err).Warn("Failed to record exhausted API key")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rollover()
+
+	if !p.exhausted[key.id] {
+		p.exhausted[key.id] = true
+		p.logger.WithField("key_id", key.id).Warn("API refused key for quota, rotating to the next key")
+		p.warnIfLast()
+	}
+}
+
+// rollover forgets the state of the previous day, p.mu must be held
+func (p *KeyPool) rollover() {
+	if day := today(); !day.Equal(p.day) {
+		p.day = day
+		p.loaded = false
+		p.exhausted = map[string]bool{}
+		p.alerted = map[string]bool{}
+	}
+}
+
+// load picks up what other instances already used on day. The store is read
+// without p.mu so other calls do not wait for the database, the usage is
+// merged once unless the day has rolled over meanwhile.
+func (p *KeyPool) load(ctx context.Context, day time.Time) {
+	usage, err := p.store.DailyUsage(ctx, p.provider, day)
+	if err != nil {
+		p.logger.WithError(err).Warn("Failed to load API key usage")
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.loaded || !day.Equal(p.day) {
+		return
+	}
+	p.loaded = true
+
+	for _, u := range usage {
+		if u.ExhaustedAt != nil || (p.dailyLimit > 0 && u.Requests >= p.dailyLimit) {
+			p.exhausted[u.KeyID] = true
+		}
+		if p.dailyLimit > 0 && u.Requests >= p.alertAt {
+			p.alerted[u.KeyID] = true
+		}
+	}
+}
+
+// warnIfLast logs when no key is left for the day, p.mu must be held
+func (p *KeyPool) warnIfLast() {
+	for _, key := range p.keys {
+		if !p.exhausted[key.id] {
+			return
+		}
+	}
+	p.logger.Error("All API keys are exhausted for today")
+}
+
+// today is the current quota day, the APIs reset at midnight UTC
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"people-enricher/internal/entity"
+
+	"github.com/sirupsen/logrus"
+)
+
+// memoryUsage is a UsageStore keeping the counts in memory. When block is
+// set, DailyUsage signals entered and waits for block to be closed.
+type memoryUsage struct {
+	mu        sync.Mutex
+	requests  map[string]int
+	exhausted map[string]bool
+
+	entered chan struct{}
+	block   chan struct{}
+}
+
+func newMemoryUsage() *memoryUsage {
+	return &memoryUsage{requests: map[string]int{}, exhausted: map[string]bool{}}
+}
+
+func (s *memoryUsage) AddRequests(ctx context.Context, provider, keyID string, day time.Time, requests, dailyLimit int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[keyID] += requests
+	return s.requests[keyID], nil
+}
+
+func (s *memoryUsage) MarkExhausted(ctx context.Context, provider, keyID string, day time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exhausted[keyID] = true
+	return nil
+}
+
+func (s *memoryUsage) DailyUsage(ctx context.Context, provider string, day time.Time) ([]entity.APIKeyUsage, error) {
+	if s.block != nil {
+		close(s.entered)
+		<-s.block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var usage []entity.APIKeyUsage
+	for id, requests := range s.requests {
+		usage = append(usage, entity.APIKeyUsage{Provider: provider, KeyID: id, Day: day, Requests: requests})
+	}
+	now := time.Now()
+	for id := range s.exhausted {
+		usage = append(usage, entity.APIKeyUsage{Provider: provider, KeyID: id, Day: day, ExhaustedAt: &now})
+	}
+	return usage, nil
+}
+
+func testKeyPool(keys []string, dailyLimit int, store UsageStore) *KeyPool {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return NewKeyPool("agify", keys, dailyLimit, 0.8, store, logrus.NewEntry(logger))
+}
+
+func TestKeyPoolRotatesOnExhaustion(t *testing.T) {
+	pool := testKeyPool([]string{"first", "second"}, 2, newMemoryUsage())
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		key, err := pool.acquire(ctx)
+		if err != nil || key.value != "first" {
+			t.Fatalf("request %d got key %q, %v, want the first key", i, key.value, err)
+		}
+		pool.used(ctx, key)
+	}
+
+	key, err := pool.acquire(ctx)
+	if err != nil || key.value != "second" {
+		t.Fatalf("after the daily limit got key %q, %v, want the second key", key.value, err)
+	}
+
+	// Keys the API refuses are retired before their limit
+	pool.refused(ctx, key)
+	if _, err := pool.acquire(ctx); !errors.Is(err, ErrKeysExhausted) {
+		t.Errorf("acquire = %v, want ErrKeysExhausted", err)
+	}
+}
+
+func TestKeyPoolLoadsSharedUsage(t *testing.T) {
+	store := newMemoryUsage()
+	other := testKeyPool([]string{"first", "second"}, 2, store)
+	ctx := context.Background()
+
+	// Another instance used up the first key
+	first, _ := other.acquire(ctx)
+	other.used(ctx, first)
+	other.used(ctx, first)
+
+	pool := testKeyPool([]string{"first", "second"}, 2, store)
+	if key, err := pool.acquire(ctx); err != nil || key.value != "second" {
+		t.Errorf("acquire = %q, %v, want the second key", key.value, err)
+	}
+}
+
+func TestKeyPoolLoadsWithoutLock(t *testing.T) {
+	store := newMemoryUsage()
+	store.entered = make(chan struct{})
+	store.block = make(chan struct{})
+	pool := testKeyPool([]string{"first", "second"}, 2, store)
+	ctx := context.Background()
+
+	acquired := make(chan apiKey)
+	go func() {
+		key, _ := pool.acquire(ctx)
+		acquired <- key
+	}()
+	<-store.entered
+
+	// Counting usage must not wait for the usage being loaded
+	done := make(chan struct{})
+	go func() {
+		pool.refused(ctx, pool.keys[0])
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("refused waited for DailyUsage")
+	}
+
+	close(store.block)
+	if key := <-acquired; key.value != "second" {
+		t.Errorf("acquire = %q, want the second key", key.value)
+	}
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"people-enricher/internal/entity"
+	"sort"
+)
+
+// Field is a set of enrichment fields
+type Field uint8
+
+const (
+	FieldAge Field = 1 << iota
+	FieldGender
+	FieldNationality
+
+	AllFields = FieldAge | FieldGender | FieldNationality
+)
+
+// Has reports whether f contains all of other
+func (f Field) Has(other Field) bool {
+	return f&other == other
+}
+
+// Provider estimates some enrichment fields of a person, from the first name,
+// the surname or both. Providers only do the work for the requested fields
+// and leave the others empty.
+type Provider interface {
+	// Name identifies the provider in logs and configuration
+	Name() string
+	// Fields lists what the provider can estimate
+	Fields() Field
+	Estimate(ctx context.Context, person *entity.Person, fields Field) (*Estimate, error)
+}
+
+// Estimate is what one provider found, any part may be empty
+type Estimate struct {
+	Age           *AgeGuess
+	Gender        *GenderGuess
+	Nationalities []CountryProbability
+	// Cached is set for estimates reused from an earlier request
+	Cached bool
+}
+
+// AgeGuess is an age with the number of people it was derived from, zero
+// when unknown
+type AgeGuess struct {
+	Age     int
+	Samples int
+}
+
+// CountryProbability is the probability a person comes from a country
+type CountryProbability = entity.CountryProbability
+
+// firstName is the first name sent to providers, the Latin transliteration
+// when the name has one
+func firstName(person *entity.Person) string {
+	if person.NameLatin != nil {
+		return *person.NameLatin
+	}
+	return person.Name
+}
+
+// weightedDistribution is a nationality distribution with the weight its
+// provider is trusted with
+type weightedDistribution struct {
+	weight        float64
+	nationalities []CountryProbability
+}
+
+// mergeNationalities averages the distributions by weight. Providers that
+// found nothing are left out rather than counted as zero everywhere. The
+// result is ordered by probability, most likely country first.
+func mergeNationalities(distributions []weightedDistribution) []CountryProbability {
+	scores := map[string]float64{}
+	total := 0.0
+	for _, d := range distributions {
+		if len(d.nationalities) == 0 || d.weight <= 0 {
+			continue
+		}
+		total += d.weight
+		for _, country := range d.nationalities {
+			scores[country.CountryID] += d.weight * country.Probability
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	merged := make([]CountryProbability, 0, len(scores))
+	for country, score := range scores {
+		merged = append(merged, CountryProbability{CountryID: country, Probability: score / total})
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Probability != merged[j].Probability {
+			return merged[i].Probability > merged[j].Probability
+		}
+		return merged[i].CountryID < merged[j].CountryID
+	})
+	return merged
+}
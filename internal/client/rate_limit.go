@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"people-enricher/internal/config"
+	"people-enricher/internal/entity"
+	"people-enricher/internal/ratelimit"
+	"time"
+)
+
+// RateLimited wraps the providers that have a configured rate, so every
+// estimate first takes a token from the provider's bucket in store
+func RateLimited(providers []Provider, cfg config.ProviderLimitCfg, store ratelimit.Store) []Provider {
+	maxWait := cfg.MaxWait
+	if cfg.Mode == "fail" {
+		maxWait = 0
+	}
+
+	limited := make([]Provider, 0, len(providers))
+	for _, provider := range providers {
+		rate, ok := cfg.Rates[provider.Name()]
+		if !ok {
+			limited = append(limited, provider)
+			continue
+		}
+		limited = append(limited, &rateLimitedProvider{
+			Provider: provider,
+			store:    store,
+			bucket:   ratelimit.Bucket{Rate: rate, Burst: cfg.Burst},
+			maxWait:  maxWait,
+		})
+	}
+	return limited
+}
+
+type rateLimitedProvider struct {
+	Provider
+	store   ratelimit.Store
+	bucket  ratelimit.Bucket
+	maxWait time.Duration
+}
+
+func (p *rateLimitedProvider) Estimate(ctx context.Context, person *entity.Person, fields Field) (*Estimate, error) {
+	if err := ratelimit.Wait(ctx, p.store, "provider:"+p.Name(), p.bucket, p.maxWait); err != nil {
+		return nil, err
+	}
+	return p.Provider.Estimate(ctx, person, fields)
+}
+
+// coalesceKey keeps the wrapped provider coalescable, so callers sharing a
+// request only take one token
+func (p *rateLimitedProvider) coalesceKey(person *entity.Person) (string, bool) {
+	if c, ok := p.Provider.(coalescer); ok {
+		return c.coalesceKey(person)
+	}
+	return "", false
+}
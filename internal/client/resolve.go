@@ -0,0 +1,258 @@
+package client
+
+import (
+	"math"
+	"people-enricher/internal/config"
+	"people-enricher/internal/entity"
+	"slices"
+)
+
+// Resolution strategies, not every field supports all of them
+const (
+	// StrategyPriority takes the answer of the highest priority provider
+	StrategyPriority = "priority"
+	// StrategyConfidence takes the answer the provider is most sure about
+	StrategyConfidence = "confidence"
+	// StrategyWeightedAverage averages ages or nationality distributions by
+	// provider weight
+	StrategyWeightedAverage = "weighted_average"
+	// StrategyMajority takes the value most providers agree on, votes count
+	// by provider weight
+	StrategyMajority = "majority"
+	// StrategyRules marks a gender decided by the patronymic and surname rules
+	StrategyRules = "rules"
+)
+
+// answer is the estimate of one provider
+type answer struct {
+	provider string
+	estimate *Estimate
+}
+
+// resolver merges the answers of providers field by field
+type resolver struct {
+	cfg config.ResolutionCfg
+}
+
+// weight is how much the answers of a provider count, 1 unless configured
+// otherwise
+func (r resolver) weight(provider string) float64 {
+	if weight, ok := r.cfg.Weights[provider]; ok {
+		return weight
+	}
+	return 1
+}
+
+// rank orders providers by the configured priority, unlisted providers
+// after the listed ones in the order they answered
+func (r resolver) rank(provider string) int {
+	if i := slices.Index(r.cfg.Priority, provider); i >= 0 {
+		return i
+	}
+	return len(r.cfg.Priority)
+}
+
+// byPriority returns the answers in priority order
+func (r resolver) byPriority(answers []answer) []answer {
+	sorted := slices.Clone(answers)
+	slices.SortStableFunc(sorted, func(a, b answer) int {
+		return r.rank(a.provider) - r.rank(b.provider)
+	})
+	return sorted
+}
+
+func (r resolver) age(answers []answer) (*int, *entity.FieldResolution) {
+	var ages []answer
+	for _, a := range r.byPriority(answers) {
+		if a.estimate.Age != nil {
+			ages = append(ages, a)
+		}
+	}
+	if len(ages) == 0 {
+		return nil, nil
+	}
+
+	switch r.cfg.Age {
+	case StrategyConfidence:
+		best := ages[0]
+		for _, a := range ages[1:] {
+			if a.estimate.Age.Samples > best.estimate.Age.Samples {
+				best = a
+			}
+		}
+		return &best.estimate.Age.Age, resolution(StrategyConfidence, nil, best.provider)
+	case StrategyWeightedAverage:
+		sum, total := 0.0, 0.0
+		var providers []string
+		for _, a := range ages {
+			weight := r.weight(a.provider)
+			if weight <= 0 {
+				continue
+			}
+			sum += weight * float64(a.estimate.Age.Age)
+			total += weight
+			providers = append(providers, a.provider)
+		}
+		if total > 0 {
+			age := int(math.Round(sum / total))
+			return &age, resolution(StrategyWeightedAverage, nil, providers...)
+		}
+	case StrategyMajority:
+		votes := map[int]float64{}
+		for _, a := range ages {
+			votes[a.estimate.Age.Age] += r.weight(a.provider)
+		}
+		// Ties go to the value of the higher priority provider
+		winner := ages[0].estimate.Age.Age
+		for _, a := range ages {
+			if votes[a.estimate.Age.Age] > votes[winner] {
+				winner = a.estimate.Age.Age
+			}
+		}
+		var providers []string
+		for _, a := range ages {
+			if a.estimate.Age.Age == winner {
+				providers = append(providers, a.provider)
+			}
+		}
+		return &winner, resolution(StrategyMajority, nil, providers...)
+	}
+	return &ages[0].estimate.Age.Age, resolution(StrategyPriority, nil, ages[0].provider)
+}
+
+func (r resolver) gender(answers []answer) (*GenderGuess, *entity.FieldResolution) {
+	var genders []answer
+	for _, a := range r.byPriority(answers) {
+		if a.estimate.Gender != nil {
+			genders = append(genders, a)
+		}
+	}
+	if len(genders) == 0 {
+		return nil, nil
+	}
+
+	switch r.cfg.Gender {
+	case StrategyConfidence:
+		best := genders[0]
+		for _, a := range genders[1:] {
+			if a.estimate.Gender.Probability > best.estimate.Gender.Probability {
+				best = a
+			}
+		}
+		return best.estimate.Gender, resolution(StrategyConfidence, &best.estimate.Gender.Probability, best.provider)
+	case StrategyMajority:
+		votes := map[string]float64{}
+		total := 0.0
+		for _, a := range genders {
+			votes[a.estimate.Gender.Gender] += r.weight(a.provider)
+			total += r.weight(a.provider)
+		}
+		winner := genders[0].estimate.Gender.Gender
+		for gender, vote := range votes {
+			if vote > votes[winner] {
+				winner = gender
+			}
+		}
+		var providers []string
+		for _, a := range genders {
+			if a.estimate.Gender.Gender == winner {
+				providers = append(providers, a.provider)
+			}
+		}
+		// The share of the votes stands in for the probability
+		share := 0.0
+		if total > 0 {
+			share = votes[winner] / total
+		}
+		return &GenderGuess{Gender: winner, Probability: share}, resolution(StrategyMajority, &share, providers...)
+	}
+	best := genders[0]
+	return best.estimate.Gender, resolution(StrategyPriority, &best.estimate.Gender.Probability, best.provider)
+}
+
+func (r resolver) nationality(answers []answer) ([]CountryProbability, *entity.FieldResolution) {
+	var nationalities []answer
+	for _, a := range r.byPriority(answers) {
+		if len(a.estimate.Nationalities) > 0 {
+			nationalities = append(nationalities, a)
+		}
+	}
+	if len(nationalities) == 0 {
+		return nil, nil
+	}
+
+	switch r.cfg.Nationality {
+	case StrategyConfidence:
+		best := nationalities[0]
+		for _, a := range nationalities[1:] {
+			if top(a.estimate.Nationalities).Probability > top(best.estimate.Nationalities).Probability {
+				best = a
+			}
+		}
+		distribution := sortCountries(best.estimate.Nationalities)
+		return distribution, resolution(StrategyConfidence, &distribution[0].Probability, best.provider)
+	case StrategyWeightedAverage:
+		distributions := make([]weightedDistribution, 0, len(nationalities))
+		var providers []string
+		for _, a := range nationalities {
+			weight := r.weight(a.provider)
+			if weight <= 0 {
+				continue
+			}
+			distributions = append(distributions, weightedDistribution{weight: weight, nationalities: a.estimate.Nationalities})
+			providers = append(providers, a.provider)
+		}
+		if merged := mergeNationalities(distributions); len(merged) > 0 {
+			return merged, resolution(StrategyWeightedAverage, &merged[0].Probability, providers...)
+		}
+	case StrategyMajority:
+		votes := map[string]float64{}
+		for _, a := range nationalities {
+			votes[top(a.estimate.Nationalities).CountryID] += r.weight(a.provider)
+		}
+		winner := top(nationalities[0].estimate.Nationalities).CountryID
+		for _, a := range nationalities {
+			if country := top(a.estimate.Nationalities).CountryID; votes[country] > votes[winner] {
+				winner = country
+			}
+		}
+		// The distributions of the agreeing providers are averaged, so the
+		// probability of the winner is theirs
+		var agreeing []weightedDistribution
+		var providers []string
+		for _, a := range nationalities {
+			if top(a.estimate.Nationalities).CountryID == winner {
+				agreeing = append(agreeing, weightedDistribution{weight: 1, nationalities: a.estimate.Nationalities})
+				providers = append(providers, a.provider)
+			}
+		}
+		merged := mergeNationalities(agreeing)
+		return merged, resolution(StrategyMajority, &merged[0].Probability, providers...)
+	}
+	distribution := sortCountries(nationalities[0].estimate.Nationalities)
+	return distribution, resolution(StrategyPriority, &distribution[0].Probability, nationalities[0].provider)
+}
+
+// top is the most likely country of a distribution
+func top(distribution []CountryProbability) CountryProbability {
+	best := distribution[0]
+	for _, country := range distribution[1:] {
+		if country.Probability > best.Probability {
+			best = country
+		}
+	}
+	return best
+}
+
+// sortCountries returns the distribution ordered most likely country first
+func sortCountries(distribution []CountryProbability) []CountryProbability {
+	return mergeNationalities([]weightedDistribution{{weight: 1, nationalities: distribution}})
+}
+
+func resolution(strategy string, confidence *float64, providers ...string) *entity.FieldResolution {
+	if confidence != nil {
+		value := *confidence
+		confidence = &value
+	}
+	return &entity.FieldResolution{Strategy: strategy, Providers: providers, Confidence: confidence}
+}
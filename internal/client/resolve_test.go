@@ -0,0 +1,130 @@
+package client
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"people-enricher/internal/config"
+)
+
+// Provider b has the highest priority, then a, c is unlisted but counts twice
+var (
+	testPriority = []string{"b", "a"}
+	testWeights  = map[string]float64{"c": 2}
+)
+
+func ageAnswer(provider string, age, samples int) answer {
+	return answer{provider: provider, estimate: &Estimate{Age: &AgeGuess{Age: age, Samples: samples}}}
+}
+
+func genderAnswer(provider, gender string, probability float64) answer {
+	return answer{provider: provider, estimate: &Estimate{Gender: &GenderGuess{Gender: gender, Probability: probability}}}
+}
+
+func nationalityAnswer(provider string, nationalities ...CountryProbability) answer {
+	return answer{provider: provider, estimate: &Estimate{Nationalities: nationalities}}
+}
+
+func TestResolveAge(t *testing.T) {
+	answers := []answer{ageAnswer("a", 30, 100), ageAnswer("b", 40, 10), ageAnswer("c", 50, 5), {provider: "d", estimate: &Estimate{}}}
+	tests := []struct {
+		strategy  string
+		weights   map[string]float64
+		answers   []answer
+		want      int
+		providers []string
+	}{
+		{StrategyPriority, testWeights, answers, 40, []string{"b"}},
+		{StrategyConfidence, testWeights, answers, 30, []string{"a"}},
+		{StrategyWeightedAverage, testWeights, answers, 43, []string{"b", "a", "c"}},
+		{StrategyWeightedAverage, map[string]float64{"c": 0}, answers, 35, []string{"b", "a"}},
+		{StrategyMajority, testWeights, answers, 50, []string{"c"}},
+		// Ties go to the higher priority provider
+		{StrategyMajority, testWeights, answers[:2], 40, []string{"b"}},
+	}
+	for _, tt := range tests {
+		r := resolver{cfg: config.ResolutionCfg{Age: tt.strategy, Priority: testPriority, Weights: tt.weights}}
+		age, resolution := r.age(tt.answers)
+		if age == nil || *age != tt.want {
+			t.Errorf("%s: age = %v, want %d", tt.strategy, age, tt.want)
+			continue
+		}
+		if resolution.Strategy != tt.strategy || !reflect.DeepEqual(resolution.Providers, tt.providers) {
+			t.Errorf("%s: resolution = %+v, want providers %v", tt.strategy, resolution, tt.providers)
+		}
+	}
+
+	if age, resolution := (resolver{}).age([]answer{{provider: "a", estimate: &Estimate{}}}); age != nil || resolution != nil {
+		t.Errorf("age without answers = %v, %+v", age, resolution)
+	}
+}
+
+func TestResolveGender(t *testing.T) {
+	answers := []answer{genderAnswer("a", GenderMale, 0.6), genderAnswer("b", GenderFemale, 0.7), genderAnswer("c", GenderMale, 0.95)}
+	tests := []struct {
+		strategy   string
+		want       string
+		confidence float64
+		providers  []string
+	}{
+		{StrategyPriority, GenderFemale, 0.7, []string{"b"}},
+		{StrategyConfidence, GenderMale, 0.95, []string{"c"}},
+		{StrategyMajority, GenderMale, 0.75, []string{"a", "c"}},
+		// Strategies the field does not support fall back to priority
+		{StrategyWeightedAverage, GenderFemale, 0.7, []string{"b"}},
+	}
+	for _, tt := range tests {
+		r := resolver{cfg: config.ResolutionCfg{Gender: tt.strategy, Priority: testPriority, Weights: testWeights}}
+		gender, resolution := r.gender(answers)
+		if gender == nil || gender.Gender != tt.want {
+			t.Errorf("%s: gender = %+v, want %s", tt.strategy, gender, tt.want)
+			continue
+		}
+		if resolution.Confidence == nil || math.Abs(*resolution.Confidence-tt.confidence) > 1e-9 {
+			t.Errorf("%s: confidence = %v, want %v", tt.strategy, resolution.Confidence, tt.confidence)
+		}
+		if !reflect.DeepEqual(resolution.Providers, tt.providers) {
+			t.Errorf("%s: providers = %v, want %v", tt.strategy, resolution.Providers, tt.providers)
+		}
+	}
+}
+
+func TestResolveNationality(t *testing.T) {
+	answers := []answer{
+		nationalityAnswer("a", CountryProbability{CountryID: "RU", Probability: 0.6}, CountryProbability{CountryID: "UA", Probability: 0.3}),
+		nationalityAnswer("b", CountryProbability{CountryID: "RU", Probability: 0.4}, CountryProbability{CountryID: "UA", Probability: 0.5}),
+		nationalityAnswer("c", CountryProbability{CountryID: "RU", Probability: 0.8}),
+	}
+	tests := []struct {
+		strategy  string
+		want      []CountryProbability
+		providers []string
+	}{
+		{StrategyPriority, []CountryProbability{{CountryID: "UA", Probability: 0.5}, {CountryID: "RU", Probability: 0.4}}, []string{"b"}},
+		{StrategyConfidence, []CountryProbability{{CountryID: "RU", Probability: 0.8}}, []string{"c"}},
+		{StrategyWeightedAverage, []CountryProbability{{CountryID: "RU", Probability: 0.65}, {CountryID: "UA", Probability: 0.2}}, []string{"b", "a", "c"}},
+		// The agreeing distributions are averaged without weights
+		{StrategyMajority, []CountryProbability{{CountryID: "RU", Probability: 0.7}, {CountryID: "UA", Probability: 0.15}}, []string{"a", "c"}},
+	}
+	for _, tt := range tests {
+		r := resolver{cfg: config.ResolutionCfg{Nationality: tt.strategy, Priority: testPriority, Weights: testWeights}}
+		nationalities, resolution := r.nationality(answers)
+		if len(nationalities) != len(tt.want) {
+			t.Errorf("%s: nationalities = %v, want %v", tt.strategy, nationalities, tt.want)
+			continue
+		}
+		for i, want := range tt.want {
+			if nationalities[i].CountryID != want.CountryID || math.Abs(nationalities[i].Probability-want.Probability) > 1e-9 {
+				t.Errorf("%s: nationalities = %v, want %v", tt.strategy, nationalities, tt.want)
+				break
+			}
+		}
+		if math.Abs(*resolution.Confidence-tt.want[0].Probability) > 1e-9 {
+			t.Errorf("%s: confidence = %v, want %v", tt.strategy, *resolution.Confidence, tt.want[0].Probability)
+		}
+		if !reflect.DeepEqual(resolution.Providers, tt.providers) {
+			t.Errorf("%s: providers = %v, want %v", tt.strategy, resolution.Providers, tt.providers)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"people-enricher/internal/entity"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SurnameDictionary estimates the nationality from the origin of the
+// surname, as listed in a local file. It needs no network access.
+type SurnameDictionary struct {
+	origins map[string][]CountryProbability
+}
+
+// LoadSurnameDictionary reads a CSV file of surname,country_id,probability
+// rows. A surname may have several rows, one per country. A header row is
+// skipped.
+func LoadSurnameDictionary(path string) (*SurnameDictionary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening surname dictionary: %w", err)
+	}
+	defer file.Close()
+
+	dictionary, err := ReadSurnameDictionary(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading surname dictionary %s: %w", path, err)
+	}
+	return dictionary, nil
+}
+
+// ReadSurnameDictionary parses a surname dictionary in the format described
+// at LoadSurnameDictionary
+func ReadSurnameDictionary(r io.Reader) (*SurnameDictionary, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	origins := map[string][]CountryProbability{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		probability, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid probability %q", line, record[2])
+		}
+		if probability < 0 || probability > 1 {
+			return nil, fmt.Errorf("line %d: probability %v is out of range 0-1", line, probability)
+		}
+
+		country := strings.ToUpper(strings.TrimSpace(record[1]))
+		if len(country) != 2 {
+			return nil, fmt.Errorf("line %d: country %q is not an ISO 3166-1 alpha-2 code", line, record[1])
+		}
+
+		key := lookupKey(record[0])
+		origins[key] = append(origins[key], CountryProbability{CountryID: country, Probability: probability})
+	}
+	return &SurnameDictionary{origins: origins}, nil
+}
+
+// Len is the number of surnames in the dictionary
+func (d *SurnameDictionary) Len() int {
+	return len(d.origins)
+}
+
+func (d *SurnameDictionary) Name() string  { return "surname_dictionary" }
+func (d *SurnameDictionary) Fields() Field { return FieldNationality }
+
+// Estimate looks the surname up as written and in its Latin transliteration
+func (d *SurnameDictionary) Estimate(ctx context.Context, person *entity.Person, fields Field) (*Estimate, error) {
+	nationalities, ok := d.origins[lookupKey(person.Surname)]
+	if !ok && person.SurnameLatin != nil {
+		nationalities, ok = d.origins[lookupKey(*person.SurnameLatin)]
+	}
+	if !ok {
+		return &Estimate{}, nil
+	}
+	return &Estimate{Nationalities: nationalities}, nil
+}
+
+// lookupKey is the form names are stored and looked up in
+func lookupKey(name string) string {
+	return strings.ToLower(norm.NFC.String(strings.TrimSpace(name)))
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"people-enricher/internal/entity"
+)
+
+func TestReadSurnameDictionary(t *testing.T) {
+	dictionary, err := ReadSurnameDictionary(strings.NewReader(`surname,country_id,probability
+# comment
+Ivanov, ru, 0.8
+Ivanov,UA,0.15
+Иванов,RU,0.9
+Müller,DE,0.7
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dictionary.Len() != 3 {
+		t.Errorf("Len = %d, want 3", dictionary.Len())
+	}
+
+	tests := []struct {
+		name   string
+		person *entity.Person
+		want   []string
+	}{
+		{"several countries", &entity.Person{Surname: "IVANOV"}, []string{"RU", "UA"}},
+		{"Cyrillic", &entity.Person{Surname: "Иванов"}, []string{"RU"}},
+		// Decomposed "ü" is found under the composed spelling
+		{"normalized", &entity.Person{Surname: "Mu\u0308ller"}, []string{"DE"}},
+		{"transliteration", &entity.Person{Surname: "Иваноф", SurnameLatin: ptr("Ivanov")}, []string{"RU", "UA"}},
+		{"unknown", &entity.Person{Surname: "Smith"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate, err := dictionary.Estimate(context.Background(), tt.person, FieldNationality)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, nationality := range estimate.Nationalities {
+				got = append(got, nationality.CountryID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("countries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSurnameDictionaryRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"bad probability", "Ivanov,RU,0.8\nPetrov,RU,high\n", "line 2: invalid probability"},
+		{"probability out of range", "Ivanov,RU,1.5\n", "line 1: probability 1.5 is out of range"},
+		{"bad country", "Ivanov,RUS,0.8\n", "line 1: country"},
+		{"missing column", "Ivanov,RU\n", "wrong number of fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSurnameDictionary(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadSurnameDictionary = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
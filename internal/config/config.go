@@ -2,30 +2,206 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"people-enricher/internal/translit"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DBConfig    DBCfg
-	Logger      LoggerCfg
-	ExternalAPI ExternalAPIConfig
+	DBConfig       DBCfg
+	Logger         LoggerCfg
+	ExternalAPI    ExternalAPIConfig
+	Duplicates     DuplicateCfg
+	Outbox         OutboxCfg
+	Webhook        WebhookCfg
+	GRPC           GRPCCfg
+	GraphQL        GraphQLCfg
+	Translit       TranslitCfg
+	Gender         GenderCfg
+	Nationality    NationalityCfg
+	Enrichment     EnrichmentCfg
+	Resolution     ResolutionCfg
+	ProviderLimits ProviderLimitCfg
+	HTTPLimits     HTTPLimitCfg
+	Auth           AuthCfg
 }
 
+// DBCfg configures the database connection. User must not be a superuser or
+// have BYPASSRLS, the row-level security policies on the tenant tables would
+// not apply. Connecting as such a role fails unless AllowRLSBypass is set,
+// which is meant for single-tenant development setups.
 type DBCfg struct {
-	Port     string
-	Host     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Port           string
+	Host           string
+	User           string
+	Password       string
+	DBName         string
+	SSLMode        string
+	AllowRLSBypass bool
 }
+
+// ExternalAPIConfig configures the enrichment APIs. Keys are sent as the
+// apikey parameter, the next key is used once one is exhausted. KeyDailyLimit
+// caps the requests per key and day, zero leaves it to the API to refuse.
+// A warning is logged when a key has used KeyAlertThreshold of its limit.
 type ExternalAPIConfig struct {
-	AgifyURL       string
-	GenderizeURL   string
-	NationalizeURL string
+	AgifyURL          string
+	GenderizeURL      string
+	NationalizeURL    string
+	AgifyKeys         []string
+	GenderizeKeys     []string
+	NationalizeKeys   []string
+	KeyDailyLimit     int
+	KeyAlertThreshold float64
+}
+
+// DuplicateCfg controls duplicate detection on create.
+// Mode is one of "reject" (409), "warn" (create and report) or "off".
+type DuplicateCfg struct {
+	Mode      string
+	Threshold float64
+}
+
+// OutboxCfg configures the relay of person events.
+// Sink is one of "stdout", "file", "nats", "kafka", empty disables the relay.
+// Events failing MaxAttempts times are marked failed and no longer relayed.
+type OutboxCfg struct {
+	Sink         string
+	FilePath     string
+	NATSURL      string
+	NATSSubject  string
+	KafkaBrokers []string
+	KafkaTopic   string
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+}
+
+// WebhookCfg configures webhook delivery retries. Webhooks may only target
+// public addresses unless AllowPrivateTargets is set, for local development.
+type WebhookCfg struct {
+	MaxAttempts         int
+	RetryBase           time.Duration
+	RetryMax            time.Duration
+	Timeout             time.Duration
+	PollInterval        time.Duration
+	BatchSize           int
+	AllowPrivateTargets bool
+}
+
+type GRPCCfg struct {
+	Addr string
+}
+
+// GraphQLCfg limits the queries accepted on /graphql, zero disables a limit
+type GraphQLCfg struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// GenderCfg selects how the genderize.io answer and the patronymic and
+// surname rules are combined. Strategy is one of "rules_first",
+// "genderize_first", "confidence", "rules_only" (no network access) or
+// "genderize_only".
+type GenderCfg struct {
+	Strategy string
+}
+
+// EnrichmentCfg selects where enrichment data comes from. Mode is "online"
+// (external APIs), "offline" (local datasets only) or "fallback" (external
+// APIs, datasets for what they could not answer). Datasets lists CSV or
+// Parquet files, empty uses the bundled dataset. The external APIs' answers
+// are reused for CacheTTL, up to CacheSize names per API, zero disables it.
+type EnrichmentCfg struct {
+	Mode      string
+	Datasets  []string
+	CacheTTL  time.Duration
+	CacheSize int
+}
+
+// NationalityCfg configures the nationality providers. SurnameDictionary is
+// the path of a surname origin CSV file, empty disables it.
+type NationalityCfg struct {
+	SurnameDictionary string
+}
+
+// ResolutionCfg sets how the answers of several providers for one field are
+// combined: "priority", "confidence", "weighted_average" (age and
+// nationality) or "majority". Priority orders providers by name, Weights set
+// how much each provider counts in averages and votes, 1 by default.
+type ResolutionCfg struct {
+	Age         string
+	Gender      string
+	Nationality string
+	Priority    []string
+	Weights     map[string]float64
+}
+
+// ProviderLimitCfg rate limits requests to the enrichment providers with a
+// token bucket each. Rates are requests per second by provider name, others
+// are not limited. Store is "memory" (per replica) or "postgres" (shared by
+// all replicas). Mode "wait" queues requests for up to MaxWait, "fail"
+// gives up right away when the bucket is empty.
+type ProviderLimitCfg struct {
+	Store   string
+	Mode    string
+	MaxWait time.Duration
+	Rates   map[string]float64
+	Burst   int
+}
+
+// HTTPLimitCfg rate limits the HTTP API per client: the API key or JWT
+// subject of authenticated requests, the IP address of the others. Reads and
+// writes have their own token bucket of Rate requests per second and Burst,
+// GET /enrich counts as a write since it asks the enrichment providers and
+// GraphQL queries as reads. Every request is first limited by its IP address
+// to IPRate and IPBurst, before credentials are checked. Store is "memory"
+// (per replica) or "postgres" (shared by all replicas). X-Forwarded-For is
+// only trusted from TrustedProxies.
+type HTTPLimitCfg struct {
+	Enabled        bool
+	Store          string
+	ReadRate       float64
+	ReadBurst      int
+	WriteRate      float64
+	WriteBurst     int
+	IPRate         float64
+	IPBurst        int
+	TrustedProxies []*net.IPNet
+}
+
+// AuthCfg configures authentication. When enabled every request but the
+// Swagger UI needs an API key or a JWT bearer token. JWTs are verified with
+// JWTSecret (HMAC), the PEM files in JWTPublicKeys or the keys published at
+// JWKSURL, their roles are read from the JWTRolesClaim claim and their tenant
+// from the JWTTenantClaim one. BootstrapKey is accepted like an admin API key
+// of no tenant, to create the first stored keys and manage tenants. Well known
+// placeholder values are refused.
+type AuthCfg struct {
+	Enabled        bool
+	BootstrapKey   string
+	JWTSecret      string
+	JWTPublicKeys  []string
+	JWKSURL        string
+	JWKSRefresh    time.Duration
+	JWTIssuer      string
+	JWTAudience    string
+	JWTRolesClaim  string
+	JWTTenantClaim string
+}
+
+// TranslitCfg selects how non-Latin names are converted to Latin before
+// enrichment
+type TranslitCfg struct {
+	Scheme translit.Scheme
 }
+
 type LoggerCfg struct {
 	Level string
 }
@@ -35,19 +211,167 @@ func LoadCfg(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed load env file: %w", err)
 	}
 
+	duplicateMode := getEnv("DUPLICATE_MODE", "warn")
+	switch duplicateMode {
+	case "reject", "warn", "off":
+	default:
+		return nil, fmt.Errorf("parse DUPLICATE_MODE: unknown mode %q", duplicateMode)
+	}
+	duplicateThreshold, err := strconv.ParseFloat(getEnv("DUPLICATE_THRESHOLD", "0.6"), 64)
+	if err != nil || duplicateThreshold < 0 || duplicateThreshold > 1 {
+		return nil, fmt.Errorf("parse DUPLICATE_THRESHOLD: must be a number in [0, 1]")
+	}
+
+	keyDailyLimit, err := strconv.Atoi(getEnv("API_KEY_DAILY_LIMIT", "0"))
+	if err != nil || keyDailyLimit < 0 {
+		return nil, fmt.Errorf("parse API_KEY_DAILY_LIMIT: must be a non-negative integer")
+	}
+	keyAlertThreshold, err := strconv.ParseFloat(getEnv("API_KEY_ALERT_THRESHOLD", "0.8"), 64)
+	if err != nil || keyAlertThreshold <= 0 || keyAlertThreshold > 1 {
+		return nil, fmt.Errorf("parse API_KEY_ALERT_THRESHOLD: must be a number in (0, 1]")
+	}
+
+	outboxInterval, err := time.ParseDuration(getEnv("OUTBOX_POLL_INTERVAL", "1s"))
+	if err != nil || outboxInterval <= 0 {
+		return nil, fmt.Errorf("parse OUTBOX_POLL_INTERVAL: must be a positive duration")
+	}
+	outboxBatch, err := strconv.Atoi(getEnv("OUTBOX_BATCH_SIZE", "100"))
+	if err != nil || outboxBatch < 1 {
+		return nil, fmt.Errorf("parse OUTBOX_BATCH_SIZE: must be a positive integer")
+	}
+	outboxAttempts, err := strconv.Atoi(getEnv("OUTBOX_MAX_ATTEMPTS", "10"))
+	if err != nil || outboxAttempts < 1 {
+		return nil, fmt.Errorf("parse OUTBOX_MAX_ATTEMPTS: must be a positive integer")
+	}
+
+	webhookCfg, err := loadWebhookCfg()
+	if err != nil {
+		return nil, err
+	}
+
+	graphqlDepth, err := strconv.Atoi(getEnv("GRAPHQL_MAX_DEPTH", "8"))
+	if err != nil || graphqlDepth < 0 {
+		return nil, fmt.Errorf("parse GRAPHQL_MAX_DEPTH: must be a non-negative integer")
+	}
+	graphqlComplexity, err := strconv.Atoi(getEnv("GRAPHQL_MAX_COMPLEXITY", "5000"))
+	if err != nil || graphqlComplexity < 0 {
+		return nil, fmt.Errorf("parse GRAPHQL_MAX_COMPLEXITY: must be a non-negative integer")
+	}
+
+	translitScheme, err := translit.ParseScheme(getEnv("TRANSLIT_SCHEME", "bgn"))
+	if err != nil {
+		return nil, fmt.Errorf("parse TRANSLIT_SCHEME: %w", err)
+	}
+
+	genderStrategy := getEnv("GENDER_STRATEGY", "rules_first")
+	switch genderStrategy {
+	case "rules_first", "genderize_first", "confidence", "rules_only", "genderize_only":
+	default:
+		return nil, fmt.Errorf("parse GENDER_STRATEGY: unknown strategy %q", genderStrategy)
+	}
+
+	enrichmentMode := getEnv("ENRICHMENT_MODE", "online")
+	switch enrichmentMode {
+	case "online", "offline", "fallback":
+	default:
+		return nil, fmt.Errorf("parse ENRICHMENT_MODE: unknown mode %q", enrichmentMode)
+	}
+	datasets := splitList(os.Getenv("ENRICHMENT_DATASETS"))
+	cacheTTL, err := time.ParseDuration(getEnv("ENRICHMENT_CACHE_TTL", "1h"))
+	if err != nil || cacheTTL < 0 {
+		return nil, fmt.Errorf("parse ENRICHMENT_CACHE_TTL: must be a non-negative duration")
+	}
+	cacheSize, err := strconv.Atoi(getEnv("ENRICHMENT_CACHE_SIZE", "10000"))
+	if err != nil || cacheSize < 1 {
+		return nil, fmt.Errorf("parse ENRICHMENT_CACHE_SIZE: must be a positive integer")
+	}
+
+	resolutionCfg, err := loadResolutionCfg()
+	if err != nil {
+		return nil, err
+	}
+
+	providerLimitCfg, err := loadProviderLimitCfg()
+	if err != nil {
+		return nil, err
+	}
+
+	httpLimitCfg, err := loadHTTPLimitCfg()
+	if err != nil {
+		return nil, err
+	}
+
+	authCfg, err := loadAuthCfg()
+	if err != nil {
+		return nil, err
+	}
+
+	allowRLSBypass, err := strconv.ParseBool(getEnv("DB_ALLOW_RLS_BYPASS", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("parse DB_ALLOW_RLS_BYPASS: %w", err)
+	}
+
 	return &Config{
 		DBConfig: DBCfg{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", ""),
-			DBName:   getEnv("DB_NAME", "peopleEnricher"),
-			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+			Host:           getEnv("DB_HOST", "localhost"),
+			Port:           getEnv("DB_PORT", "5432"),
+			User:           getEnv("DB_USER", "people_enricher"),
+			Password:       getEnv("DB_PASSWORD", ""),
+			DBName:         getEnv("DB_NAME", "peopleEnricher"),
+			SSLMode:        getEnv("DB_SSL_MODE", "disable"),
+			AllowRLSBypass: allowRLSBypass,
 		},
 		ExternalAPI: ExternalAPIConfig{
-			AgifyURL:       os.Getenv("AGIFY_API_URL"),
-			GenderizeURL:   os.Getenv("GENDERIZE_API_URL"),
-			NationalizeURL: os.Getenv("NATIONALIZE_API_URL"),
+			AgifyURL:          os.Getenv("AGIFY_API_URL"),
+			GenderizeURL:      os.Getenv("GENDERIZE_API_URL"),
+			NationalizeURL:    os.Getenv("NATIONALIZE_API_URL"),
+			AgifyKeys:         splitList(os.Getenv("AGIFY_API_KEYS")),
+			GenderizeKeys:     splitList(os.Getenv("GENDERIZE_API_KEYS")),
+			NationalizeKeys:   splitList(os.Getenv("NATIONALIZE_API_KEYS")),
+			KeyDailyLimit:     keyDailyLimit,
+			KeyAlertThreshold: keyAlertThreshold,
+		},
+		Duplicates: DuplicateCfg{
+			Mode:      duplicateMode,
+			Threshold: duplicateThreshold,
+		},
+		Outbox: OutboxCfg{
+			Sink:         os.Getenv("OUTBOX_SINK"),
+			FilePath:     getEnv("OUTBOX_FILE_PATH", "outbox.jsonl"),
+			NATSURL:      getEnv("NATS_URL", "nats://localhost:4222"),
+			NATSSubject:  getEnv("NATS_SUBJECT", "people.events"),
+			KafkaBrokers: strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ","),
+			KafkaTopic:   getEnv("KAFKA_TOPIC", "people.events"),
+			PollInterval: outboxInterval,
+			BatchSize:    outboxBatch,
+			MaxAttempts:  outboxAttempts,
+		},
+		Webhook: *webhookCfg,
+		GRPC: GRPCCfg{
+			Addr: getEnv("GRPC_ADDR", ":9090"),
+		},
+		GraphQL: GraphQLCfg{
+			MaxDepth:      graphqlDepth,
+			MaxComplexity: graphqlComplexity,
+		},
+		Gender: GenderCfg{
+			Strategy: genderStrategy,
+		},
+		Enrichment: EnrichmentCfg{
+			Mode:      enrichmentMode,
+			Datasets:  datasets,
+			CacheTTL:  cacheTTL,
+			CacheSize: cacheSize,
+		},
+		Nationality: NationalityCfg{
+			SurnameDictionary: os.Getenv("SURNAME_DICTIONARY_PATH"),
+		},
+		Resolution:     *resolutionCfg,
+		ProviderLimits: *providerLimitCfg,
+		HTTPLimits:     *httpLimitCfg,
+		Auth:           *authCfg,
+		Translit: TranslitCfg{
+			Scheme: translitScheme,
 		},
 		Logger: LoggerCfg{
 			Level: getEnv("LOG_LEVEL", "info"),
@@ -55,6 +379,197 @@ func LoadCfg(path string) (*Config, error) {
 	}, nil
 }
 
+func loadWebhookCfg() (*WebhookCfg, error) {
+	cfg := &WebhookCfg{}
+	var err error
+
+	if cfg.MaxAttempts, err = strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "8")); err != nil || cfg.MaxAttempts < 1 {
+		return nil, fmt.Errorf("parse WEBHOOK_MAX_ATTEMPTS: must be a positive integer")
+	}
+	if cfg.BatchSize, err = strconv.Atoi(getEnv("WEBHOOK_BATCH_SIZE", "50")); err != nil || cfg.BatchSize < 1 {
+		return nil, fmt.Errorf("parse WEBHOOK_BATCH_SIZE: must be a positive integer")
+	}
+	if cfg.RetryBase, err = time.ParseDuration(getEnv("WEBHOOK_RETRY_BASE", "10s")); err != nil || cfg.RetryBase <= 0 {
+		return nil, fmt.Errorf("parse WEBHOOK_RETRY_BASE: must be a positive duration")
+	}
+	if cfg.RetryMax, err = time.ParseDuration(getEnv("WEBHOOK_RETRY_MAX", "1h")); err != nil || cfg.RetryMax < cfg.RetryBase {
+		return nil, fmt.Errorf("parse WEBHOOK_RETRY_MAX: must be a duration of at least WEBHOOK_RETRY_BASE")
+	}
+	if cfg.Timeout, err = time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s")); err != nil || cfg.Timeout <= 0 {
+		return nil, fmt.Errorf("parse WEBHOOK_TIMEOUT: must be a positive duration")
+	}
+	if cfg.PollInterval, err = time.ParseDuration(getEnv("WEBHOOK_POLL_INTERVAL", "2s")); err != nil || cfg.PollInterval <= 0 {
+		return nil, fmt.Errorf("parse WEBHOOK_POLL_INTERVAL: must be a positive duration")
+	}
+	if cfg.AllowPrivateTargets, err = strconv.ParseBool(getEnv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "false")); err != nil {
+		return nil, fmt.Errorf("parse WEBHOOK_ALLOW_PRIVATE_TARGETS: %w", err)
+	}
+	return cfg, nil
+}
+
+func loadResolutionCfg() (*ResolutionCfg, error) {
+	cfg := &ResolutionCfg{
+		Age:         getEnv("RESOLVE_AGE", "weighted_average"),
+		Gender:      getEnv("RESOLVE_GENDER", "confidence"),
+		Nationality: getEnv("RESOLVE_NATIONALITY", "weighted_average"),
+	}
+
+	for _, field := range []struct {
+		env, value string
+		strategies []string
+	}{
+		{"RESOLVE_AGE", cfg.Age, []string{"priority", "confidence", "weighted_average", "majority"}},
+		{"RESOLVE_GENDER", cfg.Gender, []string{"priority", "confidence", "majority"}},
+		{"RESOLVE_NATIONALITY", cfg.Nationality, []string{"priority", "confidence", "weighted_average", "majority"}},
+	} {
+		if !slices.Contains(field.strategies, field.value) {
+			return nil, fmt.Errorf("parse %s: unknown strategy %q, expected one of %s", field.env, field.value, strings.Join(field.strategies, ", "))
+		}
+	}
+
+	cfg.Priority = splitList(os.Getenv("PROVIDER_PRIORITY"))
+
+	var err error
+	if cfg.Weights, err = parseWeights(os.Getenv("PROVIDER_WEIGHTS")); err != nil {
+		return nil, fmt.Errorf("parse PROVIDER_WEIGHTS: %w", err)
+	}
+	return cfg, nil
+}
+
+func loadProviderLimitCfg() (*ProviderLimitCfg, error) {
+	cfg := &ProviderLimitCfg{
+		Store: getEnv("PROVIDER_RATE_LIMIT_STORE", "memory"),
+		Mode:  getEnv("PROVIDER_RATE_LIMIT_MODE", "wait"),
+	}
+	if cfg.Store != "memory" && cfg.Store != "postgres" {
+		return nil, fmt.Errorf("parse PROVIDER_RATE_LIMIT_STORE: unknown store %q", cfg.Store)
+	}
+	if cfg.Mode != "wait" && cfg.Mode != "fail" {
+		return nil, fmt.Errorf("parse PROVIDER_RATE_LIMIT_MODE: unknown mode %q", cfg.Mode)
+	}
+
+	var err error
+	if cfg.MaxWait, err = time.ParseDuration(getEnv("PROVIDER_RATE_LIMIT_MAX_WAIT", "5s")); err != nil || cfg.MaxWait < 0 {
+		return nil, fmt.Errorf("parse PROVIDER_RATE_LIMIT_MAX_WAIT: must be a non-negative duration")
+	}
+	if cfg.Burst, err = strconv.Atoi(getEnv("PROVIDER_RATE_LIMIT_BURST", "5")); err != nil || cfg.Burst < 1 {
+		return nil, fmt.Errorf("parse PROVIDER_RATE_LIMIT_BURST: must be a positive integer")
+	}
+	if cfg.Rates, err = parseWeights(os.Getenv("PROVIDER_RATE_LIMITS")); err != nil {
+		return nil, fmt.Errorf("parse PROVIDER_RATE_LIMITS: %w", err)
+	}
+	for name, rate := range cfg.Rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("parse PROVIDER_RATE_LIMITS: rate of %s must be positive", name)
+		}
+	}
+	return cfg, nil
+}
+
+func loadHTTPLimitCfg() (*HTTPLimitCfg, error) {
+	cfg := &HTTPLimitCfg{
+		Store: getEnv("HTTP_RATE_LIMIT_STORE", "memory"),
+	}
+	if cfg.Store != "memory" && cfg.Store != "postgres" {
+		return nil, fmt.Errorf("parse HTTP_RATE_LIMIT_STORE: unknown store %q", cfg.Store)
+	}
+
+	var err error
+	if cfg.Enabled, err = strconv.ParseBool(getEnv("HTTP_RATE_LIMIT_ENABLED", "true")); err != nil {
+		return nil, fmt.Errorf("parse HTTP_RATE_LIMIT_ENABLED: %w", err)
+	}
+	if cfg.ReadRate, err = strconv.ParseFloat(getEnv("HTTP_RATE_LIMIT_READ_RATE", "20"), 64); err != nil || cfg.ReadRate <= 0 {
+		return nil, fmt.Errorf("parse HTTP_RATE_LIMIT_READ_RATE: must be a positive number")
+	}
+	if cfg.ReadBurst, err = strconv.Atoi(getEnv("HTTP_RATE_LIMIT_READ_BURST", "40")); err != nil || cfg.ReadBurst < 1 {
+		return nil, fmt.Errorf("parse HTTP_RATE_LIMIT_READ_BURST: must be a positive integer")
+	}
+	if cfg.WriteRate, err = strconv.ParseFloat(getEnv("HTTP_RATE_LIMIT_WRITE_RATE", "2"), 64); err != nil || cfg.WriteRate <= 0 {
+		return nil, fmt.Errorf("parse HTTP_RATE_LIMIT_WRITE_RATE: must be a positive number")
+	}
+	if cfg.WriteBurst, err = strconv.Atoi(getEnv("HTTP_RATE_LIMIT_WRITE_BURST", "10")); err != nil || cfg.WriteBurst < 1 {
+		return nil, fmt.Errorf("parse HTTP_RATE_LIMIT_WRITE_BURST: must be a positive integer")
+	}
+	if cfg.IPRate, err = strconv.ParseFloat(getEnv("HTTP_RATE_LIMIT_IP_RATE", "50"), 64); err != nil || cfg.IPRate <= 0 {
+		return nil, fmt.Errorf("parse HTTP_RATE_LIMIT_IP_RATE: must be a positive number")
+	}
+	if cfg.IPBurst, err = strconv.Atoi(getEnv("HTTP_RATE_LIMIT_IP_BURST", "100")); err != nil || cfg.IPBurst < 1 {
+		return nil, fmt.Errorf("parse HTTP_RATE_LIMIT_IP_BURST: must be a positive integer")
+	}
+	for _, cidr := range splitList(os.Getenv("HTTP_TRUSTED_PROXIES")) {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("parse HTTP_TRUSTED_PROXIES: %w", err)
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, network)
+	}
+	return cfg, nil
+}
+
+func loadAuthCfg() (*AuthCfg, error) {
+	cfg := &AuthCfg{
+		BootstrapKey:   os.Getenv("AUTH_BOOTSTRAP_KEY"),
+		JWTSecret:      os.Getenv("AUTH_JWT_SECRET"),
+		JWTPublicKeys:  splitList(os.Getenv("AUTH_JWT_PUBLIC_KEYS")),
+		JWKSURL:        os.Getenv("AUTH_JWKS_URL"),
+		JWTIssuer:      os.Getenv("AUTH_JWT_ISSUER"),
+		JWTAudience:    os.Getenv("AUTH_JWT_AUDIENCE"),
+		JWTRolesClaim:  getEnv("AUTH_JWT_ROLES_CLAIM", "roles"),
+		JWTTenantClaim: getEnv("AUTH_JWT_TENANT_CLAIM", "tenant"),
+	}
+
+	var err error
+	if cfg.Enabled, err = strconv.ParseBool(getEnv("AUTH_ENABLED", "true")); err != nil {
+		return nil, fmt.Errorf("parse AUTH_ENABLED: %w", err)
+	}
+	if cfg.JWKSRefresh, err = time.ParseDuration(getEnv("AUTH_JWKS_REFRESH", "1h")); err != nil {
+		return nil, fmt.Errorf("parse AUTH_JWKS_REFRESH: %w", err)
+	}
+	if slices.Contains(placeholderKeys, strings.ToLower(cfg.BootstrapKey)) {
+		return nil, fmt.Errorf("AUTH_BOOTSTRAP_KEY is a well known placeholder, generate a random key")
+	}
+	return cfg, nil
+}
+
+// placeholderKeys are bootstrap keys from samples and docs, anyone could use
+// them as an admin of every tenant
+var placeholderKeys = []string{
+	"dev-bootstrap-key", "bootstrap", "bootstrap-key", "changeme", "change-me",
+	"secret", "password", "admin", "test", "dev",
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseWeights parses a comma separated list of name:weight pairs
+func parseWeights(value string) (map[string]float64, error) {
+	weights := map[string]float64{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("%q is not a name:weight pair", pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q for %s", raw, name)
+		}
+		weights[strings.TrimSpace(name)] = weight
+	}
+	return weights, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
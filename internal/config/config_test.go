@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// emptyEnvFile returns the path of an env file setting nothing, so the
+// variables come from the test environment and the defaults
+func emptyEnvFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCfgDefaults(t *testing.T) {
+	cfg, err := LoadCfg(emptyEnvFile(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.DBConfig.User != "people_enricher" || cfg.DBConfig.AllowRLSBypass {
+		t.Errorf("database = %+v", cfg.DBConfig)
+	}
+	if cfg.Duplicates.Mode != "warn" || cfg.Duplicates.Threshold != 0.6 {
+		t.Errorf("duplicates = %+v", cfg.Duplicates)
+	}
+	if cfg.Outbox.PollInterval != time.Second || cfg.Outbox.BatchSize != 100 || cfg.Outbox.MaxAttempts != 10 {
+		t.Errorf("outbox = %+v", cfg.Outbox)
+	}
+	if cfg.Webhook.MaxAttempts != 8 || cfg.Webhook.RetryBase != 10*time.Second || cfg.Webhook.AllowPrivateTargets {
+		t.Errorf("webhook = %+v", cfg.Webhook)
+	}
+	if cfg.Resolution.Age != "weighted_average" || cfg.Resolution.Gender != "confidence" {
+		t.Errorf("resolution = %+v", cfg.Resolution)
+	}
+	if cfg.Enrichment.CacheTTL != time.Hour || cfg.Enrichment.CacheSize != 10000 {
+		t.Errorf("enrichment = %+v", cfg.Enrichment)
+	}
+	if !cfg.HTTPLimits.Enabled || cfg.HTTPLimits.IPRate != 50 || cfg.HTTPLimits.IPBurst != 100 {
+		t.Errorf("HTTP limits = %+v", cfg.HTTPLimits)
+	}
+	if !cfg.Auth.Enabled || cfg.Auth.BootstrapKey != "" || cfg.Auth.JWKSRefresh != time.Hour {
+		t.Errorf("auth = %+v", cfg.Auth)
+	}
+}
+
+func TestLoadCfgParses(t *testing.T) {
+	t.Setenv("PROVIDER_PRIORITY", "agify, ,genderize")
+	t.Setenv("PROVIDER_WEIGHTS", "nationalize:1, surname_dictionary:1.5")
+	t.Setenv("PROVIDER_RATE_LIMITS", "agify:2")
+	t.Setenv("HTTP_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.0.0/16")
+	t.Setenv("AUTH_BOOTSTRAP_KEY", "a-random-bootstrap-key")
+
+	cfg, err := LoadCfg(emptyEnvFile(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.Resolution.Priority, []string{"agify", "genderize"}) {
+		t.Errorf("priority = %v", cfg.Resolution.Priority)
+	}
+	if !reflect.DeepEqual(cfg.Resolution.Weights, map[string]float64{"nationalize": 1, "surname_dictionary": 1.5}) {
+		t.Errorf("weights = %v", cfg.Resolution.Weights)
+	}
+	if cfg.ProviderLimits.Rates["agify"] != 2 {
+		t.Errorf("provider rates = %v", cfg.ProviderLimits.Rates)
+	}
+	if len(cfg.HTTPLimits.TrustedProxies) != 2 || cfg.HTTPLimits.TrustedProxies[1].String() != "192.168.0.0/16" {
+		t.Errorf("trusted proxies = %v", cfg.HTTPLimits.TrustedProxies)
+	}
+	if cfg.Auth.BootstrapKey != "a-random-bootstrap-key" {
+		t.Errorf("bootstrap key = %q", cfg.Auth.BootstrapKey)
+	}
+}
+
+func TestLoadCfgRejects(t *testing.T) {
+	tests := []struct {
+		env   string
+		value string
+	}{
+		{"DB_ALLOW_RLS_BYPASS", "sometimes"},
+		{"DUPLICATE_MODE", "merge"},
+		{"DUPLICATE_THRESHOLD", "1.5"},
+		{"API_KEY_ALERT_THRESHOLD", "0"},
+		{"OUTBOX_POLL_INTERVAL", "0s"},
+		{"OUTBOX_BATCH_SIZE", "0"},
+		{"OUTBOX_MAX_ATTEMPTS", "-1"},
+		{"WEBHOOK_MAX_ATTEMPTS", "0"},
+		{"WEBHOOK_RETRY_MAX", "1s"},
+		{"WEBHOOK_ALLOW_PRIVATE_TARGETS", "sometimes"},
+		{"GRAPHQL_MAX_DEPTH", "-1"},
+		{"GRAPHQL_MAX_COMPLEXITY", "-5"},
+		{"TRANSLIT_SCHEME", "gost"},
+		{"GENDER_STRATEGY", "coin"},
+		{"ENRICHMENT_MODE", "cached"},
+		{"ENRICHMENT_CACHE_TTL", "-1s"},
+		{"ENRICHMENT_CACHE_SIZE", "0"},
+		{"RESOLVE_GENDER", "weighted_average"},
+		{"PROVIDER_WEIGHTS", "agify"},
+		{"PROVIDER_WEIGHTS", "agify:-1"},
+		{"PROVIDER_RATE_LIMITS", "agify:0"},
+		{"PROVIDER_RATE_LIMIT_MODE", "queue"},
+		{"PROVIDER_RATE_LIMIT_MAX_WAIT", "-1s"},
+		{"HTTP_RATE_LIMIT_STORE", "redis"},
+		{"HTTP_RATE_LIMIT_READ_RATE", "0"},
+		{"HTTP_RATE_LIMIT_IP_BURST", "0"},
+		{"HTTP_TRUSTED_PROXIES", "10.0.0.1"},
+		{"AUTH_ENABLED", "maybe"},
+		{"AUTH_BOOTSTRAP_KEY", "dev-bootstrap-key"},
+		{"AUTH_BOOTSTRAP_KEY", "ChangeMe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env+"="+tt.value, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			_, err := LoadCfg(emptyEnvFile(t))
+			if err == nil || !strings.Contains(err.Error(), tt.env) {
+				t.Errorf("LoadCfg = %v, want an error about %s", err, tt.env)
+			}
+		})
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{"", map[string]float64{}, false},
+		{"a:1, b : 0.5,", map[string]float64{"a": 1, "b": 0.5}, false},
+		{"a:0", map[string]float64{"a": 0}, false},
+		{"a", nil, true},
+		{"a:x", nil, true},
+		{"a:-1", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseWeights(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseWeights(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseWeights(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// APIKeyUsage counts the requests made with one upstream API key on one day.
+// Keys are identified by a hash prefix, the keys themselves are not stored.
+type APIKeyUsage struct {
+	Provider    string     `json:"provider"`
+	KeyID       string     `json:"key_id"`
+	Day         time.Time  `json:"day"`
+	Requests    int        `json:"requests"`
+	DailyLimit  int        `json:"daily_limit"`
+	ExhaustedAt *time.Time `json:"exhausted_at,omitempty"`
+}
@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrUnauthorized is returned for missing, invalid, expired or revoked
+// credentials
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Roles granted to principals, each includes the ones before it
+const (
+	RoleReader = "reader"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// Roles lists the roles from least to most privileged
+var Roles = []string{RoleReader, RoleEditor, RoleAdmin}
+
+// Authentication methods of a principal
+const (
+	AuthAPIKey    = "api_key"
+	AuthJWT       = "jwt"
+	AuthBootstrap = "bootstrap"
+	// AuthNone is the method of every caller when authentication is off
+	AuthNone = "none"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller: "api_key:<id>" for API keys, the sub
+	// claim for JWTs
+	Subject string `json:"subject"`
+	// Method is how the caller authenticated, one of the Auth* constants
+	Method string `json:"method"`
+	// Name is the API key name or the JWT name claim, for logs
+	Name string `json:"name,omitempty"`
+	// Roles granted to the caller, see Roles
+	Roles []string `json:"roles"`
+	// Tenant the caller is bound to, empty when it may act on any tenant
+	Tenant string `json:"tenant,omitempty"`
+	// Claims holds the verified claims of a JWT
+	Claims map[string]any `json:"-"`
+}
+
+type principalKey struct{}
+
+// WithPrincipal attaches the authenticated caller to ctx
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the caller attached by WithPrincipal, nil when the
+// request did not go through authentication
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// APIKey is a key clients authenticate with. Only a hash of the key is
+// stored, the key itself is returned once on create.
+// @Description API key. The key is only returned on create
+type APIKey struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// Prefix is the start of the key, to tell keys apart
+	Prefix string `json:"prefix"`
+	// Role granted to callers using the key
+	Role string `json:"role"`
+	// TenantID is the tenant callers using the key are bound to
+	TenantID   string     `json:"tenant_id"`
+	Key        string     `json:"key,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type APIKeyInput struct {
+	Name string `json:"name"`
+	// Role is one of reader, editor or admin, reader when empty
+	Role string `json:"role,omitempty"`
+}
+
+type APIKeyService interface {
+	Create(ctx context.Context, input *APIKeyInput) (*APIKey, error)
+	List(ctx context.Context) ([]*APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+}
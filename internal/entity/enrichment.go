@@ -0,0 +1,43 @@
+package entity
+
+// CountryProbability is the probability a person comes from a country
+type CountryProbability struct {
+	CountryID   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+// ProviderAnswer is what one enrichment provider answered, or why it failed
+type ProviderAnswer struct {
+	Provider string `json:"provider"`
+	// Fallback is set for providers only asked for fields the others missed
+	Fallback bool `json:"fallback,omitempty"`
+	// Cached is set when the answer was reused from an earlier request
+	Cached            bool                 `json:"cached,omitempty"`
+	Age               *int                 `json:"age,omitempty"`
+	AgeSamples        *int                 `json:"age_samples,omitempty"`
+	Gender            *string              `json:"gender,omitempty"`
+	GenderProbability *float64             `json:"gender_probability,omitempty"`
+	Nationalities     []CountryProbability `json:"nationalities,omitempty"`
+	Error             string               `json:"error,omitempty"`
+}
+
+// EnrichmentPreview is what enrichment finds for a name without storing
+// anything, with the answers of every provider that was asked
+// @Description Dry-run enrichment result
+type EnrichmentPreview struct {
+	Name                   string  `json:"name"`
+	Surname                string  `json:"surname"`
+	Patronymic             *string `json:"patronymic,omitempty"`
+	NameLatin              *string `json:"name_latin,omitempty"`
+	SurnameLatin           *string `json:"surname_latin,omitempty"`
+	PatronymicLatin        *string `json:"patronymic_latin,omitempty"`
+	Age                    *int    `json:"age,omitempty"`
+	Gender                 *string `json:"gender,omitempty"`
+	Nationality            *string `json:"nationality,omitempty"`
+	NationalityProbability *string `json:"nationality_probability,omitempty"`
+
+	// Nationalities is the resolved distribution, most likely country first
+	Nationalities []CountryProbability       `json:"nationalities,omitempty"`
+	Resolutions   map[string]FieldResolution `json:"resolutions,omitempty"`
+	Answers       []ProviderAnswer           `json:"answers"`
+}
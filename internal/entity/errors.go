@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error kinds shared by all layers. Concrete errors match one of them with
+// errors.Is, so transports map them to status codes without knowing the
+// concrete type.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	ErrForbidden  = errors.New("forbidden")
+	ErrQuota      = errors.New("quota exceeded")
+)
+
+// QuotaExceededError reports a tenant used up its daily enrichments, it
+// matches ErrQuota
+type QuotaExceededError struct {
+	Tenant string
+	Limit  int
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("tenant %s used its %d enrichments of the day", e.Tenant, e.Limit)
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuota
+}
+
+// ForbiddenError reports an action the caller's roles do not allow, it
+// matches ErrForbidden
+type ForbiddenError struct {
+	Action string
+	// Role is the least role allowed to perform the action
+	Role string
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("%s requires the %s role", e.Action, e.Role)
+}
+
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// NotFoundError reports a missing resource, it matches ErrNotFound
+type NotFoundError struct {
+	Resource string
+	ID       int64
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %d not found", e.Resource, e.ID)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// FieldError describes why a single input field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects invalid input fields, it matches ErrValidation
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// NewValidationError returns a ValidationError for a single field
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// Add records another invalid field
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err returns e when it holds any field, nil otherwise
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		parts[i] = field.Field + ": " + field.Message
+	}
+	return "invalid input: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
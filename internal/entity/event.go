@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// Person event types written to the outbox
+const (
+	EventPersonCreated  = "person.created"
+	EventPersonUpdated  = "person.updated"
+	EventPersonDeleted  = "person.deleted"
+	EventPersonEnriched = "person.enriched"
+)
+
+// Event is a change of a person published to downstream systems.
+// Person holds the state right after the change, or the last known state for
+// person.deleted. Seq orders events by commit, it is assigned shortly after
+// the event is stored and identifies the events of the SSE stream.
+type Event struct {
+	ID         int64     `json:"id"`
+	Seq        *int64    `json:"seq,omitempty"`
+	TenantID   string    `json:"tenant_id"`
+	Type       string    `json:"type"`
+	PersonID   int64     `json:"person_id"`
+	Person     *Person   `json:"person,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventSource streams person events to live subscribers
+type EventSource interface {
+	// Subscribe returns a channel of the events following the sequence
+	// number it returns, and a function releasing it. The channel is closed
+	// when the subscriber falls too far behind or the source stops.
+	Subscribe() (<-chan Event, int64, func())
+	// Since returns up to limit stored events of the tenant of ctx with a
+	// sequence number greater than afterSeq
+	Since(ctx context.Context, afterSeq int64, limit int) ([]Event, error)
+}
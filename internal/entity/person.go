@@ -2,6 +2,7 @@ package entity
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -9,29 +10,107 @@ import (
 // @Description Information about a person
 type Person struct {
 	ID                     int64     `json:"id"`
+	TenantID               string    `json:"tenant_id"`
 	Name                   string    `json:"name"`
 	Surname                string    `json:"surname"`
 	Patronymic             *string   `json:"patronymic,omitempty"`
+	NameLatin              *string   `json:"name_latin,omitempty"`
+	SurnameLatin           *string   `json:"surname_latin,omitempty"`
+	PatronymicLatin        *string   `json:"patronymic_latin,omitempty"`
 	Age                    *int      `json:"age,omitempty"`
 	Gender                 *string   `json:"gender,omitempty"`
 	Nationality            *string   `json:"nationality,omitempty"`
 	NationalityProbability *string   `json:"nationality_probability,omitempty"`
 	CreatedAt              time.Time `json:"created_at"`
 	UpdatedAt              time.Time `json:"updated_at"`
+
+	// Resolutions records how each enriched field was decided, keyed by field
+	Resolutions map[string]FieldResolution `json:"resolutions,omitempty"`
+
+	// PossibleDuplicates is filled by Create in warn mode and never stored
+	PossibleDuplicates []DuplicateCandidate `json:"possible_duplicates,omitempty"`
+
+	// EnrichmentSkipped tells why Create or Update stored the person without
+	// enriching it, "quota_exceeded" or "failed". It is never stored.
+	EnrichmentSkipped string `json:"enrichment_skipped,omitempty"`
+}
+
+// Reasons for Person.EnrichmentSkipped
+const (
+	EnrichmentSkippedQuota  = "quota_exceeded"
+	EnrichmentSkippedFailed = "failed"
+)
+
+// FieldResolution describes how the value of an enriched field was chosen
+// among the providers' answers
+type FieldResolution struct {
+	// Strategy is the resolution strategy that produced the value
+	Strategy string `json:"strategy"`
+	// Providers lists the providers whose answers made up the value
+	Providers []string `json:"providers"`
+	// Confidence is the probability or sample based confidence, when known
+	Confidence *float64 `json:"confidence,omitempty"`
 }
 
+// PersonFilter describes the conditions applied by List.
+// Slice fields match any of the given values, NullFields and NotNullFields
+// restrict the listed columns to be (not) NULL.
 type PersonFilter struct {
-	Name        *string `json:"name,omitempty"`
-	Surname     *string `json:"surname,omitempty"`
-	Patronymic  *string `json:"patronymic,omitempty"`
-	Gender      *string `json:"gender,omitempty"`
-	AgeFrom     *int    `json:"age_from,omitempty"`
-	AgeTo       *int    `json:"age_to,omitempty"`
-	Nationality *string `json:"nationality,omitempty"`
-	Page        int     `json:"page"`
-	PageSize    int     `json:"page_size"`
+	Name                       *string    `json:"name,omitempty"`
+	Surname                    *string    `json:"surname,omitempty"`
+	Patronymic                 *string    `json:"patronymic,omitempty"`
+	Gender                     []string   `json:"gender,omitempty"`
+	AgeFrom                    *int       `json:"age_from,omitempty"`
+	AgeTo                      *int       `json:"age_to,omitempty"`
+	Nationality                []string   `json:"nationality,omitempty"`
+	NationalityProbabilityFrom *float64   `json:"nationality_probability_from,omitempty"`
+	NationalityProbabilityTo   *float64   `json:"nationality_probability_to,omitempty"`
+	CreatedAfter               *time.Time `json:"created_after,omitempty"`
+	CreatedBefore              *time.Time `json:"created_before,omitempty"`
+	UpdatedAfter               *time.Time `json:"updated_after,omitempty"`
+	UpdatedBefore              *time.Time `json:"updated_before,omitempty"`
+	NullFields                 []string   `json:"null_fields,omitempty"`
+	NotNullFields              []string   `json:"not_null_fields,omitempty"`
+	SortBy                     string     `json:"sort_by,omitempty"`
+	SortDesc                   bool       `json:"sort_desc,omitempty"`
+	Page                       int        `json:"page"`
+	PageSize                   int        `json:"page_size"`
+}
+
+// SortableFields lists the person fields List can be ordered by
+var SortableFields = []string{"id", "name", "surname", "age", "nationality_probability", "created_at", "updated_at"}
+
+// NullableFields lists the person fields that may be filtered by NULL checks
+var NullableFields = []string{"patronymic", "age", "gender", "nationality", "nationality_probability"}
+
+// ValueCount is the number of people sharing one value of a field.
+// Value is nil for people where the field is not populated.
+type ValueCount struct {
+	Value *string `json:"value"`
+	Count int     `json:"count"`
+}
+
+// AgeBucketCount is the number of people whose age is in [From, To).
+// Open ends are nil, the bucket with both ends nil holds unknown ages.
+type AgeBucketCount struct {
+	Label string `json:"label"`
+	From  *int   `json:"from,omitempty"`
+	To    *int   `json:"to,omitempty"`
+	Count int    `json:"count"`
+}
+
+// PersonStats aggregates people matching a filter
+type PersonStats struct {
+	Total         int                `json:"total"`
+	ByGender      []ValueCount       `json:"by_gender"`
+	ByNationality []ValueCount       `json:"by_nationality"`
+	ByAgeBucket   []AgeBucketCount   `json:"by_age_bucket"`
+	Coverage      map[string]float64 `json:"coverage"`
 }
 
+// DefaultAgeBuckets are the age bucket boundaries used when none are given
+var DefaultAgeBuckets = []int{18, 25, 35, 45, 55, 65}
+
 type PersonInput struct {
 	Name       string  `json:"name"`
 	Surname    string  `json:"surname"`
@@ -44,4 +123,65 @@ type PersonService interface {
 	Delete(ctx context.Context, id int64) error
 	GetById(ctx context.Context, id int64) (*Person, error)
 	List(ctx context.Context, filter *PersonFilter) ([]*Person, int, error)
+	Merge(ctx context.Context, sourceID, targetID int64) (*Person, error)
+	Enrich(ctx context.Context, id int64) (*Person, error)
+	PreviewEnrichment(ctx context.Context, person *Person) (*EnrichmentPreview, error)
+	History(ctx context.Context, id int64, limit int) ([]Event, error)
+	Stats(ctx context.Context, filter *PersonFilter, ageBuckets []int) (*PersonStats, error)
+}
+
+// DuplicateCandidate is an existing person that looks like the one being created
+type DuplicateCandidate struct {
+	ID         int64   `json:"id"`
+	Name       string  `json:"name"`
+	Surname    string  `json:"surname"`
+	Patronymic *string `json:"patronymic,omitempty"`
+	Similarity float64 `json:"similarity"`
+	Exact      bool    `json:"exact"`
+}
+
+// DuplicateError is returned by Create when duplicates are rejected
+type DuplicateError struct {
+	Candidates []DuplicateCandidate
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("person has %d possible duplicates", len(e.Candidates))
+}
+
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// MergedError is returned for IDs that were merged into another person
+type MergedError struct {
+	ID       int64
+	TargetID int64
+}
+
+func (e *MergedError) Error() string {
+	return fmt.Sprintf("person %d was merged into %d", e.ID, e.TargetID)
+}
+
+func (e *MergedError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// MergeInput identifies the person merged away and the person that is kept
+type MergeInput struct {
+	SourceID int64 `json:"source_id"`
+	TargetID int64 `json:"target_id"`
+}
+
+type allowDuplicatesKey struct{}
+
+// WithDuplicatesAllowed marks ctx so that Create skips duplicate rejection
+func WithDuplicatesAllowed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowDuplicatesKey{}, true)
+}
+
+// DuplicatesAllowed reports whether ctx was marked by WithDuplicatesAllowed
+func DuplicatesAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(allowDuplicatesKey{}).(bool)
+	return allowed
 }
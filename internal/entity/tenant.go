@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultTenant owns the people stored before tenants were introduced and
+// the requests that do not name a tenant
+const DefaultTenant = "default"
+
+// Tenant is a business unit whose people are isolated from the others
+// @Description Tenant with its enrichment settings
+type Tenant struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Enrichment configures how people of the tenant are enriched
+	Enrichment TenantEnrichment `json:"enrichment"`
+	// DailyEnrichmentQuota caps the enrichments per day, zero is unlimited
+	DailyEnrichmentQuota int       `json:"daily_enrichment_quota"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// TenantEnrichment is the enrichment configuration of a tenant
+type TenantEnrichment struct {
+	// Disabled stores people without looking them up
+	Disabled bool `json:"disabled"`
+	// Fields limits enrichment to some of age, gender and nationality, all
+	// of them when empty
+	Fields []string `json:"fields,omitempty"`
+}
+
+type TenantInput struct {
+	Name                 string           `json:"name"`
+	Enrichment           TenantEnrichment `json:"enrichment"`
+	DailyEnrichmentQuota int              `json:"daily_enrichment_quota"`
+}
+
+// EnrichedFields lists the fields a tenant may restrict enrichment to
+var EnrichedFields = []string{"age", "gender", "nationality"}
+
+type tenantKey struct{}
+
+// WithTenant attaches the tenant a request acts on to ctx
+func WithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom returns the tenant attached by WithTenant, nil for contexts of
+// background work
+func TenantFrom(ctx context.Context) *Tenant {
+	tenant, _ := ctx.Value(tenantKey{}).(*Tenant)
+	return tenant
+}
+
+// TenantID returns the ID of the tenant of ctx, DefaultTenant when there is
+// none
+func TenantID(ctx context.Context) string {
+	if tenant := TenantFrom(ctx); tenant != nil {
+		return tenant.ID
+	}
+	return DefaultTenant
+}
+
+type allTenantsKey struct{}
+
+// WithAllTenants marks ctx as background work on the rows of every tenant,
+// like relaying events. Row-level security lets its queries see them all.
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+// AllTenants reports whether ctx was marked by WithAllTenants
+func AllTenants(ctx context.Context) bool {
+	all, _ := ctx.Value(allTenantsKey{}).(bool)
+	return all
+}
+
+type TenantService interface {
+	Get(ctx context.Context, id string) (*Tenant, error)
+	List(ctx context.Context) ([]*Tenant, error)
+	Put(ctx context.Context, id string, input *TenantInput) (*Tenant, error)
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+	DeliveryDead      = "dead"
+)
+
+// WebhookEvents lists the event types a webhook may subscribe to
+var WebhookEvents = []string{EventPersonCreated, EventPersonEnriched, EventPersonDeleted}
+
+// Webhook is a partner subscription to person events
+// @Description Webhook subscription. The secret is only returned on create
+type Webhook struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type WebhookInput struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+	// Secret used to sign payloads, generated when empty
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookDelivery is one attempt series of sending an event to a webhook
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	WebhookID      int64      `json:"webhook_id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastError      *string    `json:"last_error,omitempty"`
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// DueDelivery is a delivery claimed for sending together with its target
+type DueDelivery struct {
+	ID        int64
+	WebhookID int64
+	EventID   int64
+	EventType string
+	Attempts  int
+	Payload   []byte
+	URL       string
+	Secret    string
+}
+
+type DeliveryFilter struct {
+	Status   *string `json:"status,omitempty"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
+}
+
+type WebhookService interface {
+	Create(ctx context.Context, input *WebhookInput) (*Webhook, error)
+	Update(ctx context.Context, id int64, input *WebhookInput) (*Webhook, error)
+	Delete(ctx context.Context, id int64) error
+	GetByID(ctx context.Context, id int64) (*Webhook, error)
+	List(ctx context.Context) ([]*Webhook, error)
+	ListDeliveries(ctx context.Context, webhookID int64, filter *DeliveryFilter) ([]*WebhookDelivery, int, error)
+}
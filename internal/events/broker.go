@@ -0,0 +1,194 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"people-enricher/internal/entity"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Channel is the Postgres NOTIFY channel announcing new outbox events
+const Channel = "person_events"
+
+const (
+	subscriberBuffer = 64
+	catchUpPageSize  = 500
+)
+
+// Store numbers and loads outbox events
+type Store interface {
+	SequenceEvents(ctx context.Context) (int64, error)
+	LastSeq(ctx context.Context) (int64, error)
+	EventsAfter(ctx context.Context, afterSeq int64, limit int) ([]entity.Event, error)
+	AllEventsAfter(ctx context.Context, afterSeq int64, limit int) ([]entity.Event, error)
+}
+
+// Broker listens for outbox inserts with Postgres LISTEN/NOTIFY and fans
+// the events out to in-process subscribers. Every API replica runs its own
+// broker, so all of them see every change regardless of where it was made.
+// Notifications only wake the broker up: it numbers the committed events and
+// broadcasts everything after the last sequence number it has seen, so
+// events committing out of ID order are not lost.
+type Broker struct {
+	pool  *pgxpool.Pool
+	store Store
+	log   *logrus.Entry
+
+	mu          sync.Mutex
+	lastSeq     int64
+	started     bool
+	subscribers map[chan entity.Event]struct{}
+}
+
+func NewBroker(pool *pgxpool.Pool, store Store, log *logrus.Entry) *Broker {
+	return &Broker{
+		pool:        pool,
+		store:       store,
+		log:         log.WithField("component", "event_broker"),
+		subscribers: map[chan entity.Event]struct{}{},
+	}
+}
+
+// Run listens for notifications until ctx is cancelled, reconnecting on
+// errors. Subscribers are closed once it returns, ending their streams.
+func (b *Broker) Run(ctx context.Context) {
+	b.log.Info("Starting event broker")
+	defer b.closeSubscribers()
+
+	backoff := time.Second
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			b.log.Info("Event broker stopped")
+			return
+		}
+		b.log.WithError(err).WithField("retry_in", backoff).Warn("Event listener disconnected")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+func (b *Broker) listen(ctx context.Context) error {
+	pooled, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring listener connection: %w", err)
+	}
+	// The connection stays in LISTEN state, so it is taken out of the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return fmt.Errorf("listening on %s: %w", Channel, err)
+	}
+	b.log.WithField("channel", Channel).Debug("Listening for person events")
+
+	// Events committed while not listening are caught up with right away
+	if err := b.catchUp(ctx); err != nil {
+		return err
+	}
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return fmt.Errorf("waiting for notification: %w", err)
+		}
+		if err := b.catchUp(ctx); err != nil {
+			b.log.WithError(err).Error("Failed to load new events")
+		}
+	}
+}
+
+// catchUp numbers the committed events and broadcasts the ones after the last
+// broadcast sequence number. The first call only records where to start.
+func (b *Broker) catchUp(ctx context.Context) error {
+	if !b.started {
+		seq, err := b.store.LastSeq(ctx)
+		if err != nil {
+			return err
+		}
+		b.mu.Lock()
+		b.lastSeq, b.started = seq, true
+		b.mu.Unlock()
+	}
+
+	if _, err := b.store.SequenceEvents(ctx); err != nil {
+		return err
+	}
+
+	for {
+		b.mu.Lock()
+		after := b.lastSeq
+		b.mu.Unlock()
+
+		events, err := b.store.AllEventsAfter(ctx, after, catchUpPageSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			b.broadcast(event)
+		}
+		if len(events) < catchUpPageSize {
+			return nil
+		}
+	}
+}
+
+func (b *Broker) broadcast(event entity.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastSeq = *event.Seq
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// A stalled subscriber is closed, its client reconnects and
+			// resumes with Last-Event-ID
+			delete(b.subscribers, ch)
+			close(ch)
+			b.log.Warn("Closed slow event subscriber")
+		}
+	}
+}
+
+func (b *Broker) closeSubscribers() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Subscribe returns a channel receiving the events broadcast after the
+// returned sequence number
+func (b *Broker) Subscribe() (<-chan entity.Event, int64, func()) {
+	ch := make(chan entity.Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	seq := b.lastSeq
+	b.mu.Unlock()
+
+	return ch, seq, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (b *Broker) Since(ctx context.Context, afterSeq int64, limit int) ([]entity.Event, error) {
+	return b.store.EventsAfter(ctx, afterSeq, limit)
+}
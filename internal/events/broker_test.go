@@ -0,0 +1,138 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"people-enricher/internal/entity"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeStore numbers events on SequenceEvents, like the outbox does under its
+// lock: committed is what is visible, pending what commits later
+type fakeStore struct {
+	committed []entity.Event
+	seq       int64
+}
+
+func (s *fakeStore) commit(ids ...int64) {
+	for _, id := range ids {
+		s.committed = append(s.committed, entity.Event{ID: id, TenantID: entity.DefaultTenant})
+	}
+}
+
+func (s *fakeStore) SequenceEvents(context.Context) (int64, error) {
+	var sequenced int64
+	for i := range s.committed {
+		if s.committed[i].Seq == nil {
+			s.seq++
+			seq := s.seq
+			s.committed[i].Seq = &seq
+			sequenced++
+		}
+	}
+	return sequenced, nil
+}
+
+func (s *fakeStore) LastSeq(context.Context) (int64, error) {
+	return s.seq, nil
+}
+
+func (s *fakeStore) EventsAfter(ctx context.Context, afterSeq int64, limit int) ([]entity.Event, error) {
+	return s.AllEventsAfter(ctx, afterSeq, limit)
+}
+
+func (s *fakeStore) AllEventsAfter(_ context.Context, afterSeq int64, limit int) ([]entity.Event, error) {
+	events := []entity.Event{}
+	for _, event := range s.committed {
+		if event.Seq != nil && *event.Seq > afterSeq && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func newTestBroker(store Store) *Broker {
+	return NewBroker(nil, store, logrus.NewEntry(logrus.New()))
+}
+
+func receive(t *testing.T, ch <-chan entity.Event) []int64 {
+	t.Helper()
+	var ids []int64
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, event.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestBrokerDeliversEventsCommittedOutOfIDOrder(t *testing.T) {
+	store := &fakeStore{}
+	store.commit(1)
+	broker := newTestBroker(store)
+	ctx := context.Background()
+
+	if err := broker.catchUp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	live, head, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+	if head != 1 {
+		t.Fatalf("head = %d, want 1", head)
+	}
+
+	// Event 3 commits before event 2, whose transaction started earlier
+	store.commit(3)
+	if err := broker.catchUp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	store.commit(2)
+	if err := broker.catchUp(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got := receive(t, live)
+	if len(got) != 2 || got[0] != 3 || got[1] != 2 {
+		t.Fatalf("received events %v, want [3 2]", got)
+	}
+
+	_, head, release := broker.Subscribe()
+	defer release()
+	if head != 3 {
+		t.Fatalf("head = %d, want 3", head)
+	}
+}
+
+func TestBrokerClosesSlowSubscribers(t *testing.T) {
+	store := &fakeStore{}
+	broker := newTestBroker(store)
+	ctx := context.Background()
+	if err := broker.catchUp(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	slow, _, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+
+	for id := int64(1); id <= subscriberBuffer+1; id++ {
+		store.commit(id)
+	}
+	if err := broker.catchUp(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got := receive(t, slow)
+	if len(got) != subscriberBuffer {
+		t.Fatalf("received %d events, want %d", len(got), subscriberBuffer)
+	}
+	if _, ok := <-slow; ok {
+		t.Fatal("slow subscriber was not closed")
+	}
+}
@@ -0,0 +1,95 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"people-enricher/internal/entity"
+)
+
+// Error codes reported in the "code" extension of GraphQL errors
+const (
+	codeBadInput      = "BAD_USER_INPUT"
+	codeNotFound      = "NOT_FOUND"
+	codeConflict      = "CONFLICT"
+	codeDuplicate     = "DUPLICATE_PERSON"
+	codeMerged        = "PERSON_MERGED"
+	codeForbidden     = "FORBIDDEN"
+	codeQuota         = "QUOTA_EXCEEDED"
+	codeTooComplex    = "QUERY_TOO_COMPLEX"
+	codeCanceled      = "CANCELED"
+	codeInternalError = "INTERNAL_SERVER_ERROR"
+)
+
+// Error is a GraphQL error carrying a machine readable code and extra details
+// in its extensions
+type Error struct {
+	Message string
+	Code    string
+	Details map[string]interface{}
+}
+
+func newError(code, message string) *Error {
+	return &Error{Message: message, Code: code}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions implements gqlerrors.ExtendedError
+func (e *Error) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.Code}
+	for key, value := range e.Details {
+		extensions[key] = value
+	}
+	return extensions
+}
+
+// toError maps service errors the same way the HTTP handlers do: invalid
+// input lists the rejected fields, duplicates list their candidates, merged
+// IDs point to the kept person and unknown errors are logged and reported
+// without internals.
+func (r *resolver) toError(err error) error {
+	var duplicateErr *entity.DuplicateError
+	var mergedErr *entity.MergedError
+	var validationErr *entity.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		return &Error{
+			Message: validationErr.Error(),
+			Code:    codeBadInput,
+			Details: map[string]interface{}{"fields": validationErr.Fields},
+		}
+	case errors.Is(err, entity.ErrValidation):
+		return newError(codeBadInput, err.Error())
+	case errors.As(err, &duplicateErr):
+		ids := make([]int64, len(duplicateErr.Candidates))
+		for i, candidate := range duplicateErr.Candidates {
+			ids[i] = candidate.ID
+		}
+		return &Error{
+			Message: "person with the same or a similar name already exists",
+			Code:    codeDuplicate,
+			Details: map[string]interface{}{"candidates": ids},
+		}
+	case errors.As(err, &mergedErr):
+		return &Error{
+			Message: mergedErr.Error(),
+			Code:    codeMerged,
+			Details: map[string]interface{}{"targetId": mergedErr.TargetID},
+		}
+	case errors.Is(err, entity.ErrNotFound):
+		return newError(codeNotFound, err.Error())
+	case errors.Is(err, entity.ErrConflict):
+		return newError(codeConflict, err.Error())
+	case errors.Is(err, entity.ErrForbidden):
+		return newError(codeForbidden, err.Error())
+	case errors.Is(err, entity.ErrQuota):
+		return newError(codeQuota, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return newError(codeCanceled, err.Error())
+	}
+	r.log.WithError(err).Error("Error resolving GraphQL field")
+	return newError(codeInternalError, "internal error")
+}
@@ -0,0 +1,188 @@
+package gql
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/sirupsen/logrus"
+)
+
+// maxBodySize caps the size of a POSTed GraphQL request
+const maxBodySize = 1 << 20
+
+// Request is a GraphQL request as sent in a POST body
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Handler serves GraphQL queries over HTTP
+type Handler struct {
+	schema graphql.Schema
+	limits Limits
+	log    *logrus.Entry
+}
+
+// NewHandler creates a new Handler
+func NewHandler(schema graphql.Schema, limits Limits, log *logrus.Entry) *Handler {
+	return &Handler{
+		schema: schema,
+		limits: limits,
+		log:    log,
+	}
+}
+
+// ServeHTTP godoc
+// @Summary GraphQL endpoint
+// @Description Runs a GraphQL query or mutation against persons. GET accepts query, operationName and variables (JSON) parameters and only runs queries. Requests that are nested too deeply or would resolve too many fields are rejected with QUERY_TOO_COMPLEX
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body Request false "GraphQL request"
+// @Param query query string false "GraphQL query, for GET requests"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 405 {object} map[string]interface{}
+// @Router /graphql [post]
+// @Router /graphql [get]
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				h.respondWithErrors(w, http.StatusBadRequest, newError(codeBadInput, "variables must be a JSON object"))
+				return
+			}
+		}
+	case http.MethodPost:
+		body := http.MaxBytesReader(w, r.Body, maxBodySize)
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "application/graphql" {
+			query, err := io.ReadAll(body)
+			if err != nil {
+				h.respondWithErrors(w, http.StatusBadRequest, newError(codeBadInput, "invalid request body"))
+				return
+			}
+			req.Query = string(query)
+		} else if err := json.NewDecoder(body).Decode(&req); err != nil {
+			h.respondWithErrors(w, http.StatusBadRequest, newError(codeBadInput, "invalid request body"))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		h.respondWithErrors(w, http.StatusMethodNotAllowed, newError(codeBadInput, "method not allowed"))
+		return
+	}
+
+	if req.Query == "" {
+		h.respondWithErrors(w, http.StatusBadRequest, newError(codeBadInput, "query is required"))
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	if r.Method == http.MethodGet && isMutation(doc, req.OperationName) {
+		w.Header().Set("Allow", "POST")
+		h.respondWithErrors(w, http.StatusMethodNotAllowed, newError(codeBadInput, "mutations must be sent with POST"))
+		return
+	}
+
+	if err := h.limits.check(doc, req.OperationName, req.Variables); err != nil {
+		h.log.WithError(err).Warn("Rejected GraphQL query")
+		h.respondWithErrors(w, http.StatusBadRequest, err)
+		return
+	}
+
+	validation := graphql.ValidateDocument(&h.schema, doc, nil)
+	if !validation.IsValid {
+		h.respondWithJSON(w, http.StatusBadRequest, &graphql.Result{Errors: validation.Errors})
+		return
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       r.Context(),
+	})
+	h.respondWithJSON(w, http.StatusOK, result)
+}
+
+// IsMutation reports whether r is a POSTed mutation, so rate limits count
+// GraphQL queries as reads. The body is put back for the handler, requests it
+// can not parse are no mutations, the handler rejects them.
+func IsMutation(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil || len(body) > maxBodySize {
+		return false
+	}
+
+	var req Request
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/graphql" {
+		req.Query = string(body)
+	} else if err := json.Unmarshal(body, &req); err != nil {
+		return false
+	}
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	return err == nil && isMutation(doc, req.OperationName)
+}
+
+func isMutation(doc *ast.Document, operationName string) bool {
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" || (operation.Name != nil && operation.Name.Value == operationName) {
+			if operation.Operation == ast.OperationTypeMutation {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (h *Handler) respondWithErrors(w http.ResponseWriter, status int, err error) {
+	formatted := gqlerrors.FormatError(err)
+	if extended, ok := err.(gqlerrors.ExtendedError); ok {
+		formatted.Extensions = extended.Extensions()
+	}
+	h.respondWithJSON(w, status, &graphql.Result{Errors: []gqlerrors.FormattedError{formatted}})
+}
+
+func (h *Handler) respondWithJSON(w http.ResponseWriter, status int, result *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.log.WithError(err).Error("Error encoding GraphQL response")
+	}
+}
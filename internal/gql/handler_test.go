@@ -0,0 +1,45 @@
+package gql
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIsMutation(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		want        bool
+	}{
+		{"query", http.MethodPost, "application/json", `{"query":"{ persons { items { id } } }"}`, false},
+		{"mutation", http.MethodPost, "application/json", `{"query":"mutation { deletePerson(id: 1) }"}`, true},
+		{"named query of several", http.MethodPost, "application/json",
+			`{"query":"query Q { person(id: 1) { id } } mutation M { deletePerson(id: 1) }","operationName":"Q"}`, false},
+		{"named mutation of several", http.MethodPost, "application/json",
+			`{"query":"query Q { person(id: 1) { id } } mutation M { deletePerson(id: 1) }","operationName":"M"}`, true},
+		{"graphql body", http.MethodPost, "application/graphql", `mutation { deletePerson(id: 1) }`, true},
+		{"invalid JSON", http.MethodPost, "application/json", `{"query":`, false},
+		{"invalid query", http.MethodPost, "application/json", `{"query":"mutation {"}`, false},
+		{"GET", http.MethodGet, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/graphql", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			if got := IsMutation(r); got != tt.want {
+				t.Errorf("IsMutation = %v, want %v", got, tt.want)
+			}
+
+			// The handler still gets the whole body
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != tt.body {
+				t.Errorf("body after IsMutation = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
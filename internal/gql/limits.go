@@ -0,0 +1,189 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// queryFieldCost is the cost of a field that runs a database query, plain
+// fields cost one
+const queryFieldCost = 10
+
+// queryFields are the fields whose resolvers hit the database
+var queryFields = map[string]bool{
+	"person":         true,
+	"persons":        true,
+	"history":        true,
+	"lastEnrichedAt": true,
+	"createPerson":   true,
+	"updatePerson":   true,
+	"deletePerson":   true,
+	"mergePersons":   true,
+	"enrichPerson":   true,
+}
+
+// listSizes names the argument bounding the number of items a list field
+// returns, together with its default
+var listSizes = map[string]struct {
+	arg      string
+	fallback int
+}{
+	"persons": {arg: "pageSize", fallback: defaultPageSize},
+	"history": {arg: "limit", fallback: defaultHistoryLimit},
+}
+
+// Limits bounds the shape of accepted queries, zero disables a limit
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// analyzer walks the selected operation, following fragment spreads, and
+// computes its depth and estimated cost
+type analyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool
+	// sizes holds the depth and cost of the fragments walked so far, so a
+	// fragment spread many times is walked once
+	sizes map[string]fragmentSize
+}
+
+type fragmentSize struct {
+	depth, cost int
+}
+
+// check rejects queries that are nested too deeply or would resolve too many
+// fields. Costs multiply by the requested page size and history limit, so
+// persons(pageSize: 100) { history(limit: 100) { id } } is counted as the
+// 10 000 history rows it can load.
+func (l Limits) check(doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	a := &analyzer{
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+		visiting:  map[string]bool{},
+		sizes:     map[string]fragmentSize{},
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch def := definition.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		// Let the executor report the missing operation
+		return nil
+	}
+
+	depth, cost := a.selectionSet(operation.SelectionSet)
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return &Error{
+			Message: fmt.Sprintf("query depth %d exceeds the limit of %d", depth, l.MaxDepth),
+			Code:    codeTooComplex,
+			Details: map[string]interface{}{"depth": depth, "maxDepth": l.MaxDepth},
+		}
+	}
+	if l.MaxComplexity > 0 && cost > l.MaxComplexity {
+		return &Error{
+			Message: fmt.Sprintf("query complexity %d exceeds the limit of %d", cost, l.MaxComplexity),
+			Code:    codeTooComplex,
+			Details: map[string]interface{}{"complexity": cost, "maxComplexity": l.MaxComplexity},
+		}
+	}
+	return nil
+}
+
+func (a *analyzer) selectionSet(set *ast.SelectionSet) (depth, cost int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, selection := range set.Selections {
+		var d, c int
+		switch s := selection.(type) {
+		case *ast.Field:
+			d, c = a.field(s)
+		case *ast.InlineFragment:
+			d, c = a.selectionSet(s.SelectionSet)
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			fragment, ok := a.fragments[name]
+			if !ok || a.visiting[name] {
+				// Unknown and cyclic fragments fail validation later
+				continue
+			}
+			if size, ok := a.sizes[name]; ok {
+				d, c = size.depth, size.cost
+				break
+			}
+			a.visiting[name] = true
+			d, c = a.selectionSet(fragment.SelectionSet)
+			a.visiting[name] = false
+			a.sizes[name] = fragmentSize{depth: d, cost: c}
+		}
+		depth = max(depth, d)
+		cost = saturatingAdd(cost, c)
+	}
+	return depth, cost
+}
+
+func (a *analyzer) field(field *ast.Field) (depth, cost int) {
+	childDepth, childCost := a.selectionSet(field.SelectionSet)
+
+	cost = 1
+	if queryFields[field.Name.Value] {
+		cost = queryFieldCost
+	}
+	if size, ok := listSizes[field.Name.Value]; ok {
+		childCost = saturatingMul(childCost, a.intArg(field, size.arg, size.fallback))
+	}
+	return childDepth + 1, saturatingAdd(cost, childCost)
+}
+
+// intArg resolves an integer argument given inline or through a variable
+func (a *analyzer) intArg(field *ast.Field, name string, fallback int) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != name {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if parsed, err := strconv.Atoi(value.Value); err == nil && parsed > 0 {
+				return parsed
+			}
+		case *ast.Variable:
+			switch v := a.variables[value.Name.Value].(type) {
+			case int:
+				if v > 0 {
+					return v
+				}
+			case float64:
+				if v > 0 {
+					return int(v)
+				}
+			}
+		}
+	}
+	return fallback
+}
+
+const maxCost = 1 << 30
+
+func saturatingAdd(a, b int) int {
+	return min(a+b, maxCost)
+}
+
+func saturatingMul(a, b int) int {
+	if a != 0 && b > maxCost/a {
+		return maxCost
+	}
+	return min(a*b, maxCost)
+}
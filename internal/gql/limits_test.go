@@ -0,0 +1,68 @@
+package gql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+func TestLimitsCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  Limits
+		query   string
+		wantErr bool
+	}{
+		{"shallow", Limits{MaxDepth: 3}, `{ person(id: 1) { id } }`, false},
+		{"too deep", Limits{MaxDepth: 2}, `{ persons { items { history { id } } } }`, true},
+		{"too deep through a fragment", Limits{MaxDepth: 2},
+			`{ persons { ...Items } } fragment Items on PersonList { items { history { id } } }`, true},
+		{"page size multiplies", Limits{MaxComplexity: 500}, `{ persons(pageSize: 100) { items { id name } } }`, false},
+		{"history limit multiplies", Limits{MaxComplexity: 500},
+			`{ persons(pageSize: 100) { items { history(limit: 100) { id } } } }`, true},
+		{"cyclic fragments", Limits{MaxDepth: 10}, `{ ...A } fragment A on Query { ...B } fragment B on Query { ...A }`, false},
+		{"disabled", Limits{}, `{ persons(pageSize: 100) { items { history(limit: 100) { id } } } }`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tt.limits.check(doc, "", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check = %v, want error %v", err, tt.wantErr)
+			}
+			var gqlErr *Error
+			if err != nil && (!errors.As(err, &gqlErr) || gqlErr.Code != codeTooComplex) {
+				t.Errorf("check = %v, want a %s error", err, codeTooComplex)
+			}
+		})
+	}
+}
+
+// Each fragment spreads the next one twice, walking every spread would take
+// 2^levels steps
+func TestLimitsCheckNestedFragments(t *testing.T) {
+	const levels = 64
+
+	var query strings.Builder
+	query.WriteString("{ ...F0 }\n")
+	for i := 0; i < levels; i++ {
+		fmt.Fprintf(&query, "fragment F%d on Query { ...F%d ...F%d }\n", i, i+1, i+1)
+	}
+	fmt.Fprintf(&query, "fragment F%d on Query { person(id: 1) { id } }\n", levels)
+
+	doc, err := parser.Parse(parser.ParseParams{Source: query.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Limits{MaxComplexity: 1000}.check(doc, "", nil)
+	var gqlErr *Error
+	if !errors.As(err, &gqlErr) || gqlErr.Code != codeTooComplex {
+		t.Errorf("check = %v, want a %s error", err, codeTooComplex)
+	}
+}
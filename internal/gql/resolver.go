@@ -0,0 +1,318 @@
+package gql
+
+import (
+	"maps"
+	"people-enricher/internal/entity"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/sirupsen/logrus"
+)
+
+type resolver struct {
+	service entity.PersonService
+	log     *logrus.Entry
+}
+
+func (r *resolver) person(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	person, err := r.service.GetById(p.Context, id)
+	if err != nil {
+		return nil, r.toError(err)
+	}
+	return person, nil
+}
+
+func (r *resolver) persons(p graphql.ResolveParams) (interface{}, error) {
+	filter, err := filterFromArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	persons, total, err := r.service.List(p.Context, filter)
+	if err != nil {
+		return nil, r.toError(err)
+	}
+
+	return map[string]interface{}{
+		"items":      persons,
+		"total":      total,
+		"page":       filter.Page,
+		"pageSize":   filter.PageSize,
+		"totalPages": (total + filter.PageSize - 1) / filter.PageSize,
+	}, nil
+}
+
+func (r *resolver) history(p graphql.ResolveParams) (interface{}, error) {
+	person := p.Source.(*entity.Person)
+
+	limit, _ := p.Args["limit"].(int)
+	if limit < 1 || limit > maxHistoryLimit {
+		return nil, newError(codeBadInput, "limit must be between 1 and "+strconv.Itoa(maxHistoryLimit))
+	}
+
+	events, err := r.service.History(p.Context, person.ID, limit)
+	if err != nil {
+		return nil, r.toError(err)
+	}
+
+	result := make([]map[string]interface{}, len(events))
+	for i, event := range events {
+		result[i] = map[string]interface{}{
+			"id":         event.ID,
+			"type":       event.Type,
+			"occurredAt": event.OccurredAt,
+		}
+	}
+	return result, nil
+}
+
+func (r *resolver) lastEnrichedAt(p graphql.ResolveParams) (interface{}, error) {
+	personID := p.Source.(map[string]interface{})["personId"].(int64)
+
+	events, err := r.service.History(p.Context, personID, maxHistoryLimit)
+	if err != nil {
+		return nil, r.toError(err)
+	}
+	for _, event := range events {
+		if event.Type == entity.EventPersonEnriched {
+			return event.OccurredAt, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *resolver) createPerson(p graphql.ResolveParams) (interface{}, error) {
+	ctx := p.Context
+	if force, _ := p.Args["force"].(bool); force {
+		ctx = entity.WithDuplicatesAllowed(ctx)
+	}
+
+	created, err := r.service.Create(ctx, personFromInput(p.Args["input"].(map[string]interface{})))
+	if err != nil {
+		return nil, r.toError(err)
+	}
+	return created, nil
+}
+
+func (r *resolver) updatePerson(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	person := personFromInput(p.Args["input"].(map[string]interface{}))
+	person.ID = id
+
+	updated, err := r.service.Update(p.Context, person)
+	if err != nil {
+		return nil, r.toError(err)
+	}
+	return updated, nil
+}
+
+func (r *resolver) deletePerson(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	if err := r.service.Delete(p.Context, id); err != nil {
+		return nil, r.toError(err)
+	}
+	return true, nil
+}
+
+func (r *resolver) mergePersons(p graphql.ResolveParams) (interface{}, error) {
+	sourceID, err := parseID(p.Args["sourceId"])
+	if err != nil {
+		return nil, err
+	}
+	targetID, err := parseID(p.Args["targetId"])
+	if err != nil {
+		return nil, err
+	}
+	merged, err := r.service.Merge(p.Context, sourceID, targetID)
+	if err != nil {
+		return nil, r.toError(err)
+	}
+	return merged, nil
+}
+
+func (r *resolver) enrichPerson(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	person, err := r.service.Enrich(p.Context, id)
+	if err != nil {
+		return nil, r.toError(err)
+	}
+	return person, nil
+}
+
+func resolveProbability(p graphql.ResolveParams) (interface{}, error) {
+	person := p.Source.(*entity.Person)
+	return parseProbability(person.NationalityProbability), nil
+}
+
+// resolveEnrichment derives EnrichmentMetadata from the person itself,
+// lastEnrichedAt is looked up separately and only when requested
+func resolveEnrichment(p graphql.ResolveParams) (interface{}, error) {
+	person := p.Source.(*entity.Person)
+
+	populated := []string{}
+	missing := []string{}
+	for field, set := range map[string]bool{
+		"age":         person.Age != nil,
+		"gender":      person.Gender != nil,
+		"nationality": person.Nationality != nil,
+	} {
+		if set {
+			populated = append(populated, field)
+		} else {
+			missing = append(missing, field)
+		}
+	}
+	slices.Sort(populated)
+	slices.Sort(missing)
+
+	resolutions := []map[string]interface{}{}
+	for _, field := range slices.Sorted(maps.Keys(person.Resolutions)) {
+		resolution := person.Resolutions[field]
+		resolutions = append(resolutions, map[string]interface{}{
+			"field":      field,
+			"strategy":   resolution.Strategy,
+			"providers":  resolution.Providers,
+			"confidence": resolution.Confidence,
+		})
+	}
+
+	return map[string]interface{}{
+		"resolutions":            resolutions,
+		"personId":               person.ID,
+		"enriched":               len(populated) > 0,
+		"populatedFields":        populated,
+		"missingFields":          missing,
+		"nationalityProbability": parseProbability(person.NationalityProbability),
+	}, nil
+}
+
+func parseProbability(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	probability, err := strconv.ParseFloat(*value, 64)
+	if err != nil {
+		return nil
+	}
+	return probability
+}
+
+func personFromInput(input map[string]interface{}) *entity.Person {
+	person := &entity.Person{}
+	person.Name, _ = input["name"].(string)
+	person.Surname, _ = input["surname"].(string)
+	if patronymic, ok := input["patronymic"].(string); ok {
+		person.Patronymic = &patronymic
+	}
+	return person
+}
+
+func filterFromArgs(args map[string]interface{}) (*entity.PersonFilter, error) {
+	filter := &entity.PersonFilter{}
+	filter.Page, _ = args["page"].(int)
+	filter.PageSize, _ = args["pageSize"].(int)
+	if filter.Page < 1 {
+		return nil, newError(codeBadInput, "page must be positive")
+	}
+	if filter.PageSize < 1 || filter.PageSize > maxPageSize {
+		return nil, newError(codeBadInput, "pageSize must be between 1 and "+strconv.Itoa(maxPageSize))
+	}
+
+	if sort, ok := args["sort"].(string); ok && sort != "" {
+		filter.SortDesc = strings.HasPrefix(sort, "-")
+		filter.SortBy = strings.TrimPrefix(sort, "-")
+		if !slices.Contains(entity.SortableFields, filter.SortBy) {
+			return nil, newError(codeBadInput, "unknown sort field "+filter.SortBy)
+		}
+	}
+
+	input, ok := args["filter"].(map[string]interface{})
+	if !ok {
+		return filter, nil
+	}
+
+	if v, ok := input["name"].(string); ok {
+		filter.Name = &v
+	}
+	if v, ok := input["surname"].(string); ok {
+		filter.Surname = &v
+	}
+	if v, ok := input["patronymic"].(string); ok {
+		filter.Patronymic = &v
+	}
+	filter.Gender = stringList(input["gender"], strings.ToLower)
+	filter.Nationality = stringList(input["nationality"], strings.ToUpper)
+	filter.NullFields = stringList(input["nullFields"], nil)
+	filter.NotNullFields = stringList(input["notNullFields"], nil)
+	if v, ok := input["ageMin"].(int); ok {
+		filter.AgeFrom = &v
+	}
+	if v, ok := input["ageMax"].(int); ok {
+		filter.AgeTo = &v
+	}
+	if v, ok := input["nationalityProbabilityMin"].(float64); ok {
+		filter.NationalityProbabilityFrom = &v
+	}
+	if v, ok := input["nationalityProbabilityMax"].(float64); ok {
+		filter.NationalityProbabilityTo = &v
+	}
+	filter.CreatedAfter = timeArg(input["createdAfter"])
+	filter.CreatedBefore = timeArg(input["createdBefore"])
+	filter.UpdatedAfter = timeArg(input["updatedAfter"])
+	filter.UpdatedBefore = timeArg(input["updatedBefore"])
+
+	for _, field := range append(append([]string{}, filter.NullFields...), filter.NotNullFields...) {
+		if !slices.Contains(entity.NullableFields, field) {
+			return nil, newError(codeBadInput, "field "+field+" can not be checked for null")
+		}
+	}
+	return filter, nil
+}
+
+func stringList(value interface{}, normalize func(string) string) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			continue
+		}
+		if normalize != nil {
+			s = normalize(s)
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func timeArg(value interface{}) *time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return &v
+	case *time.Time:
+		return v
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package gql
+
+import (
+	"fmt"
+	"people-enricher/internal/entity"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+	defaultPageSize     = 10
+	maxPageSize         = 100
+)
+
+// NewSchema builds the GraphQL schema on top of the person service
+func NewSchema(service entity.PersonService, log *logrus.Entry) (graphql.Schema, error) {
+	r := &resolver{service: service, log: log}
+
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "PersonEvent",
+		Description: "A change of a person, as published to the outbox",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"type":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"occurredAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	resolutionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "FieldResolution",
+		Description: "How the value of an enriched field was chosen among the providers' answers",
+		Fields: graphql.Fields{
+			"field": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"strategy": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "priority, confidence, weighted_average, majority or rules",
+			},
+			"providers":  &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			"confidence": &graphql.Field{Type: graphql.Float},
+		},
+	})
+
+	enrichmentType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "EnrichmentMetadata",
+		Description: "What enrichment found out about a person",
+		Fields: graphql.Fields{
+			"enriched": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "True when at least one field was enriched",
+			},
+			"populatedFields": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			"missingFields":   &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			"nationalityProbability": &graphql.Field{
+				Type: graphql.Float,
+			},
+			"resolutions": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(resolutionType))),
+			},
+			"lastEnrichedAt": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "Time of the latest person.enriched event",
+				Resolve:     r.lastEnrichedAt,
+			},
+		},
+	})
+
+	personType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Person",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"surname":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"patronymic": &graphql.Field{Type: graphql.String},
+			"nameLatin": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Name transliterated to Latin, null when written in Latin",
+			},
+			"surnameLatin":    &graphql.Field{Type: graphql.String},
+			"patronymicLatin": &graphql.Field{Type: graphql.String},
+			"age":             &graphql.Field{Type: graphql.Int},
+			"gender":          &graphql.Field{Type: graphql.String},
+			"nationality":     &graphql.Field{Type: graphql.String},
+			"nationalityProbability": &graphql.Field{
+				Type:    graphql.Float,
+				Resolve: resolveProbability,
+			},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"enrichment": &graphql.Field{
+				Type:    graphql.NewNonNull(enrichmentType),
+				Resolve: resolveEnrichment,
+			},
+			"history": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(eventType))),
+				Description: "Latest changes of the person, newest first",
+				Args: graphql.FieldConfigArgument{
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultHistoryLimit},
+				},
+				Resolve: r.history,
+			},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PersonConnection",
+		Fields: graphql.Fields{
+			"items":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(personType)))},
+			"total":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"page":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"pageSize":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"totalPages": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	filterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "PersonFilter",
+		Description: "Same filters as GET /persons",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":                      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"surname":                   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"patronymic":                &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"gender":                    &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"nationality":               &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"ageMin":                    &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"ageMax":                    &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"nationalityProbabilityMin": &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"nationalityProbabilityMax": &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"createdAfter":              &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"createdBefore":             &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"updatedAfter":              &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"updatedBefore":             &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"nullFields":                &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"notNullFields":             &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		},
+	})
+
+	personInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "PersonInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"surname":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"patronymic": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	idArg := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"person": &graphql.Field{
+				Type:    personType,
+				Args:    graphql.FieldConfigArgument{"id": idArg},
+				Resolve: r.person,
+			},
+			"persons": &graphql.Field{
+				Type: graphql.NewNonNull(connectionType),
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: filterType},
+					"sort": &graphql.ArgumentConfig{
+						Type:        graphql.String,
+						Description: "Sort field, prefix with - for descending",
+					},
+					"page":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+					"pageSize": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+				},
+				Resolve: r.persons,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createPerson": &graphql.Field{
+				Type: graphql.NewNonNull(personType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(personInputType)},
+					"force": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				},
+				Resolve: r.createPerson,
+			},
+			"updatePerson": &graphql.Field{
+				Type: graphql.NewNonNull(personType),
+				Args: graphql.FieldConfigArgument{
+					"id":    idArg,
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(personInputType)},
+				},
+				Resolve: r.updatePerson,
+			},
+			"deletePerson": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"id": idArg},
+				Resolve: r.deletePerson,
+			},
+			"mergePersons": &graphql.Field{
+				Type: graphql.NewNonNull(personType),
+				Args: graphql.FieldConfigArgument{
+					"sourceId": idArg,
+					"targetId": idArg,
+				},
+				Resolve: r.mergePersons,
+			},
+			"enrichPerson": &graphql.Field{
+				Type:    graphql.NewNonNull(personType),
+				Args:    graphql.FieldConfigArgument{"id": idArg},
+				Resolve: r.enrichPerson,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+func parseID(value interface{}) (int64, error) {
+	id, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, newError(codeBadInput, "invalid id")
+	}
+	return id, nil
+}
@@ -0,0 +1,84 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"people-enricher/internal/auth"
+	"people-enricher/internal/entity"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Authenticator checks the credentials of a call
+type Authenticator interface {
+	Authenticate(ctx context.Context, apiKey, bearer string) (*entity.Principal, error)
+}
+
+// publicServices are served without credentials, so probes and tooling keep
+// working
+var publicServices = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+// authenticate checks the x-api-key or authorization metadata of a call,
+// like the HTTP middleware does for headers
+func authenticate(ctx context.Context, authenticator Authenticator, method string, log *logrus.Entry) (context.Context, error) {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	bearer := auth.BearerToken(first("authorization"))
+
+	principal, err := authenticator.Authenticate(ctx, first("x-api-key"), bearer)
+	if err != nil {
+		if errors.Is(err, entity.ErrUnauthorized) {
+			log.WithError(err).WithField("method", method).Debug("Call not authenticated")
+			return nil, status.Error(codes.Unauthenticated, "missing, invalid or revoked credentials")
+		}
+		log.WithError(err).WithField("method", method).Error("Error authenticating call")
+		return nil, status.Error(codes.Internal, "error authenticating call")
+	}
+	return entity.WithPrincipal(ctx, principal), nil
+}
+
+func unaryAuth(authenticator Authenticator, log *logrus.Entry) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authenticator, info.FullMethod, log)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuth(authenticator Authenticator, log *logrus.Entry) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authenticator, info.FullMethod, log)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream carries the context with the principal and tenant
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,96 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"people-enricher/internal/entity"
+	"slices"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps service errors to gRPC status codes, following the same
+// rules as the HTTP handlers: invalid input is InvalidArgument with field
+// violations, duplicates are AlreadyExists, merged IDs are NotFound with the
+// ID of the kept person, actions the caller's roles do not allow are
+// PermissionDenied, used up quotas are ResourceExhausted, everything unknown
+// is Internal.
+func (s *PersonServer) toStatus(err error, operation string) error {
+	var duplicateErr *entity.DuplicateError
+	var mergedErr *entity.MergedError
+	var validationErr *entity.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		st := status.New(codes.InvalidArgument, validationErr.Error())
+		violations := &errdetails.BadRequest{}
+		for _, field := range validationErr.Fields {
+			violations.FieldViolations = append(violations.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+			})
+		}
+		if detailed, detailErr := st.WithDetails(violations); detailErr == nil {
+			return detailed.Err()
+		}
+		return st.Err()
+	case errors.Is(err, entity.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &duplicateErr):
+		st := status.New(codes.AlreadyExists, "person with the same or a similar name already exists")
+		info := &errdetails.ErrorInfo{Reason: "DUPLICATE_PERSON", Metadata: map[string]string{}}
+		for i, candidate := range duplicateErr.Candidates {
+			info.Metadata[fmt.Sprintf("candidate_%d", i)] = fmt.Sprint(candidate.ID)
+		}
+		if detailed, detailErr := st.WithDetails(info); detailErr == nil {
+			return detailed.Err()
+		}
+		return st.Err()
+	case errors.As(err, &mergedErr):
+		st := status.New(codes.NotFound, mergedErr.Error())
+		info := &errdetails.ErrorInfo{
+			Reason:   "PERSON_MERGED",
+			Metadata: map[string]string{"target_id": fmt.Sprint(mergedErr.TargetID)},
+		}
+		if detailed, detailErr := st.WithDetails(info); detailErr == nil {
+			return detailed.Err()
+		}
+		return st.Err()
+	case errors.Is(err, entity.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, entity.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, entity.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, entity.ErrQuota):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	s.log.WithError(err).Error("Error " + operation)
+	return status.Error(codes.Internal, "error "+operation)
+}
+
+func validateFilter(filter *entity.PersonFilter) error {
+	for _, field := range append(append([]string{}, filter.NullFields...), filter.NotNullFields...) {
+		if !slices.Contains(entity.NullableFields, field) {
+			return fmt.Errorf("field %q can not be checked for null", field)
+		}
+	}
+	if filter.SortBy != "" && !slices.Contains(entity.SortableFields, filter.SortBy) {
+		return fmt.Errorf("field %q can not be used for sorting", filter.SortBy)
+	}
+	if filter.AgeFrom != nil && filter.AgeTo != nil && *filter.AgeFrom > *filter.AgeTo {
+		return fmt.Errorf("age_min must not be greater than age_max")
+	}
+	if filter.Page < 0 || filter.PageSize < 0 {
+		return fmt.Errorf("page and page_size must not be negative")
+	}
+	return nil
+}
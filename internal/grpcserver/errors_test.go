@@ -0,0 +1,81 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"people-enricher/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testServer() *PersonServer {
+	logger, _ := test.NewNullLogger()
+	return NewPersonServer(nil, logrus.NewEntry(logger))
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"validation", entity.NewValidationError("name", "is required"), codes.InvalidArgument},
+		{"wrapped validation", fmt.Errorf("creating: %w", entity.ErrValidation), codes.InvalidArgument},
+		{"duplicate", &entity.DuplicateError{Candidates: []entity.DuplicateCandidate{{ID: 7}}}, codes.AlreadyExists},
+		{"merged", &entity.MergedError{ID: 1, TargetID: 2}, codes.NotFound},
+		{"not found", fmt.Errorf("person 1: %w", entity.ErrNotFound), codes.NotFound},
+		{"conflict", entity.ErrConflict, codes.AlreadyExists},
+		{"forbidden", &entity.ForbiddenError{Action: "delete persons", Role: "reader"}, codes.PermissionDenied},
+		{"quota", fmt.Errorf("enriching: %w", entity.ErrQuota), codes.ResourceExhausted},
+		{"canceled", context.Canceled, codes.Canceled},
+		{"deadline", context.DeadlineExceeded, codes.DeadlineExceeded},
+		{"unknown", errors.New("connection refused"), codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(testServer().toStatus(tt.err, "testing")); got != tt.want {
+				t.Errorf("code = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToStatusDetails(t *testing.T) {
+	server := testServer()
+
+	st := status.Convert(server.toStatus(entity.NewValidationError("age_min", "must not be negative"), "testing"))
+	violations, ok := detail[*errdetails.BadRequest](st)
+	if !ok || len(violations.FieldViolations) != 1 || violations.FieldViolations[0].Field != "age_min" {
+		t.Errorf("validation details = %v, want a violation of age_min", st.Details())
+	}
+
+	st = status.Convert(server.toStatus(&entity.MergedError{ID: 1, TargetID: 2}, "testing"))
+	info, ok := detail[*errdetails.ErrorInfo](st)
+	if !ok || info.Reason != "PERSON_MERGED" || info.Metadata["target_id"] != "2" {
+		t.Errorf("merged details = %v, want target 2", st.Details())
+	}
+
+	// Unknown errors are not passed on to the caller
+	st = status.Convert(server.toStatus(errors.New("password authentication failed"), "testing"))
+	if st.Message() != "error testing" {
+		t.Errorf("message = %q, want %q", st.Message(), "error testing")
+	}
+}
+
+// detail returns the first detail of type T
+func detail[T any](st *status.Status) (T, bool) {
+	for _, d := range st.Details() {
+		if typed, ok := d.(T); ok {
+			return typed, true
+		}
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,163 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"people-enricher/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const listMethod = "/person.v1.PersonService/ListPersons"
+
+// stubAuthenticator accepts the key "valid" and the bearer token "token"
+// and records what it was given
+type stubAuthenticator struct {
+	apiKey, bearer string
+	err            error
+}
+
+func (a *stubAuthenticator) Authenticate(ctx context.Context, apiKey, bearer string) (*entity.Principal, error) {
+	a.apiKey, a.bearer = apiKey, bearer
+	if a.err != nil {
+		return nil, a.err
+	}
+	if apiKey != "valid" && bearer != "token" {
+		return nil, fmt.Errorf("unknown key: %w", entity.ErrUnauthorized)
+	}
+	return &entity.Principal{Subject: "tester"}, nil
+}
+
+// stubResolver sets the requested tenant or fails with err
+type stubResolver struct {
+	requested string
+	err       error
+}
+
+func (r *stubResolver) Resolve(ctx context.Context, requested string) (context.Context, error) {
+	r.requested = requested
+	if r.err != nil {
+		return nil, r.err
+	}
+	return entity.WithTenant(ctx, &entity.Tenant{ID: requested}), nil
+}
+
+func nullLog() *logrus.Entry {
+	logger, _ := test.NewNullLogger()
+	return logrus.NewEntry(logger)
+}
+
+func incoming(pairs ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+func TestUnaryAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		method     string
+		err        error
+		want       codes.Code
+		wantBearer string
+	}{
+		{"API key", incoming("x-api-key", "valid"), listMethod, nil, codes.OK, ""},
+		{"bearer token", incoming("authorization", "bearer token"), listMethod, nil, codes.OK, "token"},
+		{"no credentials", context.Background(), listMethod, nil, codes.Unauthenticated, ""},
+		{"wrong key", incoming("x-api-key", "guess"), listMethod, nil, codes.Unauthenticated, ""},
+		{"store failing", incoming("x-api-key", "valid"), listMethod, errors.New("connection refused"), codes.Internal, ""},
+		{"health check", context.Background(), "/grpc.health.v1.Health/Check", nil, codes.OK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := &stubAuthenticator{err: tt.err}
+			var principal *entity.Principal
+			_, err := unaryAuth(authenticator, nullLog())(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(ctx context.Context, req any) (any, error) {
+					principal = entity.PrincipalFrom(ctx)
+					return nil, nil
+				})
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code = %s, want %s", got, tt.want)
+			}
+			if authenticator.bearer != tt.wantBearer {
+				t.Errorf("bearer = %q, want %q", authenticator.bearer, tt.wantBearer)
+			}
+			if tt.want == codes.OK && tt.method == listMethod && principal == nil {
+				t.Error("handler got no principal")
+			}
+		})
+	}
+}
+
+func TestUnaryTenant(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		err        error
+		want       codes.Code
+		wantTenant string
+	}{
+		{"requested tenant", incoming("x-tenant-id", " acme "), nil, codes.OK, "acme"},
+		{"no tenant", context.Background(), nil, codes.OK, ""},
+		{"tenant of another principal", incoming("x-tenant-id", "acme"), &entity.ForbiddenError{Action: "act for tenant acme"}, codes.PermissionDenied, "acme"},
+		{"unknown tenant", incoming("x-tenant-id", "nope"), entity.NewValidationError("tenant", "unknown tenant"), codes.InvalidArgument, "nope"},
+		{"store failing", incoming("x-tenant-id", "acme"), errors.New("connection refused"), codes.Internal, "acme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &stubResolver{err: tt.err}
+			var tenant *entity.Tenant
+			_, err := unaryTenant(resolver, nullLog())(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: listMethod},
+				func(ctx context.Context, req any) (any, error) {
+					tenant = entity.TenantFrom(ctx)
+					return nil, nil
+				})
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code = %s, want %s", got, tt.want)
+			}
+			if resolver.requested != tt.wantTenant {
+				t.Errorf("requested tenant = %q, want %q", resolver.requested, tt.wantTenant)
+			}
+			if tt.want == codes.OK && (tenant == nil || tenant.ID != tt.wantTenant) {
+				t.Errorf("handler got tenant %+v, want %q", tenant, tt.wantTenant)
+			}
+		})
+	}
+}
+
+// fakeStream is a server stream carrying only a context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func TestStreamInterceptorsPassContext(t *testing.T) {
+	stream := &fakeStream{ctx: incoming("x-api-key", "valid", "x-tenant-id", "acme")}
+	info := &grpc.StreamServerInfo{FullMethod: listMethod}
+
+	var tenant *entity.Tenant
+	var principal *entity.Principal
+	handler := func(srv any, ss grpc.ServerStream) error {
+		return streamTenant(&stubResolver{}, nullLog())(srv, ss, info, func(srv any, ss grpc.ServerStream) error {
+			principal = entity.PrincipalFrom(ss.Context())
+			tenant = entity.TenantFrom(ss.Context())
+			return nil
+		})
+	}
+	if err := streamAuth(&stubAuthenticator{}, nullLog())(nil, stream, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if principal == nil || tenant == nil || tenant.ID != "acme" {
+		t.Errorf("stream handler got principal %+v and tenant %+v", principal, tenant)
+	}
+}
@@ -0,0 +1,228 @@
+package grpcserver
+
+import (
+	"context"
+	"people-enricher/internal/entity"
+	personv1 "people-enricher/pkg/api/person/v1"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// streamPageSize is the page size used to stream a whole List result
+const streamPageSize = 100
+
+// PersonServer exposes entity.PersonService over gRPC
+type PersonServer struct {
+	personv1.UnimplementedPersonServiceServer
+
+	service entity.PersonService
+	log     *logrus.Entry
+}
+
+// NewPersonServer creates a new PersonServer
+func NewPersonServer(service entity.PersonService, log *logrus.Entry) *PersonServer {
+	return &PersonServer{
+		service: service,
+		log:     log.WithField("transport", "grpc"),
+	}
+}
+
+// Register adds the person service to a gRPC server
+func (s *PersonServer) Register(server *grpc.Server) {
+	personv1.RegisterPersonServiceServer(server, s)
+}
+
+func (s *PersonServer) CreatePerson(ctx context.Context, req *personv1.CreatePersonRequest) (*personv1.Person, error) {
+	if req.GetForce() {
+		ctx = entity.WithDuplicatesAllowed(ctx)
+	}
+
+	created, err := s.service.Create(ctx, &entity.Person{
+		Name:       req.GetName(),
+		Surname:    req.GetSurname(),
+		Patronymic: req.Patronymic,
+	})
+	if err != nil {
+		return nil, s.toStatus(err, "creating person")
+	}
+	return toProto(created), nil
+}
+
+func (s *PersonServer) GetPerson(ctx context.Context, req *personv1.GetPersonRequest) (*personv1.Person, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be positive")
+	}
+
+	person, err := s.service.GetById(ctx, req.GetId())
+	if err != nil {
+		return nil, s.toStatus(err, "fetching person")
+	}
+	return toProto(person), nil
+}
+
+func (s *PersonServer) UpdatePerson(ctx context.Context, req *personv1.UpdatePersonRequest) (*personv1.Person, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be positive")
+	}
+	updated, err := s.service.Update(ctx, &entity.Person{
+		ID:         req.GetId(),
+		Name:       req.GetName(),
+		Surname:    req.GetSurname(),
+		Patronymic: req.Patronymic,
+	})
+	if err != nil {
+		return nil, s.toStatus(err, "updating person")
+	}
+	return toProto(updated), nil
+}
+
+func (s *PersonServer) DeletePerson(ctx context.Context, req *personv1.DeletePersonRequest) (*personv1.DeletePersonResponse, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be positive")
+	}
+
+	if err := s.service.Delete(ctx, req.GetId()); err != nil {
+		return nil, s.toStatus(err, "deleting person")
+	}
+	return &personv1.DeletePersonResponse{}, nil
+}
+
+func (s *PersonServer) EnrichPerson(ctx context.Context, req *personv1.EnrichPersonRequest) (*personv1.Person, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be positive")
+	}
+
+	person, err := s.service.Enrich(ctx, req.GetId())
+	if err != nil {
+		return nil, s.toStatus(err, "enriching person")
+	}
+	return toProto(person), nil
+}
+
+func (s *PersonServer) ListPersons(req *personv1.ListPersonsRequest, stream grpc.ServerStreamingServer[personv1.Person]) error {
+	ctx := stream.Context()
+
+	filter, err := filterFromProto(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// A requested page is streamed as is, otherwise all pages are walked
+	single := req.GetPageSize() > 0
+	if !single {
+		filter.Page = 1
+		filter.PageSize = streamPageSize
+	}
+
+	headerSent := false
+	for {
+		persons, total, err := s.service.List(ctx, filter)
+		if err != nil {
+			return s.toStatus(err, "listing persons")
+		}
+		if !headerSent {
+			if err := stream.SendHeader(metadata.Pairs("x-total-count", strconv.Itoa(total))); err != nil {
+				return err
+			}
+			headerSent = true
+		}
+
+		for _, person := range persons {
+			if err := stream.Send(toProto(person)); err != nil {
+				return err
+			}
+		}
+
+		if single || len(persons) < filter.PageSize {
+			return nil
+		}
+		filter.Page++
+	}
+}
+
+func toProto(person *entity.Person) *personv1.Person {
+	result := &personv1.Person{
+		Id:              person.ID,
+		Name:            person.Name,
+		Surname:         person.Surname,
+		Patronymic:      person.Patronymic,
+		NameLatin:       person.NameLatin,
+		SurnameLatin:    person.SurnameLatin,
+		PatronymicLatin: person.PatronymicLatin,
+		Gender:          person.Gender,
+		Nationality:     person.Nationality,
+		CreatedAt:       timestamppb.New(person.CreatedAt),
+		UpdatedAt:       timestamppb.New(person.UpdatedAt),
+	}
+	if person.Age != nil {
+		age := int32(*person.Age)
+		result.Age = &age
+	}
+	if person.NationalityProbability != nil {
+		if probability, err := strconv.ParseFloat(*person.NationalityProbability, 64); err == nil {
+			result.NationalityProbability = &probability
+		}
+	}
+	return result
+}
+
+func filterFromProto(req *personv1.ListPersonsRequest) (*entity.PersonFilter, error) {
+	filter := &entity.PersonFilter{
+		Name:                       req.Name,
+		Surname:                    req.Surname,
+		Patronymic:                 req.Patronymic,
+		NationalityProbabilityFrom: req.NationalityProbabilityMin,
+		NationalityProbabilityTo:   req.NationalityProbabilityMax,
+		NullFields:                 req.GetNullFields(),
+		NotNullFields:              req.GetNotNullFields(),
+		Page:                       int(req.GetPage()),
+		PageSize:                   int(req.GetPageSize()),
+	}
+
+	for _, gender := range req.GetGender() {
+		filter.Gender = append(filter.Gender, strings.ToLower(gender))
+	}
+	for _, nationality := range req.GetNationality() {
+		filter.Nationality = append(filter.Nationality, strings.ToUpper(nationality))
+	}
+	if req.AgeMin != nil {
+		age := int(req.GetAgeMin())
+		filter.AgeFrom = &age
+	}
+	if req.AgeMax != nil {
+		age := int(req.GetAgeMax())
+		filter.AgeTo = &age
+	}
+	if sort := req.GetSort(); sort != "" {
+		filter.SortDesc = strings.HasPrefix(sort, "-")
+		filter.SortBy = strings.TrimPrefix(sort, "-")
+	}
+	if req.CreatedAfter != nil {
+		t := req.GetCreatedAfter().AsTime()
+		filter.CreatedAfter = &t
+	}
+	if req.CreatedBefore != nil {
+		t := req.GetCreatedBefore().AsTime()
+		filter.CreatedBefore = &t
+	}
+	if req.UpdatedAfter != nil {
+		t := req.GetUpdatedAfter().AsTime()
+		filter.UpdatedAfter = &t
+	}
+	if req.UpdatedBefore != nil {
+		t := req.GetUpdatedBefore().AsTime()
+		filter.UpdatedBefore = &t
+	}
+
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
@@ -0,0 +1,73 @@
+package grpcserver
+
+import (
+	"testing"
+	"time"
+
+	personv1 "people-enricher/pkg/api/person/v1"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestFilterFromProto(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter, err := filterFromProto(&personv1.ListPersonsRequest{
+		Name:         proto.String("Ivan"),
+		Gender:       []string{"Male"},
+		Nationality:  []string{"ru", "Ua"},
+		AgeMin:       proto.Int32(18),
+		AgeMax:       proto.Int32(30),
+		CreatedAfter: timestamppb.New(created),
+		NullFields:   []string{"age"},
+		Page:         2,
+		PageSize:     20,
+		Sort:         "-age",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filter.Name == nil || *filter.Name != "Ivan" {
+		t.Errorf("name = %v, want Ivan", filter.Name)
+	}
+	if len(filter.Gender) != 1 || filter.Gender[0] != "male" {
+		t.Errorf("gender = %v, want [male]", filter.Gender)
+	}
+	if len(filter.Nationality) != 2 || filter.Nationality[0] != "RU" || filter.Nationality[1] != "UA" {
+		t.Errorf("nationality = %v, want [RU UA]", filter.Nationality)
+	}
+	if filter.AgeFrom == nil || *filter.AgeFrom != 18 || filter.AgeTo == nil || *filter.AgeTo != 30 {
+		t.Errorf("ages = %v..%v, want 18..30", filter.AgeFrom, filter.AgeTo)
+	}
+	if filter.CreatedAfter == nil || !filter.CreatedAfter.Equal(created) {
+		t.Errorf("created after = %v, want %s", filter.CreatedAfter, created)
+	}
+	if filter.SortBy != "age" || !filter.SortDesc {
+		t.Errorf("sort = %q desc %v, want age descending", filter.SortBy, filter.SortDesc)
+	}
+	if filter.Page != 2 || filter.PageSize != 20 {
+		t.Errorf("page = %d of %d, want 2 of 20", filter.Page, filter.PageSize)
+	}
+}
+
+func TestFilterFromProtoRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *personv1.ListPersonsRequest
+	}{
+		{"unknown null field", &personv1.ListPersonsRequest{NullFields: []string{"name"}}},
+		{"unknown not null field", &personv1.ListPersonsRequest{NotNullFields: []string{"password"}}},
+		{"unknown sort field", &personv1.ListPersonsRequest{Sort: "-password"}},
+		{"ages reversed", &personv1.ListPersonsRequest{AgeMin: proto.Int32(30), AgeMax: proto.Int32(18)}},
+		{"negative page", &personv1.ListPersonsRequest{Page: -1}},
+		{"negative page size", &personv1.ListPersonsRequest{PageSize: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if filter, err := filterFromProto(tt.req); err == nil {
+				t.Errorf("filterFromProto = %+v, want an error", filter)
+			}
+		})
+	}
+}
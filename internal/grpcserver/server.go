@@ -0,0 +1,65 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer creates a gRPC server with the person service, health checks
+// and reflection registered. Calls are authenticated by authenticator, then
+// act on the tenant picked by tenants.
+func NewServer(personServer *PersonServer, authenticator Authenticator, tenants TenantResolver, log *logrus.Entry) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{unaryLogger(log), unaryAuth(authenticator, log), unaryTenant(tenants, log)}
+	stream := []grpc.StreamServerInterceptor{streamLogger(log), streamAuth(authenticator, log), streamTenant(tenants, log)}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+
+	personServer.Register(server)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	reflection.Register(server)
+
+	return server
+}
+
+func unaryLogger(log *logrus.Entry) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(log, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func streamLogger(log *logrus.Entry) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(log, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(log *logrus.Entry, method string, start time.Time, err error) {
+	code := status.Code(err)
+	entry := log.WithFields(logrus.Fields{
+		"transport": "grpc",
+		"method":    method,
+		"code":      code.String(),
+		"duration":  time.Since(start).String(),
+	})
+	if code == codes.Internal || code == codes.Unknown {
+		entry.WithError(err).Error("gRPC call failed")
+		return
+	}
+	entry.Debug("gRPC call handled")
+}
@@ -0,0 +1,67 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"people-enricher/internal/entity"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TenantResolver picks the tenant of a call
+type TenantResolver interface {
+	Resolve(ctx context.Context, requested string) (context.Context, error)
+}
+
+// resolveTenant attaches the tenant of the principal or of the x-tenant-id
+// metadata to the call context, like the HTTP middleware does for headers
+func resolveTenant(ctx context.Context, resolver TenantResolver, method string, log *logrus.Entry) (context.Context, error) {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+
+	requested := ""
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("x-tenant-id"); len(values) > 0 {
+		requested = strings.TrimSpace(values[0])
+	}
+
+	ctx, err := resolver.Resolve(ctx, requested)
+	switch {
+	case err == nil:
+		return ctx, nil
+	case errors.Is(err, entity.ErrForbidden):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, entity.ErrValidation):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.WithError(err).WithField("method", method).Error("Error resolving tenant")
+	return nil, status.Error(codes.Internal, "error resolving tenant")
+}
+
+func unaryTenant(resolver TenantResolver, log *logrus.Entry) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := resolveTenant(ctx, resolver, info.FullMethod, log)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamTenant(resolver TenantResolver, log *logrus.Entry) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := resolveTenant(ss.Context(), resolver, info.FullMethod, log)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"people-enricher/internal/entity"
+
+	"github.com/sirupsen/logrus"
+)
+
+// APIKeyHandler handles the admin endpoints managing API keys
+type APIKeyHandler struct {
+	service entity.APIKeyService
+	log     *logrus.Entry
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(service entity.APIKeyService, log *logrus.Entry) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// Create godoc
+// @Summary Create an API key
+// @Description Create a key clients authenticate with in the X-API-Key header or as a bearer token. The key is only returned in this response
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key body entity.APIKeyInput true "API key"
+// @Success 201 {object} entity.APIKey
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input entity.APIKeyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.log.WithError(err).Debug("Error decoding request body")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	key, err := h.service.Create(r.Context(), &input)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error creating API key")
+		return
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"id":         key.ID,
+		"created_by": principalSubject(r),
+	}).Info("API key created")
+	respondWithJSON(w, http.StatusCreated, key)
+}
+
+// List godoc
+// @Summary List API keys
+// @Tags admin
+// @Produce json
+// @Success 200 {array} entity.APIKey
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.List(r.Context())
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error listing API keys")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, keys)
+}
+
+// Revoke godoc
+// @Summary Revoke an API key
+// @Description Revoked keys are rejected right away and can not be restored
+// @Tags admin
+// @Param id path int true "API key ID"
+// @Success 204
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Invalid API key ID")
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), id); err != nil {
+		respondWithServiceError(w, r, h.log.WithField("id", id), err, "Error revoking API key")
+		return
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"id":         id,
+		"revoked_by": principalSubject(r),
+	}).Info("API key revoked")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"people-enricher/internal/auth"
+	"people-enricher/internal/entity"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Authenticator checks the credentials of a request
+type Authenticator interface {
+	Authenticate(ctx context.Context, apiKey, bearer string) (*entity.Principal, error)
+}
+
+// publicPaths are served without credentials
+var publicPaths = []string{"/swagger/"}
+
+// Authenticate rejects requests without a valid API key (X-API-Key header or
+// bearer token) or JWT bearer token with 401, and attaches the principal of
+// the others to the request context
+func Authenticate(authenticator Authenticator, log *logrus.Entry) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range publicPaths {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			bearer := auth.BearerToken(r.Header.Get("Authorization"))
+			principal, err := authenticator.Authenticate(r.Context(), r.Header.Get("X-API-Key"), bearer)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="people-enricher"`)
+				respondWithServiceError(w, r, log, err, "Error authenticating request")
+				return
+			}
+
+			log.WithFields(logrus.Fields{
+				"subject": principal.Subject,
+				"method":  principal.Method,
+				"path":    r.URL.Path,
+			}).Debug("Request authenticated")
+			next.ServeHTTP(w, r.WithContext(entity.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// principalSubject is the subject of the caller for audit logs, "anonymous"
+// when authentication is off
+func principalSubject(r *http.Request) string {
+	if principal := entity.PrincipalFrom(r.Context()); principal != nil {
+		return principal.Subject
+	}
+	return ""
+}
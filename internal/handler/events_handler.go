@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"people-enricher/internal/entity"
+	"people-enricher/internal/policy"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	replayPageSize    = 500
+	heartbeatInterval = 15 * time.Second
+)
+
+// EventsHandler streams person changes as Server-Sent Events
+type EventsHandler struct {
+	source entity.EventSource
+	log    *logrus.Entry
+}
+
+// NewEventsHandler creates a new EventsHandler
+func NewEventsHandler(source entity.EventSource, log *logrus.Entry) *EventsHandler {
+	return &EventsHandler{
+		source: source,
+		log:    log,
+	}
+}
+
+// Stream godoc
+// @Summary Stream person changes
+// @Description Server-Sent Events stream of person.created, person.updated, person.enriched and person.deleted events. Message IDs are event sequence numbers, reconnecting clients send Last-Event-ID to receive the events they missed
+// @Tags persons
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "Resume after this event sequence number"
+// @Param last_event_id query int false "Resume after this event sequence number, for clients that can not set headers"
+// @Param types query string false "Comma separated event types to receive"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Router /persons/events [get]
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	// The stream reads from the broker, not the person service, so the policy
+	// is checked here
+	if err := policy.Authorize(r.Context(), policy.ReadPersons); err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error streaming events")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondWithError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	lastSeq := int64(0)
+	resume := r.Header.Get("Last-Event-ID")
+	if resume == "" {
+		resume = r.URL.Query().Get("last_event_id")
+	}
+	if resume != "" {
+		parsed, err := strconv.ParseInt(resume, 10, 64)
+		if err != nil || parsed < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid Last-Event-ID")
+			return
+		}
+		lastSeq = parsed
+	}
+
+	// The broker fans out the events of every tenant, replays are scoped by
+	// the store
+	tenantID := entity.TenantID(r.Context())
+
+	var types []string
+	if value := r.URL.Query().Get("types"); value != "" {
+		types = splitList(value, func(s string) string { return s })
+	}
+
+	// Subscribe before replaying so nothing committed in between is lost,
+	// events seen during the replay are skipped by sequence number below.
+	live, head, unsubscribe := h.source.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if resume == "" && head > 0 {
+		// New clients get a position right away, so they resume from it
+		// even when they reconnect before receiving an event
+		lastSeq = head
+		fmt.Fprintf(w, "id: %d\n\n", head)
+	}
+	flusher.Flush()
+
+	h.log.WithField("last_event_id", lastSeq).Debug("Event stream opened")
+
+	if resume != "" {
+		for {
+			missed, err := h.source.Since(r.Context(), lastSeq, replayPageSize)
+			if err != nil {
+				h.log.WithError(err).Error("Failed to replay events")
+				return
+			}
+			for _, event := range missed {
+				if err := writeEvent(w, event, types); err != nil {
+					return
+				}
+				lastSeq = *event.Seq
+			}
+			flusher.Flush()
+			if len(missed) < replayPageSize {
+				break
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			h.log.Debug("Event stream closed by client")
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-live:
+			if !ok {
+				// Closed by the broker for being too slow or stopping, the
+				// client reconnects with Last-Event-ID
+				h.log.WithField("last_event_id", lastSeq).Info("Event stream closed by the server")
+				return
+			}
+			if *event.Seq <= lastSeq || event.TenantID != tenantID {
+				continue
+			}
+			if err := writeEvent(w, event, types); err != nil {
+				return
+			}
+			lastSeq = *event.Seq
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event entity.Event, types []string) error {
+	if len(types) > 0 && !slices.Contains(types, event.Type) {
+		return nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", *event.Seq, event.Type, data)
+	return err
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"people-enricher/internal/entity"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	nullValue    = "null"
+	notNullValue = "!null"
+)
+
+// parseFilter builds a PersonFilter from List query parameters.
+//
+// Supported syntax:
+//
+//	name=iv                     substring match (also surname, patronymic)
+//	gender=male,female          any of the listed values (also nationality)
+//	age=null / age=!null        NULL checks for nullable fields
+//	age_min=18&age_max=30       inclusive age range
+//	nationality_probability_min=0.5
+//	created_after=2025-01-01    dates in RFC 3339 or YYYY-MM-DD form
+//	sort=-age                   order by a field, "-" for descending
+func parseFilter(query url.Values) (*entity.PersonFilter, error) {
+	filter := &entity.PersonFilter{}
+
+	if name := query.Get("name"); name != "" {
+		filter.Name = &name
+	}
+	if surname := query.Get("surname"); surname != "" {
+		filter.Surname = &surname
+	}
+
+	for _, field := range entity.NullableFields {
+		value := strings.ToLower(strings.TrimSpace(query.Get(field)))
+		switch value {
+		case nullValue:
+			filter.NullFields = append(filter.NullFields, field)
+		case notNullValue:
+			filter.NotNullFields = append(filter.NotNullFields, field)
+		}
+	}
+
+	if patronymic := query.Get("patronymic"); patronymic != "" && !isNullCheck(patronymic) {
+		filter.Patronymic = &patronymic
+	}
+	if gender := query.Get("gender"); gender != "" && !isNullCheck(gender) {
+		filter.Gender = splitList(gender, strings.ToLower)
+	}
+	if nationality := query.Get("nationality"); nationality != "" && !isNullCheck(nationality) {
+		filter.Nationality = splitList(nationality, strings.ToUpper)
+	}
+
+	var err error
+	if filter.AgeFrom, err = parseIntParam(query, "age_min"); err != nil {
+		return nil, err
+	}
+	if filter.AgeTo, err = parseIntParam(query, "age_max"); err != nil {
+		return nil, err
+	}
+	if filter.NationalityProbabilityFrom, err = parseFloatParam(query, "nationality_probability_min"); err != nil {
+		return nil, err
+	}
+	if filter.NationalityProbabilityTo, err = parseFloatParam(query, "nationality_probability_max"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedAfter, err = parseTimeParam(query, "created_after"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedBefore, err = parseTimeParam(query, "created_before"); err != nil {
+		return nil, err
+	}
+	if filter.UpdatedAfter, err = parseTimeParam(query, "updated_after"); err != nil {
+		return nil, err
+	}
+	if filter.UpdatedBefore, err = parseTimeParam(query, "updated_before"); err != nil {
+		return nil, err
+	}
+
+	if filter.AgeFrom != nil && filter.AgeTo != nil && *filter.AgeFrom > *filter.AgeTo {
+		return nil, entity.NewValidationError("age_min", "must not be greater than age_max")
+	}
+	if slices.Contains(filter.NullFields, "age") && (filter.AgeFrom != nil || filter.AgeTo != nil) {
+		return nil, entity.NewValidationError("age", "null can not be combined with an age range")
+	}
+
+	if sort := strings.TrimSpace(query.Get("sort")); sort != "" {
+		filter.SortDesc = strings.HasPrefix(sort, "-")
+		filter.SortBy = strings.TrimPrefix(sort, "-")
+		if !slices.Contains(entity.SortableFields, filter.SortBy) {
+			return nil, entity.NewValidationError("sort", fmt.Sprintf("%q can not be used for sorting", filter.SortBy))
+		}
+	}
+
+	filter.Page = 1
+	if p := query.Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			filter.Page = parsed
+		}
+	}
+	filter.PageSize = 10
+	if ps := query.Get("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 {
+			filter.PageSize = parsed
+		}
+	}
+
+	return filter, nil
+}
+
+func isNullCheck(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return value == nullValue || value == notNullValue
+}
+
+func splitList(value string, normalize func(string) string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		result = append(result, normalize(item))
+	}
+	return result
+}
+
+func parseIntParam(query url.Values, key string) (*int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, entity.NewValidationError(key, "must be an integer")
+	}
+	return &parsed, nil
+}
+
+func parseFloatParam(query url.Values, key string) (*float64, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 || parsed > 1 {
+		return nil, entity.NewValidationError(key, "must be a number between 0 and 1")
+	}
+	return &parsed, nil
+}
+
+func parseTimeParam(query url.Values, key string) (*time.Time, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, nil
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, entity.NewValidationError(key, "expected RFC 3339 timestamp or YYYY-MM-DD date")
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"people-enricher/internal/entity"
+)
+
+func TestParseFilter(t *testing.T) {
+	query, _ := url.ParseQuery("name=iv&gender=Male,%20female&nationality=ru,,ua&patronymic=null&age=!null" +
+		"&age_min=18&age_max=30&nationality_probability_min=0.5&created_after=2025-01-01" +
+		"&updated_before=2025-02-01T10:00:00Z&sort=-age&page=3&page_size=50")
+
+	filter, err := parseFilter(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filter.Name == nil || *filter.Name != "iv" {
+		t.Errorf("name = %v, want iv", filter.Name)
+	}
+	if filter.Patronymic != nil {
+		t.Errorf("patronymic = %q, a null check is no substring", *filter.Patronymic)
+	}
+	if !reflect.DeepEqual(filter.Gender, []string{"male", "female"}) {
+		t.Errorf("gender = %v", filter.Gender)
+	}
+	if !reflect.DeepEqual(filter.Nationality, []string{"RU", "UA"}) {
+		t.Errorf("nationality = %v", filter.Nationality)
+	}
+	if !reflect.DeepEqual(filter.NullFields, []string{"patronymic"}) || !reflect.DeepEqual(filter.NotNullFields, []string{"age"}) {
+		t.Errorf("null fields = %v, not null fields = %v", filter.NullFields, filter.NotNullFields)
+	}
+	if *filter.AgeFrom != 18 || *filter.AgeTo != 30 || *filter.NationalityProbabilityFrom != 0.5 {
+		t.Errorf("ranges = %d..%d, %v", *filter.AgeFrom, *filter.AgeTo, *filter.NationalityProbabilityFrom)
+	}
+	if !filter.CreatedAfter.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) ||
+		!filter.UpdatedBefore.Equal(time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("dates = %v, %v", filter.CreatedAfter, filter.UpdatedBefore)
+	}
+	if filter.SortBy != "age" || !filter.SortDesc {
+		t.Errorf("sort = %s desc %v, want age desc", filter.SortBy, filter.SortDesc)
+	}
+	if filter.Page != 3 || filter.PageSize != 50 {
+		t.Errorf("page = %d size %d, want 3 size 50", filter.Page, filter.PageSize)
+	}
+}
+
+func TestParseFilterDefaults(t *testing.T) {
+	filter, err := parseFilter(url.Values{"page": {"-1"}, "page_size": {"x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.Page != 1 || filter.PageSize != 10 || filter.SortBy != "" {
+		t.Errorf("filter = %+v, want page 1 of 10 unsorted", filter)
+	}
+}
+
+func TestParseFilterRejects(t *testing.T) {
+	tests := []struct {
+		query string
+		field string
+	}{
+		{"age_min=x", "age_min"},
+		{"age_min=40&age_max=30", "age_min"},
+		{"age=null&age_min=18", "age"},
+		{"nationality_probability_max=1.5", "nationality_probability_max"},
+		{"created_before=yesterday", "created_before"},
+		{"sort=password", "sort"},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		_, err := parseFilter(query)
+
+		var validation *entity.ValidationError
+		if !errors.As(err, &validation) {
+			t.Errorf("parseFilter(%s) = %v, want a validation error", tt.query, err)
+			continue
+		}
+		if len(validation.Fields) == 0 || validation.Fields[0].Field != tt.field {
+			t.Errorf("parseFilter(%s) failed on %+v, want %s", tt.query, validation.Fields, tt.field)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Middleware wraps an http.Handler with extra behaviour
+type Middleware func(http.Handler) http.Handler
+
+// Chain applies middleware to h so that the first one listed runs first
+func Chain(h http.Handler, middleware ...Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+// statusRecorder remembers the status code and size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Flush keeps streaming handlers such as the SSE endpoint working
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+type patternKey struct{}
+
+// recordPattern stores the route pattern matched for r where Logging can read
+// it. The mux sets r.Pattern on its own request, middleware in between copies
+// the request with every WithContext.
+func recordPattern(r *http.Request) {
+	if pattern, ok := r.Context().Value(patternKey{}).(*string); ok {
+		*pattern = r.Pattern
+	}
+}
+
+// Logging logs every request with its status and duration
+func Logging(log *logrus.Entry) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w}
+			pattern := new(string)
+
+			next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), patternKey{}, pattern)))
+
+			status := recorder.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			entry := log.WithFields(logrus.Fields{
+				"method":   r.Method,
+				"path":     r.URL.Path,
+				"pattern":  *pattern,
+				"status":   status,
+				"bytes":    recorder.bytes,
+				"duration": time.Since(start),
+				"remote":   r.RemoteAddr,
+			})
+			switch {
+			case status >= http.StatusInternalServerError:
+				entry.Error("Request failed")
+			case status >= http.StatusBadRequest:
+				entry.Warn("Request rejected")
+			default:
+				entry.Info("Request served")
+			}
+		})
+	}
+}
+
+// Recovery turns a panic in a handler into a 500 response instead of a
+// dropped connection
+func Recovery(log *logrus.Entry) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if err := recover(); err != nil {
+					if err == http.ErrAbortHandler {
+						panic(err)
+					}
+					log.WithFields(logrus.Fields{
+						"method": r.Method,
+						"path":   r.URL.Path,
+						"panic":  err,
+						"stack":  string(debug.Stack()),
+					}).Error("Handler panicked")
+					respondWithError(w, http.StatusInternalServerError, "Internal server error")
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
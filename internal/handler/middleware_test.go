@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+type testKey struct{}
+
+func TestLoggingRecordsPatternBehindMiddleware(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	log := logrus.NewEntry(logger)
+
+	// Like Authenticate, copies the request with a new context
+	withValue := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), testKey{}, true)))
+		})
+	}
+
+	router := NewRouter(Handlers{
+		Person:  &PersonHandler{},
+		Swagger: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}, Logging(log), withValue)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+
+	entry := hook.LastEntry()
+	if entry == nil {
+		t.Fatal("request was not logged")
+	}
+	if got := entry.Data["pattern"]; got != "GET /swagger/" {
+		t.Fatalf("pattern = %q, want %q", got, "GET /swagger/")
+	}
+}
@@ -2,7 +2,6 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -28,14 +27,6 @@ func NewPersonHandler(service entity.PersonService, log *logrus.Entry) *PersonHa
 	}
 }
 
-func extractIDFromURL(path string) (int, error) {
-	parts := strings.Split(strings.Trim(path, "/"), "/")
-	if len(parts) < 2 {
-		return 0, errors.New("invalid URL format")
-	}
-	return strconv.Atoi(parts[len(parts)-1])
-}
-
 // Create godoc
 // @Summary Create a new person
 // @Description Create a new person with name, surname and optional patronymic
@@ -43,9 +34,12 @@ func extractIDFromURL(path string) (int, error) {
 // @Accept json
 // @Produce json
 // @Param person body entity.PersonInput true "Person data to create"
+// @Param force query bool false "Create even if duplicates are found"
 // @Success 201 {object} entity.Person
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /persons [post]
 func (h *PersonHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var input entity.PersonInput
@@ -57,12 +51,6 @@ func (h *PersonHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	if input.Name == "" || input.Surname == "" {
-		h.log.Debug("Invalid input: name or surname is empty")
-		respondWithError(w, http.StatusBadRequest, "Name and surname are required")
-		return
-	}
-
 	h.log.WithFields(logrus.Fields{
 		"name":       input.Name,
 		"surname":    input.Surname,
@@ -75,13 +63,22 @@ func (h *PersonHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Patronymic: input.Patronymic,
 	}
 
-	createdPerson, err := h.service.Create(r.Context(), person)
+	ctx := r.Context()
+	if force, _ := strconv.ParseBool(r.URL.Query().Get("force")); force {
+		ctx = entity.WithDuplicatesAllowed(ctx)
+	}
+
+	createdPerson, err := h.service.Create(ctx, person)
 	if err != nil {
-		h.log.WithError(err).Error("Error creating person")
-		respondWithError(w, http.StatusInternalServerError, "Error creating person")
+		respondWithServiceError(w, r, h.log, err, "Error creating person")
 		return
 	}
 
+	if len(createdPerson.PossibleDuplicates) > 0 {
+		w.Header().Add("Warning", `299 - "Possible duplicate person"`)
+	}
+	warnEnrichmentSkipped(w, createdPerson)
+
 	h.log.WithField("id", createdPerson.ID).Info("Person created successfully")
 	respondWithJSON(w, http.StatusCreated, createdPerson)
 }
@@ -94,15 +91,15 @@ func (h *PersonHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Param id path int true "Person ID"
 // @Param person body entity.Person true "Person data to update"
 // @Success 200 {object} entity.Person
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /persons/{id} [put]
 func (h *PersonHandler) Update(w http.ResponseWriter, r *http.Request) {
-	id, err := extractIDFromURL(r.URL.Path)
+	id, err := pathID(r)
 	if err != nil {
-		h.log.WithError(err).Debug("Invalid ID parameter")
-		respondWithError(w, http.StatusBadRequest, "Invalid person ID")
+		respondWithServiceError(w, r, h.log, err, "Invalid person ID")
 		return
 	}
 
@@ -114,12 +111,6 @@ func (h *PersonHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	if input.Name == "" || input.Surname == "" {
-		h.log.Debug("Invalid input: name or surname is empty")
-		respondWithError(w, http.StatusBadRequest, "Name and surname are required")
-		return
-	}
-
 	h.log.WithFields(logrus.Fields{
 		"id":         id,
 		"name":       input.Name,
@@ -127,16 +118,14 @@ func (h *PersonHandler) Update(w http.ResponseWriter, r *http.Request) {
 		"patronymic": input.Patronymic,
 	}).Debug("Updating person")
 
+	input.ID = id
 	person, err := h.service.Update(r.Context(), &input)
 	if err != nil {
-		h.log.WithFields(logrus.Fields{
-			"id":    id,
-			"error": err,
-		}).Error("Error updating person")
-		respondWithError(w, http.StatusInternalServerError, "Error updating person")
+		respondWithServiceError(w, r, h.log.WithField("id", id), err, "Error updating person")
 		return
 	}
 
+	warnEnrichmentSkipped(w, person)
 	h.log.WithField("id", id).Info("Person updated successfully")
 	respondWithJSON(w, http.StatusOK, person)
 }
@@ -149,26 +138,22 @@ func (h *PersonHandler) Update(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path int true "Person ID"
 // @Success 204
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /persons/{id} [delete]
 func (h *PersonHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	id, err := extractIDFromURL(r.URL.Path)
+	id, err := pathID(r)
 	if err != nil {
-		h.log.WithError(err).Debug("Invalid ID parameter")
-		respondWithError(w, http.StatusBadRequest, "Invalid person ID")
+		respondWithServiceError(w, r, h.log, err, "Invalid person ID")
 		return
 	}
 
 	h.log.WithField("id", id).Debug("Deleting person")
 
-	if err := h.service.Delete(r.Context(), int64(id)); err != nil {
-		h.log.WithFields(logrus.Fields{
-			"id":    id,
-			"error": err,
-		}).Error("Error deleting person")
-		respondWithError(w, http.StatusInternalServerError, "Error deleting person")
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		respondWithServiceError(w, r, h.log.WithField("id", id), err, "Error deleting person")
 		return
 	}
 
@@ -184,11 +169,19 @@ func (h *PersonHandler) Delete(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path int true "Person ID"
 // @Success 200 {object} entity.Person
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Success 301 {string} string "Person was merged, Location points to the kept person"
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /persons/{id} [get]
-func (h *PersonHandler) GetByID(ctx context.Context, id int64) (*entity.Person, error) {
+func (h *PersonHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Invalid person ID")
+		return
+	}
+
 	logger := h.log.WithFields(logrus.Fields{
 		"operation": "GetByID",
 		"person_id": id,
@@ -196,18 +189,20 @@ func (h *PersonHandler) GetByID(ctx context.Context, id int64) (*entity.Person,
 
 	logger.Debug("Fetching person by ID")
 
-	person, err := h.service.GetById(ctx, id)
+	person, err := h.service.GetById(r.Context(), id)
 	if err != nil {
-		if err.Error() == "person not found" {
-			logger.Warn("Person not found")
-			return nil, fmt.Errorf("person not found with id %d", id)
+		var mergedErr *entity.MergedError
+		if errors.As(err, &mergedErr) {
+			logger.WithField("target_id", mergedErr.TargetID).Info("Person was merged, redirecting")
+			http.Redirect(w, r, fmt.Sprintf("/persons/%d", mergedErr.TargetID), http.StatusMovedPermanently)
+			return
 		}
-		logger.Error("Error fetching person:", err)
-		return nil, fmt.Errorf("error fetching person with id %d: %w", id, err)
+		respondWithServiceError(w, r, logger, err, "Error fetching person")
+		return
 	}
 
 	logger.Info("Successfully fetched person")
-	return person, nil
+	respondWithJSON(w, http.StatusOK, person)
 }
 
 // List godoc
@@ -216,87 +211,193 @@ func (h *PersonHandler) GetByID(ctx context.Context, id int64) (*entity.Person,
 // @Tags persons
 // @Accept json
 // @Produce json
-// @Param name query string false "Filter by name"
-// @Param surname query string false "Filter by surname"
-// @Param patronymic query string false "Filter by patronymic"
+// @Param name query string false "Filter by name, matches the original or the Latin transliteration"
+// @Param surname query string false "Filter by surname, matches the original or the Latin transliteration"
+// @Param patronymic query string false "Filter by patronymic, or null / !null"
+// @Param gender query string false "Comma separated genders, or null / !null"
+// @Param nationality query string false "Comma separated country codes (RU,KZ), or null / !null"
+// @Param age query string false "null / !null"
 // @Param age_min query int false "Minimum age filter"
 // @Param age_max query int false "Maximum age filter"
+// @Param nationality_probability_min query number false "Minimum nationality probability"
+// @Param nationality_probability_max query number false "Maximum nationality probability"
+// @Param created_after query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_after query string false "Updated at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param updated_before query string false "Updated before (RFC 3339 or YYYY-MM-DD)"
+// @Param sort query string false "Sort field (id, name, surname, age, nationality_probability, created_at, updated_at), prefix with - for descending"
 // @Param page query int false "Page number (default 1)"
 // @Param page_size query int false "Items per page (default 10)"
 // @Success 200 {object} PaginatedResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /persons [get]
 func (h *PersonHandler) List(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query()
-
-	filter := &entity.PersonFilter{}
-
-	if name := query.Get("name"); name != "" {
-		filter.Name = &name
-	}
-	if surname := query.Get("surname"); surname != "" {
-		filter.Surname = &surname
-	}
-	if patronymic := query.Get("patronymic"); patronymic != "" {
-		filter.Patronymic = &patronymic
-	}
-
-	if ageMinStr := query.Get("age_min"); ageMinStr != "" {
-		if ageMin, err := strconv.Atoi(ageMinStr); err == nil {
-			filter.AgeFrom = &ageMin
-		}
-	}
-	if ageMaxStr := query.Get("age_max"); ageMaxStr != "" {
-		if ageMax, err := strconv.Atoi(ageMaxStr); err == nil {
-			filter.AgeTo = &ageMax
-		}
-	}
-	page := 1
-	if p := query.Get("page"); p != "" {
-		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
-			page = parsed
-		}
-	}
-	pageSize := 10
-	if ps := query.Get("page_size"); ps != "" {
-		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 {
-			pageSize = parsed
-		}
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Invalid filter parameters")
+		return
 	}
-	filter.Page = page
-	filter.PageSize = pageSize
 
 	h.log.WithFields(logrus.Fields{
-		"name":       filter.Name,
-		"surname":    filter.Surname,
-		"patronymic": filter.Patronymic,
-		"age_min":    filter.AgeFrom,
-		"age_max":    filter.AgeTo,
-		"page":       filter.Page,
-		"page_size":  filter.PageSize,
+		"name":            filter.Name,
+		"surname":         filter.Surname,
+		"patronymic":      filter.Patronymic,
+		"gender":          filter.Gender,
+		"nationality":     filter.Nationality,
+		"age_min":         filter.AgeFrom,
+		"age_max":         filter.AgeTo,
+		"null_fields":     filter.NullFields,
+		"not_null_fields": filter.NotNullFields,
+		"page":            filter.Page,
+		"page_size":       filter.PageSize,
 	}).Debug("Listing persons with filter")
 
 	persons, total, err := h.service.List(r.Context(), filter)
 	if err != nil {
-		h.log.WithError(err).Error("Error listing persons")
-		respondWithError(w, http.StatusInternalServerError, "Error listing persons")
+		respondWithServiceError(w, r, h.log, err, "Error listing persons")
 		return
 	}
 
-	totalPages := (total + pageSize - 1) / pageSize
+	totalPages := (total + filter.PageSize - 1) / filter.PageSize
 
 	response := PaginatedResponse{
 		Data:       toFlatList(persons),
 		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
 		TotalPages: totalPages,
 	}
 
 	respondWithJSON(w, http.StatusOK, response)
 }
 
+// Stats godoc
+// @Summary Aggregated statistics about persons
+// @Description Count persons by gender, nationality and age bucket and report how many records have each field populated. Accepts the same filters as the list endpoint
+// @Tags persons
+// @Produce json
+// @Param age_buckets query string false "Ascending comma separated age bucket boundaries (default 18,25,35,45,55,65)"
+// @Param name query string false "Filter by name, matches the original or the Latin transliteration"
+// @Param surname query string false "Filter by surname, matches the original or the Latin transliteration"
+// @Param gender query string false "Comma separated genders, or null / !null"
+// @Param nationality query string false "Comma separated country codes, or null / !null"
+// @Param age_min query int false "Minimum age filter"
+// @Param age_max query int false "Maximum age filter"
+// @Param created_after query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Success 200 {object} entity.PersonStats
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /persons/stats [get]
+func (h *PersonHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := parseFilter(query)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Invalid filter parameters")
+		return
+	}
+
+	// The service checks the bounds, only the syntax is checked here
+	var ageBuckets []int
+	if value := query.Get("age_buckets"); value != "" {
+		for _, item := range splitList(value, strings.TrimSpace) {
+			bound, err := strconv.Atoi(item)
+			if err != nil {
+				respondWithServiceError(w, r, h.log, entity.NewValidationError("age_buckets", "must be a list of non-negative integers"), "Invalid age buckets")
+				return
+			}
+			ageBuckets = append(ageBuckets, bound)
+		}
+	}
+
+	stats, err := h.service.Stats(r.Context(), filter, ageBuckets)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error collecting stats")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, stats)
+}
+
+// Merge godoc
+// @Summary Merge two persons
+// @Description Merge the source person into the target one. Empty target fields are filled from the source, the source is removed and its ID redirects to the target
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param merge body entity.MergeInput true "Persons to merge"
+// @Success 200 {object} entity.Person
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /persons/merge [post]
+func (h *PersonHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	var input entity.MergeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.log.WithError(err).Debug("Error decoding request body")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	merged, err := h.service.Merge(r.Context(), input.SourceID, input.TargetID)
+	if err != nil {
+		respondWithServiceError(w, r, h.log.WithFields(logrus.Fields{
+			"source_id": input.SourceID,
+			"target_id": input.TargetID,
+		}), err, "Error merging persons")
+		return
+	}
+
+	h.log.WithField("id", merged.ID).Info("Persons merged successfully")
+	respondWithJSON(w, http.StatusOK, merged)
+}
+
+// PreviewEnrichment godoc
+// @Summary Preview enrichment of a name
+// @Description Run the enrichment pipeline for a name without storing anything. Returns the resolved fields and what every provider answered, answers reused from the enrichment cache are marked cached. Needs the editor role and counts against the daily enrichment quota of the tenant
+// @Tags persons
+// @Produce json
+// @Param name query string true "Name"
+// @Param surname query string true "Surname"
+// @Param patronymic query string false "Patronymic"
+// @Success 200 {object} entity.EnrichmentPreview
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 429 {object} Problem "Daily enrichment quota of the tenant used up"
+// @Failure 500 {object} Problem
+// @Router /enrich [get]
+func (h *PersonHandler) PreviewEnrichment(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	person := &entity.Person{
+		Name:    query.Get("name"),
+		Surname: query.Get("surname"),
+	}
+	if query.Has("patronymic") {
+		patronymic := query.Get("patronymic")
+		person.Patronymic = &patronymic
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"name":       person.Name,
+		"surname":    person.Surname,
+		"patronymic": person.Patronymic,
+	}).Debug("Previewing enrichment")
+
+	preview, err := h.service.PreviewEnrichment(r.Context(), person)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error previewing enrichment")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, preview)
+}
+
 // PaginatedResponse represents a paginated response
 type PaginatedResponse struct {
 	Data       []entity.Person `json:"data"`
@@ -306,15 +407,6 @@ type PaginatedResponse struct {
 	TotalPages int             `json:"total_pages"`
 }
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
-
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, ErrorResponse{Error: message})
-}
-
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, err := json.Marshal(payload)
 	if err != nil {
@@ -328,6 +420,17 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.Write(response)
 }
 
+// warnEnrichmentSkipped adds a Warning header when the person was stored
+// without enrichment
+func warnEnrichmentSkipped(w http.ResponseWriter, person *entity.Person) {
+	switch person.EnrichmentSkipped {
+	case entity.EnrichmentSkippedQuota:
+		w.Header().Add("Warning", `299 - "Enrichment skipped, daily quota exceeded"`)
+	case entity.EnrichmentSkippedFailed:
+		w.Header().Add("Warning", `299 - "Enrichment failed"`)
+	}
+}
+
 func toFlatList(persons []*entity.Person) []entity.Person {
 	result := make([]entity.Person, len(persons))
 	for i, p := range persons {
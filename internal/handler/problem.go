@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"people-enricher/internal/entity"
+
+	"github.com/sirupsen/logrus"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem types, relative URIs identifying each kind of error
+const (
+	problemValidation   = "/problems/validation"
+	problemNotFound     = "/problems/not-found"
+	problemConflict     = "/problems/conflict"
+	problemDuplicate    = "/problems/duplicate-person"
+	problemCanceled     = "/problems/canceled"
+	problemUnauthorized = "/problems/unauthorized"
+	problemForbidden    = "/problems/forbidden"
+	problemQuota        = "/problems/quota-exceeded"
+	problemRateLimited  = "/problems/rate-limited"
+	problemInternal     = "/problems/internal"
+)
+
+// Problem is an RFC 7807 problem details response
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Errors lists the rejected fields of a validation problem
+	Errors []entity.FieldError `json:"errors,omitempty"`
+	// Candidates lists the similar persons of a duplicate-person problem
+	Candidates []entity.DuplicateCandidate `json:"candidates,omitempty"`
+}
+
+// problemFor maps a service error to a problem. Errors of an unknown kind
+// become a 500 carrying only message, so internals do not leak.
+func problemFor(err error, message string) Problem {
+	var duplicateErr *entity.DuplicateError
+	var validationErr *entity.ValidationError
+	var notFoundErr *entity.NotFoundError
+	var forbiddenErr *entity.ForbiddenError
+
+	switch {
+	case errors.As(err, &validationErr):
+		return Problem{
+			Type:   problemValidation,
+			Title:  "Invalid input",
+			Status: http.StatusBadRequest,
+			Detail: "One or more fields are invalid",
+			Errors: validationErr.Fields,
+		}
+	case errors.Is(err, entity.ErrValidation):
+		return Problem{Type: problemValidation, Title: "Invalid input", Status: http.StatusBadRequest, Detail: err.Error()}
+	case errors.As(err, &duplicateErr):
+		return Problem{
+			Type:       problemDuplicate,
+			Title:      "Possible duplicate person",
+			Status:     http.StatusConflict,
+			Detail:     "Person with the same or a similar name already exists, retry with force=true to create it anyway",
+			Candidates: duplicateErr.Candidates,
+		}
+	case errors.Is(err, entity.ErrConflict):
+		return Problem{Type: problemConflict, Title: "Conflict", Status: http.StatusConflict, Detail: err.Error()}
+	case errors.As(err, &notFoundErr):
+		return Problem{Type: problemNotFound, Title: "Not found", Status: http.StatusNotFound, Detail: notFoundErr.Error()}
+	case errors.Is(err, entity.ErrNotFound):
+		return Problem{Type: problemNotFound, Title: "Not found", Status: http.StatusNotFound, Detail: err.Error()}
+	case errors.Is(err, entity.ErrUnauthorized):
+		// The reason stays in the logs, callers only learn the credentials failed
+		return Problem{Type: problemUnauthorized, Title: "Unauthorized", Status: http.StatusUnauthorized, Detail: "Missing, invalid or revoked credentials"}
+	case errors.As(err, &forbiddenErr):
+		return Problem{Type: problemForbidden, Title: "Forbidden", Status: http.StatusForbidden, Detail: forbiddenErr.Error()}
+	case errors.Is(err, entity.ErrForbidden):
+		return Problem{Type: problemForbidden, Title: "Forbidden", Status: http.StatusForbidden, Detail: err.Error()}
+	case errors.Is(err, entity.ErrQuota):
+		return Problem{Type: problemQuota, Title: "Quota exceeded", Status: http.StatusTooManyRequests, Detail: err.Error()}
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return Problem{Type: problemCanceled, Title: "Request canceled", Status: http.StatusServiceUnavailable, Detail: err.Error()}
+	}
+	return Problem{Type: problemInternal, Title: "Internal server error", Status: http.StatusInternalServerError, Detail: message}
+}
+
+// respondWithServiceError writes err as a problem. Server side failures are
+// logged with message, client errors only at debug level.
+func respondWithServiceError(w http.ResponseWriter, r *http.Request, log *logrus.Entry, err error, message string) {
+	problem := problemFor(err, message)
+	problem.Instance = r.URL.Path
+
+	logger := log.WithError(err).WithField("status", problem.Status)
+	if problem.Status >= http.StatusInternalServerError {
+		logger.Error(message)
+	} else {
+		logger.Debug(message)
+	}
+	respondWithProblem(w, problem)
+}
+
+// respondWithError writes a problem without a specific type
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	respondWithProblem(w, Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(code),
+		Status: code,
+		Detail: message,
+	})
+}
+
+func respondWithProblem(w http.ResponseWriter, problem Problem) {
+	response, err := json.Marshal(problem)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal problem response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+	w.Write(response)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"people-enricher/internal/entity"
+	"people-enricher/internal/ratelimit"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimits are the per client buckets of the HTTP API
+type RateLimits struct {
+	Read  ratelimit.Bucket
+	Write ratelimit.Bucket
+	// IP limits every request of an address before its credentials are
+	// checked
+	IP ratelimit.Bucket
+	// TrustedProxies may set X-Forwarded-For
+	TrustedProxies []*net.IPNet
+	// IsMutation reports whether a GraphQL request changes data, the others
+	// count as reads. All of them are writes when it is nil.
+	IsMutation func(r *http.Request) bool
+}
+
+// RateLimitIP takes a token from the bucket of the client IP address for
+// every request, rejecting it with 429 when the bucket is empty. It runs
+// before Authenticate, so guessing credentials and the key lookups it causes
+// are limited too.
+func RateLimitIP(store ratelimit.Store, limits RateLimits, log *logrus.Entry) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if take(w, r, store, "http:ip:"+clientIP(r, limits.TrustedProxies), "", limits.IP, log) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// RateLimit takes a token from the read or write bucket of the client for
+// every request, rejecting it with 429 when the bucket is empty. Responses
+// carry RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers.
+// It runs after Authenticate, so authenticated clients are limited by their
+// principal wherever they connect from. Failures of the store let requests
+// through, an outage of the limiter does not take the API down.
+func RateLimit(store ratelimit.Store, limits RateLimits, log *logrus.Entry) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			class, bucket := "read", limits.Read
+			if isWrite(r, limits.IsMutation) {
+				class, bucket = "write", limits.Write
+			}
+			key := "http:" + class + ":" + rateLimitClient(r, limits.TrustedProxies)
+			if take(w, r, store, key, class+" ", bucket, log) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// take takes a token from the bucket of key and sets the rate limit headers,
+// responding with 429 and returning false when there is none. class names
+// the limit in the problem detail.
+func take(w http.ResponseWriter, r *http.Request, store ratelimit.Store, key, class string, bucket ratelimit.Bucket, log *logrus.Entry) bool {
+	state, delay, ok, err := store.Take(r.Context(), key, bucket, 0)
+	if err != nil {
+		log.WithError(err).WithField("key", key).Error("Error taking rate limit token, letting request through")
+		return true
+	}
+
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(bucket.Burst))
+	if !ok {
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", seconds(delay))
+		w.Header().Set("Retry-After", seconds(delay))
+		log.WithFields(logrus.Fields{"key": key, "path": r.URL.Path}).Debug("Request rate limited")
+		respondWithProblem(w, Problem{
+			Type:     problemRateLimited,
+			Title:    "Too many requests",
+			Status:   http.StatusTooManyRequests,
+			Detail:   "Rate limit of " + class + "requests exceeded, retry in " + seconds(delay) + "s",
+			Instance: r.URL.Path,
+		})
+		return false
+	}
+
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(int(math.Max(state.Tokens, 0))))
+	w.Header().Set("RateLimit-Reset", seconds(time.Until(bucket.Full(state))))
+	return true
+}
+
+// isWrite reports whether a request changes data or asks the enrichment
+// providers, which the write bucket guards. GraphQL queries are reads
+// whatever their method.
+func isWrite(r *http.Request, isMutation func(r *http.Request) bool) bool {
+	if r.URL.Path == "/graphql" && isMutation != nil {
+		return isMutation(r)
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return r.URL.Path == "/enrich"
+	}
+	return true
+}
+
+// rateLimitClient identifies the client of a request: its principal when
+// authenticated, its IP address otherwise
+func rateLimitClient(r *http.Request, trusted []*net.IPNet) string {
+	if principal := entity.PrincipalFrom(r.Context()); principal != nil && principal.Method != entity.AuthNone {
+		return principal.Subject
+	}
+	return "ip:" + clientIP(r, trusted)
+}
+
+// clientIP returns the address the request came from. X-Forwarded-For is
+// walked from the right while the hops are trusted proxies, so clients can
+// not pick their address by sending the header themselves.
+func clientIP(r *http.Request, trusted []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0 && isTrusted(host, trusted); i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		host = hop
+	}
+	return host
+}
+
+func isTrusted(host string, trusted []*net.IPNet) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// seconds formats d as whole seconds, rounded up
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(max(d, 0).Seconds())))
+}
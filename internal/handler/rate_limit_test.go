@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"people-enricher/internal/entity"
+	"people-enricher/internal/ratelimit"
+
+	"github.com/sirupsen/logrus"
+)
+
+// countingAuthenticator rejects every credential and counts the lookups
+type countingAuthenticator struct {
+	calls int
+}
+
+func (a *countingAuthenticator) Authenticate(ctx context.Context, apiKey, bearer string) (*entity.Principal, error) {
+	a.calls++
+	return nil, entity.ErrUnauthorized
+}
+
+func TestRateLimitIPRunsBeforeAuthentication(t *testing.T) {
+	log := logrus.NewEntry(logrus.New())
+	store := ratelimit.NewMemory()
+	limits := RateLimits{
+		Read:  ratelimit.Bucket{Rate: 1, Burst: 100},
+		Write: ratelimit.Bucket{Rate: 1, Burst: 100},
+		IP:    ratelimit.Bucket{Rate: 0.001, Burst: 3},
+	}
+	authenticator := &countingAuthenticator{}
+	h := Chain(http.NotFoundHandler(),
+		RateLimitIP(store, limits, log), Authenticate(authenticator, log), RateLimit(store, limits, log))
+
+	var statuses []int
+	for range 5 {
+		r := httptest.NewRequest(http.MethodGet, "/persons", nil)
+		r.Header.Set("X-API-Key", "pe_guessed")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		statuses = append(statuses, w.Code)
+	}
+
+	want := []int{401, 401, 401, 429, 429}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("statuses = %v, want %v", statuses, want)
+		}
+	}
+	if authenticator.calls != 3 {
+		t.Errorf("credentials looked up %d times, want 3", authenticator.calls)
+	}
+}
+
+func TestIsWrite(t *testing.T) {
+	isMutation := func(r *http.Request) bool { return r.Header.Get("X-Mutation") != "" }
+	tests := []struct {
+		method   string
+		path     string
+		mutation bool
+		want     bool
+	}{
+		{http.MethodGet, "/persons", false, false},
+		{http.MethodHead, "/persons", false, false},
+		{http.MethodGet, "/enrich", false, true},
+		{http.MethodPost, "/persons", false, true},
+		{http.MethodPatch, "/persons/1", false, true},
+		{http.MethodDelete, "/persons/1", false, true},
+		{http.MethodPost, "/graphql", false, false},
+		{http.MethodPost, "/graphql", true, true},
+		{http.MethodGet, "/graphql", false, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.mutation {
+			r.Header.Set("X-Mutation", "1")
+		}
+		if got := isWrite(r, isMutation); got != tt.want {
+			t.Errorf("isWrite(%s %s, mutation %v) = %v, want %v", tt.method, tt.path, tt.mutation, got, tt.want)
+		}
+	}
+
+	// Without a classifier GraphQL POSTs stay writes
+	if !isWrite(httptest.NewRequest(http.MethodPost, "/graphql", nil), nil) {
+		t.Error("GraphQL POST without a classifier is not a write")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := []*net.IPNet{proxies}
+	tests := []struct {
+		remote    string
+		forwarded string
+		want      string
+	}{
+		{"192.0.2.1:1234", "", "192.0.2.1"},
+		{"192.0.2.1:1234", "203.0.113.9", "192.0.2.1"},
+		{"10.0.0.1:1234", "203.0.113.9", "203.0.113.9"},
+		{"10.0.0.1:1234", "198.51.100.7, 203.0.113.9", "203.0.113.9"},
+		{"10.0.0.1:1234", "198.51.100.7, 10.0.0.2", "198.51.100.7"},
+		{"10.0.0.1:1234", "garbage", "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/persons", nil)
+		r.RemoteAddr = tt.remote
+		if tt.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		if got := clientIP(r, trusted); got != tt.want {
+			t.Errorf("clientIP(%s, %q) = %s, want %s", tt.remote, tt.forwarded, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitClient(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/persons", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	if got := rateLimitClient(r, nil); got != "ip:192.0.2.1" {
+		t.Errorf("unauthenticated client = %s, want ip:192.0.2.1", got)
+	}
+
+	authenticated := r.WithContext(entity.WithPrincipal(r.Context(), &entity.Principal{Subject: "api_key:7", Method: entity.AuthAPIKey}))
+	if got := rateLimitClient(authenticated, nil); got != "api_key:7" {
+		t.Errorf("authenticated client = %s, want api_key:7", got)
+	}
+
+	anonymous := r.WithContext(entity.WithPrincipal(r.Context(), &entity.Principal{Subject: "anonymous", Method: entity.AuthNone}))
+	if got := rateLimitClient(anonymous, nil); got != "ip:192.0.2.1" {
+		t.Errorf("client with authentication off = %s, want ip:192.0.2.1", got)
+	}
+}
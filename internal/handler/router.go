@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+	"people-enricher/internal/entity"
+	"strconv"
+)
+
+// Handlers groups everything served by the HTTP API. Nil optional handlers
+// are not registered.
+type Handlers struct {
+	Person  *PersonHandler
+	Webhook *WebhookHandler
+	Events  *EventsHandler
+	APIKeys *APIKeyHandler
+	Tenants *TenantHandler
+	GraphQL http.Handler
+	Swagger http.Handler
+}
+
+// NewRouter registers the API routes and wraps them in the middleware, the
+// first middleware listed being the outermost one
+func NewRouter(h Handlers, middleware ...Middleware) http.Handler {
+	mux := http.NewServeMux()
+
+	if h.Swagger != nil {
+		mux.Handle("GET /swagger/", h.Swagger)
+	}
+	if h.GraphQL != nil {
+		mux.Handle("/graphql", h.GraphQL)
+	}
+
+	mux.HandleFunc("POST /persons", h.Person.Create)
+	mux.HandleFunc("GET /persons", h.Person.List)
+	mux.HandleFunc("GET /persons/stats", h.Person.Stats)
+	mux.HandleFunc("POST /persons/merge", h.Person.Merge)
+	mux.HandleFunc("GET /persons/{id}", h.Person.GetByID)
+	mux.HandleFunc("PUT /persons/{id}", h.Person.Update)
+	mux.HandleFunc("DELETE /persons/{id}", h.Person.Delete)
+	mux.HandleFunc("GET /enrich", h.Person.PreviewEnrichment)
+
+	if h.Events != nil {
+		mux.HandleFunc("GET /persons/events", h.Events.Stream)
+	}
+
+	if h.Webhook != nil {
+		mux.HandleFunc("POST /webhooks", h.Webhook.Create)
+		mux.HandleFunc("GET /webhooks", h.Webhook.List)
+		mux.HandleFunc("GET /webhooks/{id}", h.Webhook.Get)
+		mux.HandleFunc("PUT /webhooks/{id}", h.Webhook.Update)
+		mux.HandleFunc("DELETE /webhooks/{id}", h.Webhook.Delete)
+		mux.HandleFunc("GET /webhooks/{id}/deliveries", h.Webhook.Deliveries)
+	}
+
+	if h.APIKeys != nil {
+		mux.HandleFunc("POST /admin/api-keys", h.APIKeys.Create)
+		mux.HandleFunc("GET /admin/api-keys", h.APIKeys.List)
+		mux.HandleFunc("DELETE /admin/api-keys/{id}", h.APIKeys.Revoke)
+	}
+
+	if h.Tenants != nil {
+		mux.HandleFunc("GET /admin/tenants", h.Tenants.List)
+		mux.HandleFunc("GET /admin/tenants/{id}", h.Tenants.Get)
+		mux.HandleFunc("PUT /admin/tenants/{id}", h.Tenants.Put)
+	}
+
+	routed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer recordPattern(r)
+		mux.ServeHTTP(w, r)
+	})
+	return Chain(routed, middleware...)
+}
+
+// pathID parses the {id} path parameter as a positive integer
+func pathID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, entity.NewValidationError("id", "must be a positive integer")
+	}
+	return id, nil
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"people-enricher/internal/tenant"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TenantResolver picks the tenant of a request
+type TenantResolver interface {
+	Resolve(ctx context.Context, requested string) (context.Context, error)
+}
+
+// ResolveTenant attaches the tenant of the principal or of the X-Tenant-ID
+// header to the request context. It runs after Authenticate.
+func ResolveTenant(resolver TenantResolver, log *logrus.Entry) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range publicPaths {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			ctx, err := resolver.Resolve(r.Context(), strings.TrimSpace(r.Header.Get(tenant.Header)))
+			if err != nil {
+				respondWithServiceError(w, r, log, err, "Error resolving tenant")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
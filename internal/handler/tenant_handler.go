@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"people-enricher/internal/entity"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TenantHandler handles the admin endpoints managing tenants
+type TenantHandler struct {
+	service entity.TenantService
+	log     *logrus.Entry
+}
+
+// NewTenantHandler creates a new TenantHandler
+func NewTenantHandler(service entity.TenantService, log *logrus.Entry) *TenantHandler {
+	return &TenantHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// List godoc
+// @Summary List tenants
+// @Description Only credentials not bound to a tenant, like the bootstrap key, manage tenants
+// @Tags admin
+// @Produce json
+// @Success 200 {array} entity.Tenant
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/tenants [get]
+func (h *TenantHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.service.List(r.Context())
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error listing tenants")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, tenants)
+}
+
+// Get godoc
+// @Summary Get a tenant
+// @Tags admin
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} entity.Tenant
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/tenants/{id} [get]
+func (h *TenantHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenant, err := h.service.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error fetching tenant")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, tenant)
+}
+
+// Put godoc
+// @Summary Create or update a tenant
+// @Description Set the name, enrichment settings and daily enrichment quota of a tenant, creating it when missing
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Param tenant body entity.TenantInput true "Tenant settings"
+// @Success 200 {object} entity.Tenant
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/tenants/{id} [put]
+func (h *TenantHandler) Put(w http.ResponseWriter, r *http.Request) {
+	var input entity.TenantInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.log.WithError(err).Debug("Error decoding request body")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	tenant, err := h.service.Put(r.Context(), r.PathValue("id"), &input)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error storing tenant")
+		return
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"id":         tenant.ID,
+		"updated_by": principalSubject(r),
+	}).Info("Tenant stored")
+	respondWithJSON(w, http.StatusOK, tenant)
+}
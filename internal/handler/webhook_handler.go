@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"people-enricher/internal/entity"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions
+type WebhookHandler struct {
+	service entity.WebhookService
+	log     *logrus.Entry
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(service entity.WebhookService, log *logrus.Entry) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// Create godoc
+// @Summary Create a webhook
+// @Description Subscribe a URL to person events. Payloads are signed with HMAC-SHA256, the secret is only returned in this response
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body entity.WebhookInput true "Webhook subscription"
+// @Success 201 {object} entity.Webhook
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /webhooks [post]
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input entity.WebhookInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.log.WithError(err).Debug("Error decoding request body")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	webhook, err := h.service.Create(r.Context(), &input)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error creating webhook")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, webhook)
+}
+
+// List godoc
+// @Summary List webhooks
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} entity.Webhook
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /webhooks [get]
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.List(r.Context())
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error listing webhooks")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, webhooks)
+}
+
+// Get godoc
+// @Summary Get a webhook
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} entity.Webhook
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Invalid webhook ID")
+		return
+	}
+
+	webhook, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error fetching webhook")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, webhook)
+}
+
+// Update godoc
+// @Summary Update a webhook
+// @Description Replace the webhook settings. An empty secret keeps the current one
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param webhook body entity.WebhookInput true "Webhook subscription"
+// @Success 200 {object} entity.Webhook
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Invalid webhook ID")
+		return
+	}
+
+	var input entity.WebhookInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.log.WithError(err).Debug("Error decoding request body")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	webhook, err := h.service.Update(r.Context(), id, &input)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error updating webhook")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, webhook)
+}
+
+// Delete godoc
+// @Summary Delete a webhook
+// @Tags webhooks
+// @Param id path int true "Webhook ID"
+// @Success 204
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Invalid webhook ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error deleting webhook")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries godoc
+// @Summary List webhook deliveries
+// @Description Delivery log of a webhook, newest first
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param status query string false "pending, succeeded, failed or dead"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Items per page (default 20)"
+// @Success 200 {object} DeliveriesResponse
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Invalid webhook ID")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &entity.DeliveryFilter{Page: 1, PageSize: 20}
+
+	if status := query.Get("status"); status != "" {
+		switch status {
+		case entity.DeliveryPending, entity.DeliverySucceeded, entity.DeliveryFailed, entity.DeliveryDead:
+			filter.Status = &status
+		default:
+			respondWithServiceError(w, r, h.log, entity.NewValidationError("status", "must be pending, succeeded, failed or dead"), "Invalid delivery status")
+			return
+		}
+	}
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		filter.Page = p
+	}
+	if ps, err := strconv.Atoi(query.Get("page_size")); err == nil && ps > 0 {
+		filter.PageSize = ps
+	}
+
+	deliveries, total, err := h.service.ListDeliveries(r.Context(), id, filter)
+	if err != nil {
+		respondWithServiceError(w, r, h.log, err, "Error listing deliveries")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, DeliveriesResponse{
+		Data:       deliveries,
+		Total:      total,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+		TotalPages: (total + filter.PageSize - 1) / filter.PageSize,
+	})
+}
+
+// DeliveriesResponse represents a page of webhook deliveries
+type DeliveriesResponse struct {
+	Data       []*entity.WebhookDelivery `json:"data"`
+	Total      int                       `json:"total"`
+	Page       int                       `json:"page"`
+	PageSize   int                       `json:"page_size"`
+	TotalPages int                       `json:"total_pages"`
+}
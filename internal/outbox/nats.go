@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"people-enricher/internal/entity"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+// NATSSink publishes events to <subject>.<event type>, e.g. people.events.person.created.
+// Publishing is followed by a flush so that an error is reported before the
+// event is marked as published.
+type NATSSink struct {
+	conn    *nats.Conn
+	subject string
+}
+
+func NewNATSSink(url, subject string, log *logrus.Entry) (*NATSSink, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("people-enricher"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			log.WithError(err).Warn("Disconnected from NATS")
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to NATS: %w", err)
+	}
+	return &NATSSink{conn: conn, subject: subject}, nil
+}
+
+func (s *NATSSink) Publish(ctx context.Context, event entity.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+
+	msg := nats.NewMsg(s.subject + "." + event.Type)
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, strconv.FormatInt(event.ID, 10))
+	msg.Header.Set("Person-Id", strconv.FormatInt(event.PersonID, 10))
+
+	if err := s.conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("publishing to NATS: %w", err)
+	}
+	if err := s.conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("flushing NATS connection: %w", err)
+	}
+	return nil
+}
+
+func (s *NATSSink) Close() error {
+	return s.conn.Drain()
+}
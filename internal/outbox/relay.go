@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"people-enricher/internal/config"
+	"people-enricher/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Store gives the relay access to unpublished outbox events
+type Store interface {
+	ProcessBatch(ctx context.Context, limit, maxAttempts int, publish func(events []entity.Event) map[int64]error) (int, bool, error)
+}
+
+// Relay moves events from the outbox table to a Sink. Delivery is at least
+// once: an event is marked as published only after the sink accepted it.
+// Events of one person are published in order, once an event fails all later
+// events of the same person wait for the next round. An event failing
+// maxAttempts times is marked failed, so it stops holding the later ones back.
+type Relay struct {
+	store       Store
+	sink        Sink
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	log         *logrus.Entry
+}
+
+func NewRelay(store Store, sink Sink, cfg config.OutboxCfg, log *logrus.Entry) *Relay {
+	return &Relay{
+		store:       store,
+		sink:        sink,
+		interval:    cfg.PollInterval,
+		batchSize:   cfg.BatchSize,
+		maxAttempts: cfg.MaxAttempts,
+		log:         log.WithField("component", "outbox_relay"),
+	}
+}
+
+// Run relays events until ctx is cancelled. The batch in progress is
+// finished first, so its published events are marked as such.
+func (r *Relay) Run(ctx context.Context) {
+	r.log.WithField("interval", r.interval).Info("Starting outbox relay")
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		processed, err := r.RelayOnce(context.WithoutCancel(ctx))
+		if err != nil {
+			r.log.WithError(err).Error("Failed to relay outbox events")
+		}
+
+		// A full batch means more events are likely waiting
+		if err == nil && processed == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			r.log.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes a single batch and returns the number of delivered events
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	processed, ok, err := r.store.ProcessBatch(ctx, r.batchSize, r.maxAttempts, func(events []entity.Event) map[int64]error {
+		return r.publish(ctx, events)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		r.log.Debug("Outbox relay lock is held by another replica")
+	}
+	return processed, nil
+}
+
+func (r *Relay) publish(ctx context.Context, events []entity.Event) map[int64]error {
+	results := make(map[int64]error, len(events))
+	blocked := map[int64]bool{}
+
+	for _, event := range events {
+		if blocked[event.PersonID] {
+			continue
+		}
+
+		if err := r.sink.Publish(ctx, event); err != nil {
+			r.log.WithError(err).WithFields(logrus.Fields{
+				"event_id":   event.ID,
+				"event_type": event.Type,
+				"person_id":  event.PersonID,
+			}).Warn("Failed to publish event")
+			results[event.ID] = fmt.Errorf("publishing event: %w", err)
+			blocked[event.PersonID] = true
+			continue
+		}
+		results[event.ID] = nil
+	}
+
+	return results
+}
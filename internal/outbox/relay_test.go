@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"people-enricher/internal/config"
+	"people-enricher/internal/entity"
+
+	"github.com/sirupsen/logrus"
+)
+
+// batchStore hands out one batch of events and keeps what publish reported
+type batchStore struct {
+	events  []entity.Event
+	limit   int
+	results map[int64]error
+}
+
+func (s *batchStore) ProcessBatch(ctx context.Context, limit, maxAttempts int, publish func(events []entity.Event) map[int64]error) (int, bool, error) {
+	s.limit = limit
+	s.results = publish(s.events)
+	published := 0
+	for _, err := range s.results {
+		if err == nil {
+			published++
+		}
+	}
+	return published, true, nil
+}
+
+// recordingSink keeps the published event IDs and fails the ones in fail
+type recordingSink struct {
+	published []int64
+	fail      map[int64]bool
+	closeErr  error
+}
+
+func (s *recordingSink) Publish(ctx context.Context, event entity.Event) error {
+	if s.fail[event.ID] {
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+func (s *recordingSink) Close() error { return s.closeErr }
+
+func testLog() *logrus.Entry {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logrus.NewEntry(logger)
+}
+
+func TestRelayBlocksPersonAfterFailure(t *testing.T) {
+	store := &batchStore{events: []entity.Event{
+		{ID: 1, PersonID: 10},
+		{ID: 2, PersonID: 20},
+		{ID: 3, PersonID: 10},
+		{ID: 4, PersonID: 20},
+	}}
+	sink := &recordingSink{fail: map[int64]bool{1: true}}
+	relay := NewRelay(store, sink, config.OutboxCfg{PollInterval: time.Second, BatchSize: 50, MaxAttempts: 3}, testLog())
+
+	processed, err := relay.RelayOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed != 2 || store.limit != 50 {
+		t.Errorf("processed %d events with limit %d, want 2 with 50", processed, store.limit)
+	}
+
+	// The later event of the failed person waits for the next round
+	if err, ok := store.results[1]; !ok || err == nil {
+		t.Errorf("result of the failed event = %v, want an error", err)
+	}
+	if _, ok := store.results[3]; ok {
+		t.Error("event after a failed one of the same person was published")
+	}
+	for _, id := range []int64{2, 4} {
+		if err, ok := store.results[id]; !ok || err != nil {
+			t.Errorf("result of event %d = %v, %v, want published", id, err, ok)
+		}
+	}
+	if len(sink.published) != 2 || sink.published[0] != 2 || sink.published[1] != 4 {
+		t.Errorf("sink got %v, want [2 4]", sink.published)
+	}
+}
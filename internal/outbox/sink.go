@@ -0,0 +1,97 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"people-enricher/internal/config"
+	"people-enricher/internal/entity"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sink delivers person events to a downstream system
+type Sink interface {
+	Publish(ctx context.Context, event entity.Event) error
+	Close() error
+}
+
+// NewSink creates the sink selected by cfg.Sink: "stdout", "file", "nats" or "kafka"
+func NewSink(cfg config.OutboxCfg, log *logrus.Entry) (Sink, error) {
+	switch cfg.Sink {
+	case "stdout":
+		return NewWriterSink(nopCloser{os.Stdout}), nil
+	case "file":
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening outbox file: %w", err)
+		}
+		return NewWriterSink(file), nil
+	case "nats":
+		return NewNATSSink(cfg.NATSURL, cfg.NATSSubject, log)
+	case "kafka":
+		return NewKafkaSink(cfg.KafkaBrokers, cfg.KafkaTopic), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox sink %q", cfg.Sink)
+	}
+}
+
+// WriterSink writes every event as a JSON line, it is meant for local runs and tests
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.WriteCloser
+}
+
+func NewWriterSink(w io.WriteCloser) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Publish(_ context.Context, event entity.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing event: %w", err)
+	}
+	return nil
+}
+
+func (s *WriterSink) Close() error {
+	return s.w.Close()
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// MultiSink publishes every event to all of its sinks. An event counts as
+// published only when all sinks accepted it, so a failing sink causes the
+// others to see the event again; sinks have to tolerate duplicates.
+type MultiSink []Sink
+
+func (m MultiSink) Publish(ctx context.Context, event entity.Event) error {
+	for _, sink := range m {
+		if err := sink.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m MultiSink) Close() error {
+	var errs []error
+	for _, sink := range m {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
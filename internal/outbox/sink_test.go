@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"people-enricher/internal/entity"
+)
+
+func TestMultiSinkPublish(t *testing.T) {
+	first := &recordingSink{}
+	failing := &recordingSink{fail: map[int64]bool{2: true}}
+	last := &recordingSink{}
+	sinks := MultiSink{first, failing, last}
+	ctx := context.Background()
+
+	if err := sinks.Publish(ctx, entity.Event{ID: 1}); err != nil {
+		t.Fatalf("Publish = %v", err)
+	}
+	// A failing sink fails the event, the sinks before it see it again on
+	// the retry
+	if err := sinks.Publish(ctx, entity.Event{ID: 2}); err == nil {
+		t.Fatal("Publish succeeded although a sink failed")
+	}
+	if len(first.published) != 2 || len(last.published) != 1 {
+		t.Errorf("sinks got %v and %v, want [1 2] and [1]", first.published, last.published)
+	}
+}
+
+func TestMultiSinkClose(t *testing.T) {
+	closeErr := errors.New("close failed")
+	sinks := MultiSink{&recordingSink{closeErr: closeErr}, &recordingSink{}}
+	if err := sinks.Close(); !errors.Is(err, closeErr) {
+		t.Errorf("Close = %v, want %v", err, closeErr)
+	}
+	if err := (MultiSink{&recordingSink{}}).Close(); err != nil {
+		t.Errorf("Close = %v, want nil", err)
+	}
+}
+
+func TestWriterSinkWritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(nopCloser{&buf})
+	for _, id := range []int64{1, 2} {
+		if err := sink.Publish(context.Background(), entity.Event{ID: id, Type: "person.created", PersonID: 7}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	decoder := json.NewDecoder(&buf)
+	for _, want := range []int64{1, 2} {
+		var event entity.Event
+		if err := decoder.Decode(&event); err != nil {
+			t.Fatal(err)
+		}
+		if event.ID != want || event.Type != "person.created" || event.PersonID != 7 {
+			t.Errorf("event = %+v, want ID %d of person 7", event, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Package policy decides which roles may perform which actions.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"people-enricher/internal/entity"
+	"slices"
+)
+
+// Action is an operation subject to authorization
+type Action string
+
+const (
+	ReadPersons   Action = "persons:read"
+	WritePersons  Action = "persons:write"
+	DeletePersons Action = "persons:delete"
+	// PreviewEnrichment asks the providers and uses the tenant's quota like
+	// writes do, without storing anything
+	PreviewEnrichment Action = "enrichment:preview"
+	ManageWebhooks    Action = "webhooks:manage"
+	ManageAPIKeys     Action = "api_keys:manage"
+	ManageTenants     Action = "tenants:manage"
+)
+
+// required is the least role allowed to perform each action
+var required = map[Action]string{
+	ReadPersons:       entity.RoleReader,
+	WritePersons:      entity.RoleEditor,
+	DeletePersons:     entity.RoleAdmin,
+	PreviewEnrichment: entity.RoleEditor,
+	ManageWebhooks:    entity.RoleAdmin,
+	ManageAPIKeys:     entity.RoleAdmin,
+	ManageTenants:     entity.RoleAdmin,
+}
+
+// crossTenant actions affect every tenant, principals bound to a tenant may
+// not perform them whatever their roles
+var crossTenant = []Action{ManageTenants}
+
+// Allowed reports whether principal may perform action. Higher roles include
+// the lower ones, unknown actions are denied.
+func Allowed(principal *entity.Principal, action Action) bool {
+	role, ok := required[action]
+	if !ok || (principal.Tenant != "" && slices.Contains(crossTenant, action)) {
+		return false
+	}
+	needed := slices.Index(entity.Roles, role)
+	for _, granted := range principal.Roles {
+		if slices.Index(entity.Roles, granted) >= needed {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize checks the principal of ctx may perform action, returning an
+// *entity.ForbiddenError otherwise. Contexts without a principal are denied,
+// with authentication disabled the transports attach an anonymous admin.
+func Authorize(ctx context.Context, action Action) error {
+	principal := entity.PrincipalFrom(ctx)
+	if principal == nil {
+		return fmt.Errorf("%w: no authenticated principal", entity.ErrForbidden)
+	}
+	if Allowed(principal, action) {
+		return nil
+	}
+	if principal.Tenant != "" && slices.Contains(crossTenant, action) {
+		return fmt.Errorf("%w: %s is not allowed to credentials bound to a tenant", entity.ErrForbidden, action)
+	}
+	return &entity.ForbiddenError{Action: string(action), Role: required[action]}
+}
@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"people-enricher/internal/entity"
+)
+
+func TestAuthorize(t *testing.T) {
+	// allowed lists the actions of each role, for principals of no tenant
+	allowed := map[string][]Action{
+		entity.RoleReader: {ReadPersons},
+		entity.RoleEditor: {ReadPersons, WritePersons, PreviewEnrichment},
+		entity.RoleAdmin: {
+			ReadPersons, WritePersons, DeletePersons, PreviewEnrichment,
+			ManageWebhooks, ManageAPIKeys, ManageTenants,
+		},
+	}
+	actions := []Action{
+		ReadPersons, WritePersons, DeletePersons, PreviewEnrichment,
+		ManageWebhooks, ManageAPIKeys, ManageTenants,
+	}
+
+	for _, tenant := range []string{"", "acme"} {
+		for _, role := range entity.Roles {
+			for _, action := range actions {
+				want := false
+				for _, a := range allowed[role] {
+					want = want || a == action
+				}
+				if tenant != "" && action == ManageTenants {
+					want = false
+				}
+
+				principal := &entity.Principal{Subject: "test", Roles: []string{role}, Tenant: tenant}
+				err := Authorize(entity.WithPrincipal(context.Background(), principal), action)
+				if want && err != nil {
+					t.Errorf("%s of tenant %q, %s: %v, want allowed", role, tenant, action, err)
+				}
+				if !want && !errors.Is(err, entity.ErrForbidden) {
+					t.Errorf("%s of tenant %q, %s: %v, want forbidden", role, tenant, action, err)
+				}
+			}
+		}
+	}
+}
+
+func TestAuthorizeDenies(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *entity.Principal
+		action    Action
+	}{
+		{"no principal", nil, ReadPersons},
+		{"no roles", &entity.Principal{Subject: "test"}, ReadPersons},
+		{"unknown role", &entity.Principal{Subject: "test", Roles: []string{"owner"}}, ReadPersons},
+		{"unknown action", &entity.Principal{Subject: "test", Roles: []string{entity.RoleAdmin}}, Action("persons:export")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = entity.WithPrincipal(ctx, tt.principal)
+			}
+			if err := Authorize(ctx, tt.action); !errors.Is(err, entity.ErrForbidden) {
+				t.Errorf("Authorize = %v, want forbidden", err)
+			}
+		})
+	}
+}
+
+func TestAuthorizeReportsRequiredRole(t *testing.T) {
+	principal := &entity.Principal{Subject: "test", Roles: []string{entity.RoleReader}}
+	err := Authorize(entity.WithPrincipal(context.Background(), principal), PreviewEnrichment)
+
+	var forbidden *entity.ForbiddenError
+	if !errors.As(err, &forbidden) || forbidden.Role != entity.RoleEditor {
+		t.Errorf("Authorize = %v, want a ForbiddenError requiring %s", err, entity.RoleEditor)
+	}
+}
+
+func TestAuthorizeHigherRoleWins(t *testing.T) {
+	principal := &entity.Principal{Subject: "test", Roles: []string{entity.RoleReader, entity.RoleAdmin}}
+	if err := Authorize(entity.WithPrincipal(context.Background(), principal), DeletePersons); err != nil {
+		t.Errorf("Authorize = %v, want allowed", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Package ratelimit implements token buckets whose state lives in a Store,
+// either in process or shared by all replicas.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrLimited is returned when no token is available within the allowed wait
+var ErrLimited = errors.New("rate limit exceeded")
+
+// Bucket is a token bucket refilled with Rate tokens per second, holding at
+// most Burst tokens
+type Bucket struct {
+	Rate  float64
+	Burst int
+}
+
+// State is the fill of a bucket at a point in time
+type State struct {
+	Tokens float64
+	At     time.Time
+}
+
+// Take takes a token from the bucket at now. A token available within
+// maxWait is taken, possibly ahead of time, and delay is how long to wait
+// before using it. Otherwise ok is false and the state is left unchanged.
+// A now before state.At is taken as state.At, so the bucket's clock never
+// goes back and no interval is refilled twice.
+func (b Bucket) Take(state State, now time.Time, maxWait time.Duration) (next State, delay time.Duration, ok bool) {
+	if now.Before(state.At) {
+		now = state.At
+	}
+	tokens := float64(b.Burst)
+	if !state.At.IsZero() {
+		tokens = state.Tokens
+		tokens += now.Sub(state.At).Seconds() * b.Rate
+		tokens = min(tokens, float64(b.Burst))
+	}
+
+	tokens--
+	if tokens < 0 {
+		delay = time.Duration(-tokens / b.Rate * float64(time.Second))
+	}
+	if delay > maxWait {
+		return state, delay, false
+	}
+	return State{Tokens: tokens, At: now}, delay, true
+}
+
+// Full returns when a bucket in state is refilled to Burst
+func (b Bucket) Full(state State) time.Time {
+	missing := float64(b.Burst) - state.Tokens
+	if missing <= 0 {
+		return state.At
+	}
+	return state.At.Add(time.Duration(missing / b.Rate * float64(time.Second)))
+}
+
+// Store keeps the state of buckets by key
+type Store interface {
+	// Take takes a token from the bucket of key, see Bucket.Take. state is
+	// the bucket after taking the token, when ok.
+	Take(ctx context.Context, key string, bucket Bucket, maxWait time.Duration) (state State, delay time.Duration, ok bool, err error)
+}
+
+// pruneInterval is how often Memory drops the buckets that refilled
+const pruneInterval = time.Minute
+
+type memoryState struct {
+	State
+	full time.Time
+}
+
+// Memory keeps bucket states in process, each replica limits on its own.
+// Refilled buckets are dropped, as a missing bucket starts full.
+type Memory struct {
+	mu     sync.Mutex
+	states map[string]memoryState
+	pruned time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{states: map[string]memoryState{}, pruned: time.Now()}
+}
+
+func (m *Memory) Take(ctx context.Context, key string, bucket Bucket, maxWait time.Duration) (State, time.Duration, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.pruned) > pruneInterval {
+		for key, state := range m.states {
+			if state.full.Before(now) {
+				delete(m.states, key)
+			}
+		}
+		m.pruned = now
+	}
+
+	state, delay, ok := bucket.Take(m.states[key].State, now, maxWait)
+	m.states[key] = memoryState{State: state, full: bucket.Full(state)}
+	return state, delay, ok, nil
+}
+
+// Wait takes a token from the bucket of key and sleeps until it may be used.
+// It fails with ErrLimited when that would take longer than maxWait, a zero
+// maxWait only accepts tokens available right away.
+func Wait(ctx context.Context, store Store, key string, bucket Bucket, maxWait time.Duration) error {
+	_, delay, ok, err := store.Take(ctx, key, bucket, maxWait)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s: %w, next token in %s", key, ErrLimited, delay.Round(time.Millisecond))
+	}
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBucketTake(t *testing.T) {
+	bucket := Bucket{Rate: 2, Burst: 3}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		state     State
+		maxWait   time.Duration
+		wantOK    bool
+		wantDelay time.Duration
+		wantLeft  float64
+		// wantAt is the time of the new state, now when zero
+		wantAt time.Time
+	}{
+		{"new bucket starts full", State{}, 0, true, 0, 2, time.Time{}},
+		{"empty bucket", State{Tokens: 0, At: now}, 0, false, 500 * time.Millisecond, 0, time.Time{}},
+		{"token ahead of time", State{Tokens: 0, At: now}, time.Second, true, 500 * time.Millisecond, -1, time.Time{}},
+		{"refilled at rate", State{Tokens: 0, At: now.Add(-time.Second)}, 0, true, 0, 1, time.Time{}},
+		{"refill capped at burst", State{Tokens: 0, At: now.Add(-time.Minute)}, 0, true, 0, 2, time.Time{}},
+		{"clock going back", State{Tokens: 1, At: now.Add(time.Second)}, 0, true, 0, 0, now.Add(time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, delay, ok := bucket.Take(tt.state, now, tt.maxWait)
+			if ok != tt.wantOK || delay != tt.wantDelay {
+				t.Fatalf("Take = delay %s, ok %v, want delay %s, ok %v", delay, ok, tt.wantDelay, tt.wantOK)
+			}
+			if !ok {
+				if next != tt.state {
+					t.Errorf("state changed to %+v without taking a token", next)
+				}
+				return
+			}
+			wantAt := tt.wantAt
+			if wantAt.IsZero() {
+				wantAt = now
+			}
+			if next.Tokens != tt.wantLeft || !next.At.Equal(wantAt) {
+				t.Errorf("state = %+v, want %v tokens at %s", next, tt.wantLeft, wantAt)
+			}
+		})
+	}
+}
+
+func TestBucketFull(t *testing.T) {
+	bucket := Bucket{Rate: 2, Burst: 3}
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if got := bucket.Full(State{Tokens: 1, At: at}); !got.Equal(at.Add(time.Second)) {
+		t.Errorf("Full = %s, want a second later", got)
+	}
+	if got := bucket.Full(State{Tokens: 3, At: at}); !got.Equal(at) {
+		t.Errorf("Full of a full bucket = %s, want %s", got, at)
+	}
+}
+
+func TestMemoryTake(t *testing.T) {
+	store := NewMemory()
+	bucket := Bucket{Rate: 0.001, Burst: 2}
+	ctx := context.Background()
+
+	for i, want := range []bool{true, true, false} {
+		if _, _, ok, err := store.Take(ctx, "a", bucket, 0); err != nil || ok != want {
+			t.Fatalf("take %d from a = %v, %v, want %v", i, ok, err, want)
+		}
+	}
+	// Keys have their own buckets
+	if _, _, ok, _ := store.Take(ctx, "b", bucket, 0); !ok {
+		t.Error("bucket b was emptied by takes from a")
+	}
+}
+
+func TestWait(t *testing.T) {
+	store := NewMemory()
+	ctx := context.Background()
+
+	limited := Bucket{Rate: 0.001, Burst: 1}
+	if err := Wait(ctx, store, "limited", limited, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := Wait(ctx, store, "limited", limited, time.Second); !errors.Is(err, ErrLimited) {
+		t.Errorf("Wait on an empty bucket = %v, want ErrLimited", err)
+	}
+
+	fast := Bucket{Rate: 100, Burst: 1}
+	_ = Wait(ctx, store, "fast", fast, 0)
+	start := time.Now()
+	if err := Wait(ctx, store, "fast", fast, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited < 5*time.Millisecond {
+		t.Errorf("waited %s for the next token, want about 10ms", waited)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := Wait(canceled, store, "fast", fast, time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait with a canceled context = %v, want context.Canceled", err)
+	}
+}
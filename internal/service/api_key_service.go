@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"people-enricher/internal/adapter/repository"
+	"people-enricher/internal/auth"
+	"people-enricher/internal/entity"
+	"people-enricher/internal/policy"
+
+	"github.com/sirupsen/logrus"
+)
+
+type apiKeyService struct {
+	repo *repository.APIKeyRepo
+	log  *logrus.Entry
+}
+
+func NewAPIKeyService(repo *repository.APIKeyRepo, log *logrus.Entry) *apiKeyService {
+	return &apiKeyService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, input *entity.APIKeyInput) (*entity.APIKey, error) {
+	if err := policy.Authorize(ctx, policy.ManageAPIKeys); err != nil {
+		return nil, err
+	}
+
+	invalid := &entity.ValidationError{}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		invalid.Add("name", "is required")
+	}
+	role := input.Role
+	if role == "" {
+		role = entity.RoleReader
+	}
+	if !slices.Contains(entity.Roles, role) {
+		invalid.Add("role", "must be one of "+strings.Join(entity.Roles, ", "))
+	}
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+	s.log.WithFields(logrus.Fields{"name": name, "role": role}).Info("Creating API key")
+
+	key, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, &entity.APIKey{
+		Name:     name,
+		Prefix:   key[:auth.DisplayPrefixLen],
+		Role:     role,
+		TenantID: entity.TenantID(ctx),
+	}, auth.HashAPIKey(key))
+	if err != nil {
+		s.log.WithError(err).Error("Failed to create API key")
+		return nil, err
+	}
+
+	// The key is shown once, only its hash is stored
+	created.Key = key
+	s.log.WithField("id", created.ID).Info("Successfully created API key")
+	return created, nil
+}
+
+func (s *apiKeyService) List(ctx context.Context) ([]*entity.APIKey, error) {
+	if err := policy.Authorize(ctx, policy.ManageAPIKeys); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(ctx)
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, id int64) error {
+	if err := policy.Authorize(ctx, policy.ManageAPIKeys); err != nil {
+		return err
+	}
+
+	s.log.WithField("id", id).Info("Revoking API key")
+	return s.repo.Revoke(ctx, id)
+}
@@ -3,11 +3,17 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 
 	"people-enricher/internal/adapter/repository"
 	"people-enricher/internal/client"
+	"people-enricher/internal/config"
 	"people-enricher/internal/entity"
+	"people-enricher/internal/policy"
+	"people-enricher/internal/translit"
+	"people-enricher/internal/validation"
 
 	"github.com/sirupsen/logrus"
 )
@@ -21,73 +27,103 @@ type PersonRepo interface {
 }
 
 type personService struct {
-	repo     repository.PersonRepo
-	enricher *client.Enricher
-	log      *logrus.Entry
+	repo       repository.PersonRepo
+	tenants    *repository.TenantRepo
+	enricher   *client.Enricher
+	duplicates config.DuplicateCfg
+	translit   config.TranslitCfg
+	log        *logrus.Entry
 }
 
-func NewPersonService(repo repository.PersonRepo, enricher *client.Enricher, log *logrus.Entry) *personService {
+func NewPersonService(repo repository.PersonRepo, tenants *repository.TenantRepo, enricher *client.Enricher, duplicates config.DuplicateCfg, translit config.TranslitCfg, log *logrus.Entry) *personService {
 	return &personService{
-		repo:     repo,
-		enricher: enricher,
-		log:      log,
+		repo:       repo,
+		tenants:    tenants,
+		enricher:   enricher,
+		duplicates: duplicates,
+		translit:   translit,
+		log:        log,
 	}
 }
 
 func (s *personService) Create(ctx context.Context, input *entity.Person) (*entity.Person, error) {
+	if err := policy.Authorize(ctx, policy.WritePersons); err != nil {
+		return nil, err
+	}
+
 	s.log.WithFields(logrus.Fields{
 		"name":       input.Name,
 		"surname":    input.Surname,
 		"patronymic": input.Patronymic,
 	}).Info("Creating person")
 
-	// Получаем обогащённые данные по имени.
-	enrichedResult, err := s.enricher.EnrichPerson(ctx, input.Name)
-	if err != nil {
-		s.log.WithError(err).Error("Failed to enrich person data")
+	if err := validation.NormalizePerson(input); err != nil {
+		return nil, err
+	}
+	s.transliterate(input)
 
+	duplicates, err := s.checkDuplicates(ctx, input)
+	if err != nil {
+		return nil, err
 	}
 
+	// Получаем обогащённые данные по имени.
+	enrichedResult, skipped := s.enrichOrSkip(ctx, input)
+
 	person := &entity.Person{
-		Name:       input.Name,
-		Surname:    input.Surname,
-		Patronymic: input.Patronymic,
+		Name:            input.Name,
+		Surname:         input.Surname,
+		Patronymic:      input.Patronymic,
+		NameLatin:       input.NameLatin,
+		SurnameLatin:    input.SurnameLatin,
+		PatronymicLatin: input.PatronymicLatin,
 	}
 
-	if enrichedResult != nil {
-		if enrichedResult.Age != nil {
-			person.Age = enrichedResult.Age
-		}
-		if enrichedResult.Gender != nil {
-			person.Gender = enrichedResult.Gender
-		}
-		if enrichedResult.Nationality != nil {
-			person.Nationality = enrichedResult.Nationality
-		}
+	applyEnrichment(person, enrichedResult)
 
-		if enrichedResult.NationalityProbability != nil {
-			person.NationalityProbability = ptrString(fmt.Sprintf("%.2f", *enrichedResult.NationalityProbability))
+	var createdPerson *entity.Person
+	err = s.repo.WithTx(ctx, func(repo *repository.PersonRepo) error {
+		createdPerson, err = repo.Create(ctx, person)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to create person in DB")
+			return err
 		}
-	}
 
-	createdPerson, err := s.repo.Create(ctx, person)
+		if err := repo.AddEvent(ctx, entity.EventPersonCreated, createdPerson); err != nil {
+			return err
+		}
+		if isEnriched(enrichedResult) {
+			return repo.AddEvent(ctx, entity.EventPersonEnriched, createdPerson)
+		}
+		return nil
+	})
 	if err != nil {
-		s.log.WithError(err).Error("Failed to create person in DB")
 		return nil, err
 	}
+	createdPerson.PossibleDuplicates = duplicates
+	createdPerson.EnrichmentSkipped = skipped
 
 	s.log.WithField("id", createdPerson.ID).Info("Successfully created person")
 	return createdPerson, nil
 }
 
 func (s *personService) GetById(ctx context.Context, id int64) (*entity.Person, error) {
+	if err := policy.Authorize(ctx, policy.ReadPersons); err != nil {
+		return nil, err
+	}
+
 	s.log.WithField("id", id).Info("Fetching person by ID")
 	person, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.log.WithFields(logrus.Fields{
-			"id":    id,
-			"error": err,
-		}).Error("Failed to fetch person")
+		if !errors.Is(err, entity.ErrNotFound) {
+			s.log.WithFields(logrus.Fields{"id": id, "error": err}).Error("Failed to fetch person")
+			return nil, err
+		}
+		if targetID, mergeErr := s.repo.MergedInto(ctx, id); mergeErr == nil && targetID != 0 {
+			s.log.WithFields(logrus.Fields{"id": id, "target_id": targetID}).Info("Person was merged")
+			return nil, &entity.MergedError{ID: id, TargetID: targetID}
+		}
+		s.log.WithField("id", id).Warn("Person not found")
 		return nil, err
 	}
 	s.log.WithField("id", id).Info("Successfully fetched person")
@@ -95,6 +131,10 @@ func (s *personService) GetById(ctx context.Context, id int64) (*entity.Person,
 }
 
 func (s *personService) Update(ctx context.Context, person *entity.Person) (*entity.Person, error) {
+	if err := policy.Authorize(ctx, policy.WritePersons); err != nil {
+		return nil, err
+	}
+
 	s.log.WithFields(logrus.Fields{
 		"id":         person.ID,
 		"name":       person.Name,
@@ -102,64 +142,295 @@ func (s *personService) Update(ctx context.Context, person *entity.Person) (*ent
 		"patronymic": person.Patronymic,
 	}).Info("Updating person")
 
-	existing, err := s.repo.GetByID(ctx, person.ID)
-	if err != nil {
-		s.log.WithFields(logrus.Fields{"id": person.ID, "error": err}).Error("Person not found")
+	if err := validation.NormalizePerson(person); err != nil {
 		return nil, err
 	}
-	_ = existing
+	s.transliterate(person)
 
-	enrichedResult, err := s.enricher.EnrichPerson(ctx, person.Name)
-	if err != nil {
-		s.log.WithError(err).Error("Failed to enrich updated person data")
-	} else if enrichedResult != nil {
-		if enrichedResult.Age != nil {
-			person.Age = enrichedResult.Age
+	// Missing people are not worth the providers' requests and the quota
+	if _, err := s.repo.GetByID(ctx, person.ID); err != nil {
+		s.log.WithFields(logrus.Fields{"id": person.ID, "error": err}).Warn("Person lookup failed")
+		return nil, err
+	}
+
+	enrichedResult, skipped := s.enrichOrSkip(ctx, person)
+	applyEnrichment(person, enrichedResult)
+
+	// Enrichment talks to external APIs, so the transaction only covers the
+	// existence check and the write. The person may be gone by now.
+	var updated *entity.Person
+	var err error
+	err = s.repo.WithTx(ctx, func(repo *repository.PersonRepo) error {
+		if _, err := repo.GetByID(ctx, person.ID); err != nil {
+			s.log.WithFields(logrus.Fields{"id": person.ID, "error": err}).Warn("Person lookup failed")
+			return err
 		}
-		if enrichedResult.Gender != nil {
-			person.Gender = enrichedResult.Gender
+
+		updated, err = repo.Update(ctx, person)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to update person in DB")
+			return err
 		}
-		if enrichedResult.Nationality != nil {
-			person.Nationality = enrichedResult.Nationality
+
+		if err := repo.AddEvent(ctx, entity.EventPersonUpdated, updated); err != nil {
+			return err
 		}
-		if enrichedResult.NationalityProbability != nil {
-			person.NationalityProbability = ptrString(fmt.Sprintf("%.2f", *enrichedResult.NationalityProbability))
+		if isEnriched(enrichedResult) {
+			return repo.AddEvent(ctx, entity.EventPersonEnriched, updated)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	updated, err := s.repo.Update(ctx, person)
+	updated.EnrichmentSkipped = skipped
+
+	s.log.WithField("id", person.ID).Info("Successfully updated person")
+	return updated, nil
+}
+
+// Enrich re-runs enrichment for a stored person and saves what was found
+func (s *personService) Enrich(ctx context.Context, id int64) (*entity.Person, error) {
+	if err := policy.Authorize(ctx, policy.WritePersons); err != nil {
+		return nil, err
+	}
+
+	s.log.WithField("id", id).Info("Enriching person")
+
+	person, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.log.WithError(err).Error("Failed to update person in DB")
+		s.log.WithFields(logrus.Fields{"id": id, "error": err}).Warn("Person lookup failed")
 		return nil, err
 	}
 
-	s.log.WithField("id", person.ID).Info("Successfully updated person")
+	// People stored before transliteration get their Latin forms now
+	s.transliterate(person)
+
+	enrichedResult, err := s.enrich(ctx, person)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to enrich person data")
+		return nil, err
+	}
+	if !isEnriched(enrichedResult) {
+		s.log.WithField("id", id).Info("Enrichment found nothing new")
+		return person, nil
+	}
+	applyEnrichment(person, enrichedResult)
+
+	var updated *entity.Person
+	err = s.repo.WithTx(ctx, func(repo *repository.PersonRepo) error {
+		updated, err = repo.Update(ctx, person)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to save enriched person")
+			return err
+		}
+		return repo.AddEvent(ctx, entity.EventPersonEnriched, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.WithField("id", id).Info("Successfully enriched person")
 	return updated, nil
 }
 
-func (s *personService) Delete(ctx context.Context, id int64) error {
-	s.log.WithField("id", id).Info("Deleting person")
+// PreviewEnrichment runs enrichment for a name like Create would, without
+// storing anything. It counts against the tenant's enrichment quota.
+func (s *personService) PreviewEnrichment(ctx context.Context, person *entity.Person) (*entity.EnrichmentPreview, error) {
+	if err := policy.Authorize(ctx, policy.PreviewEnrichment); err != nil {
+		return nil, err
+	}
 
-	_, err := s.repo.GetByID(ctx, id)
+	s.log.WithFields(logrus.Fields{
+		"name":       person.Name,
+		"surname":    person.Surname,
+		"patronymic": person.Patronymic,
+	}).Info("Previewing enrichment")
+
+	if err := validation.NormalizePerson(person); err != nil {
+		return nil, err
+	}
+	s.transliterate(person)
+
+	result, err := s.enrich(ctx, person)
 	if err != nil {
-		s.log.WithFields(logrus.Fields{"id": id, "error": err}).Error("Person not found")
+		s.log.WithError(err).Error("Failed to enrich person data")
+		return nil, err
+	}
+	if result == nil {
+		result = &client.EnrichmentResult{}
+	}
+	applyEnrichment(person, result)
+
+	return &entity.EnrichmentPreview{
+		Name:                   person.Name,
+		Surname:                person.Surname,
+		Patronymic:             person.Patronymic,
+		NameLatin:              person.NameLatin,
+		SurnameLatin:           person.SurnameLatin,
+		PatronymicLatin:        person.PatronymicLatin,
+		Age:                    person.Age,
+		Gender:                 person.Gender,
+		Nationality:            person.Nationality,
+		NationalityProbability: person.NationalityProbability,
+		Nationalities:          result.Nationalities,
+		Resolutions:            person.Resolutions,
+		Answers:                result.Answers,
+	}, nil
+}
+
+func (s *personService) Delete(ctx context.Context, id int64) error {
+	if err := policy.Authorize(ctx, policy.DeletePersons); err != nil {
 		return err
 	}
 
-	err = s.repo.Delete(ctx, id)
+	s.log.WithField("id", id).Info("Deleting person")
+
+	err := s.repo.WithTx(ctx, func(repo *repository.PersonRepo) error {
+		existing, err := repo.GetByID(ctx, id)
+		if err != nil {
+			s.log.WithFields(logrus.Fields{"id": id, "error": err}).Warn("Person lookup failed")
+			return err
+		}
+
+		if err := repo.Delete(ctx, id); err != nil {
+			s.log.WithError(err).Error("Failed to delete person")
+			return err
+		}
+		return repo.AddEvent(ctx, entity.EventPersonDeleted, existing)
+	})
 	if err != nil {
-		s.log.WithError(err).Error("Failed to delete person")
 		return err
 	}
 	s.log.WithField("id", id).Info("Successfully deleted person")
 	return nil
 }
 
+// transliterate sets the Latin forms of the person's names. Names already
+// written in Latin have none, the original is used for search and enrichment.
+func (s *personService) transliterate(person *entity.Person) {
+	person.NameLatin = s.latin(person.Name)
+	person.SurnameLatin = s.latin(person.Surname)
+	person.PatronymicLatin = nil
+	if person.Patronymic != nil {
+		person.PatronymicLatin = s.latin(*person.Patronymic)
+	}
+}
+
+func (s *personService) latin(name string) *string {
+	if translit.IsLatin(name) {
+		return nil
+	}
+	return ptrString(translit.Transliterate(name, s.translit.Scheme))
+}
+
 func ptrString(s string) *string {
 	return &s
 }
 
+// enrich looks the person up within the enrichment settings and daily quota
+// of the tenant of ctx. Tenants with enrichment disabled get a nil result,
+// a used up quota fails with *entity.QuotaExceededError.
+func (s *personService) enrich(ctx context.Context, person *entity.Person) (*client.EnrichmentResult, error) {
+	tenant := entity.TenantFrom(ctx)
+	if tenant == nil {
+		return s.enricher.EnrichPerson(ctx, person)
+	}
+	if tenant.Enrichment.Disabled {
+		s.log.WithField("tenant", tenant.ID).Debug("Enrichment is disabled for tenant")
+		return nil, nil
+	}
+	if tenant.DailyEnrichmentQuota > 0 {
+		ok, err := s.tenants.UseEnrichment(ctx, tenant.ID, tenant.DailyEnrichmentQuota)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			s.log.WithField("tenant", tenant.ID).Warn("Tenant used up its enrichment quota")
+			return nil, &entity.QuotaExceededError{Tenant: tenant.ID, Limit: tenant.DailyEnrichmentQuota}
+		}
+	}
+
+	result, err := s.enricher.EnrichPerson(ctx, person)
+	restrictFields(result, tenant.Enrichment.Fields)
+	return result, err
+}
+
+// enrichOrSkip enriches a person about to be stored. Failing enrichment does
+// not keep the person from being stored, the reason is returned to be
+// reported in Person.EnrichmentSkipped instead.
+func (s *personService) enrichOrSkip(ctx context.Context, person *entity.Person) (*client.EnrichmentResult, string) {
+	result, err := s.enrich(ctx, person)
+	switch {
+	case err == nil:
+		return result, ""
+	case errors.Is(err, entity.ErrQuota):
+		return nil, entity.EnrichmentSkippedQuota
+	}
+	s.log.WithError(err).Error("Failed to enrich person data")
+	return nil, entity.EnrichmentSkippedFailed
+}
+
+// restrictFields drops the fields the tenant does not enrich, fields empty
+// keeps them all
+func restrictFields(result *client.EnrichmentResult, fields []string) {
+	if result == nil || len(fields) == 0 {
+		return
+	}
+	if !slices.Contains(fields, "age") {
+		result.Age = nil
+	}
+	if !slices.Contains(fields, "gender") {
+		result.Gender = nil
+	}
+	if !slices.Contains(fields, "nationality") {
+		result.Nationality = nil
+		result.NationalityProbability = nil
+		result.Nationalities = nil
+	}
+	for field := range result.Resolutions {
+		if !slices.Contains(fields, field) {
+			delete(result.Resolutions, field)
+		}
+	}
+}
+
+// applyEnrichment copies the fields found by the enricher onto the person
+func applyEnrichment(person *entity.Person, result *client.EnrichmentResult) {
+	if result == nil {
+		return
+	}
+	if result.Age != nil {
+		person.Age = result.Age
+	}
+	if result.Gender != nil {
+		person.Gender = result.Gender
+	}
+	if result.Nationality != nil {
+		person.Nationality = result.Nationality
+	}
+	if result.NationalityProbability != nil {
+		person.NationalityProbability = ptrString(fmt.Sprintf("%.2f", *result.NationalityProbability))
+	}
+	// Fields enrichment did not find keep how they were resolved before
+	for field, resolution := range result.Resolutions {
+		if person.Resolutions == nil {
+			person.Resolutions = map[string]entity.FieldResolution{}
+		}
+		person.Resolutions[field] = resolution
+	}
+}
+
+// isEnriched reports whether enrichment produced at least one field
+func isEnriched(result *client.EnrichmentResult) bool {
+	return result != nil && (result.Age != nil || result.Gender != nil || result.Nationality != nil)
+}
+
 func (s *personService) List(ctx context.Context, filter *entity.PersonFilter) ([]*entity.Person, int, error) {
+	if err := policy.Authorize(ctx, policy.ReadPersons); err != nil {
+		return nil, 0, err
+	}
 
 	s.log.Infof("got request: %+v", filter)
 
@@ -171,3 +442,125 @@ func (s *personService) List(ctx context.Context, filter *entity.PersonFilter) (
 
 	return persons, total, nil
 }
+
+// checkDuplicates looks for existing people with the same or a similar name.
+// In reject mode found candidates fail the create with *entity.DuplicateError,
+// in warn mode they are returned to be reported alongside the created person.
+func (s *personService) checkDuplicates(ctx context.Context, person *entity.Person) ([]entity.DuplicateCandidate, error) {
+	if s.duplicates.Mode == "off" {
+		return nil, nil
+	}
+
+	candidates, err := s.repo.FindDuplicates(ctx, person, s.duplicates.Threshold)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to check duplicates")
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"name":       person.Name,
+		"surname":    person.Surname,
+		"candidates": len(candidates),
+	}).Warn("Possible duplicate person")
+
+	if s.duplicates.Mode == "reject" && !entity.DuplicatesAllowed(ctx) {
+		return nil, &entity.DuplicateError{Candidates: candidates}
+	}
+	return candidates, nil
+}
+
+func (s *personService) Merge(ctx context.Context, sourceID, targetID int64) (*entity.Person, error) {
+	if err := policy.Authorize(ctx, policy.DeletePersons); err != nil {
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"source_id": sourceID,
+		"target_id": targetID,
+	}).Info("Merging persons")
+
+	invalid := &entity.ValidationError{}
+	if sourceID <= 0 {
+		invalid.Add("source_id", "must be a positive person ID")
+	}
+	if targetID <= 0 {
+		invalid.Add("target_id", "must be a positive person ID")
+	}
+	if sourceID == targetID {
+		invalid.Add("target_id", "must differ from source_id")
+	}
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+
+	var merged *entity.Person
+	err := s.repo.WithTx(ctx, func(repo *repository.PersonRepo) error {
+		source, err := repo.GetByID(ctx, sourceID)
+		if err != nil {
+			return err
+		}
+
+		merged, err = repo.Merge(ctx, sourceID, targetID)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.AddEvent(ctx, entity.EventPersonUpdated, merged); err != nil {
+			return err
+		}
+		return repo.AddEvent(ctx, entity.EventPersonDeleted, source)
+	})
+	if err != nil {
+		s.log.WithError(err).Error("Failed to merge persons")
+		return nil, err
+	}
+
+	s.log.WithField("id", merged.ID).Info("Successfully merged persons")
+	return merged, nil
+}
+
+func (s *personService) Stats(ctx context.Context, filter *entity.PersonFilter, ageBuckets []int) (*entity.PersonStats, error) {
+	if err := policy.Authorize(ctx, policy.ReadPersons); err != nil {
+		return nil, err
+	}
+
+	s.log.Infof("got stats request: %+v, age buckets: %v", filter, ageBuckets)
+
+	if len(ageBuckets) == 0 {
+		ageBuckets = entity.DefaultAgeBuckets
+	}
+	for i, bucket := range ageBuckets {
+		if bucket < 0 {
+			return nil, entity.NewValidationError("age_buckets", "must be a list of non-negative integers")
+		}
+		if i > 0 && bucket <= ageBuckets[i-1] {
+			return nil, entity.NewValidationError("age_buckets", "must be strictly ascending")
+		}
+	}
+
+	stats, err := s.repo.Stats(ctx, filter, ageBuckets)
+	if err != nil {
+		s.log.Errorf("error to getting stats: %v", err)
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (s *personService) History(ctx context.Context, id int64, limit int) ([]entity.Event, error) {
+	if err := policy.Authorize(ctx, policy.ReadPersons); err != nil {
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{"id": id, "limit": limit}).Info("Fetching person history")
+
+	events, err := s.repo.History(ctx, id, limit)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to fetch person history")
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"people-enricher/internal/adapter/repository"
+	"people-enricher/internal/entity"
+	"people-enricher/internal/policy"
+
+	"github.com/sirupsen/logrus"
+)
+
+// tenantIDPattern keeps tenant IDs safe to use in headers, claims and URLs
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// TenantCache is told about changed tenants
+type TenantCache interface {
+	Forget(id string)
+}
+
+type tenantService struct {
+	repo  *repository.TenantRepo
+	cache TenantCache
+	log   *logrus.Entry
+}
+
+func NewTenantService(repo *repository.TenantRepo, cache TenantCache, log *logrus.Entry) *tenantService {
+	return &tenantService{
+		repo:  repo,
+		cache: cache,
+		log:   log,
+	}
+}
+
+func (s *tenantService) Get(ctx context.Context, id string) (*entity.Tenant, error) {
+	if err := policy.Authorize(ctx, policy.ManageTenants); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Get(ctx, id)
+}
+
+func (s *tenantService) List(ctx context.Context) ([]*entity.Tenant, error) {
+	if err := policy.Authorize(ctx, policy.ManageTenants); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(ctx)
+}
+
+// Put creates the tenant or replaces its settings
+func (s *tenantService) Put(ctx context.Context, id string, input *entity.TenantInput) (*entity.Tenant, error) {
+	if err := policy.Authorize(ctx, policy.ManageTenants); err != nil {
+		return nil, err
+	}
+
+	invalid := &entity.ValidationError{}
+	if !tenantIDPattern.MatchString(id) {
+		invalid.Add("id", "must be 1 to 64 lowercase letters, digits, - or _")
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		invalid.Add("name", "is required")
+	}
+	for _, field := range input.Enrichment.Fields {
+		if !slices.Contains(entity.EnrichedFields, field) {
+			invalid.Add("enrichment.fields", fmt.Sprintf("unknown field %q", field))
+		}
+	}
+	if input.DailyEnrichmentQuota < 0 {
+		invalid.Add("daily_enrichment_quota", "must not be negative")
+	}
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"id":                     id,
+		"enrichment_disabled":    input.Enrichment.Disabled,
+		"daily_enrichment_quota": input.DailyEnrichmentQuota,
+	}).Info("Storing tenant")
+
+	tenant, err := s.repo.Put(ctx, &entity.Tenant{
+		ID:                   id,
+		Name:                 name,
+		Enrichment:           input.Enrichment,
+		DailyEnrichmentQuota: input.DailyEnrichmentQuota,
+	})
+	if err != nil {
+		s.log.WithError(err).Error("Failed to store tenant")
+		return nil, err
+	}
+	s.cache.Forget(id)
+	return tenant, nil
+}
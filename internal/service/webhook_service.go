@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+
+	"people-enricher/internal/adapter/repository"
+	"people-enricher/internal/entity"
+	"people-enricher/internal/policy"
+	"people-enricher/internal/webhook"
+
+	"github.com/sirupsen/logrus"
+)
+
+type webhookService struct {
+	repo    *repository.WebhookRepo
+	targets webhook.Targets
+	log     *logrus.Entry
+}
+
+func NewWebhookService(repo *repository.WebhookRepo, targets webhook.Targets, log *logrus.Entry) *webhookService {
+	return &webhookService{
+		repo:    repo,
+		targets: targets,
+		log:     log,
+	}
+}
+
+func (s *webhookService) Create(ctx context.Context, input *entity.WebhookInput) (*entity.Webhook, error) {
+	if err := policy.Authorize(ctx, policy.ManageWebhooks); err != nil {
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"url":    input.URL,
+		"events": input.Events,
+	}).Info("Creating webhook")
+
+	hook, err := s.webhookFromInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if hook.Secret == "" {
+		if hook.Secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := s.repo.Create(ctx, hook)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to create webhook")
+		return nil, err
+	}
+
+	// The secret is shown once, later reads never return it
+	created.Secret = hook.Secret
+	s.log.WithField("id", created.ID).Info("Successfully created webhook")
+	return created, nil
+}
+
+func (s *webhookService) Update(ctx context.Context, id int64, input *entity.WebhookInput) (*entity.Webhook, error) {
+	if err := policy.Authorize(ctx, policy.ManageWebhooks); err != nil {
+		return nil, err
+	}
+
+	s.log.WithField("id", id).Info("Updating webhook")
+
+	hook, err := s.webhookFromInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	hook.ID = id
+
+	updated, err := s.repo.Update(ctx, hook)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to update webhook")
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, id int64) error {
+	if err := policy.Authorize(ctx, policy.ManageWebhooks); err != nil {
+		return err
+	}
+
+	s.log.WithField("id", id).Info("Deleting webhook")
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *webhookService) GetByID(ctx context.Context, id int64) (*entity.Webhook, error) {
+	if err := policy.Authorize(ctx, policy.ManageWebhooks); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *webhookService) List(ctx context.Context) ([]*entity.Webhook, error) {
+	if err := policy.Authorize(ctx, policy.ManageWebhooks); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(ctx)
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, webhookID int64, filter *entity.DeliveryFilter) ([]*entity.WebhookDelivery, int, error) {
+	if err := policy.Authorize(ctx, policy.ManageWebhooks); err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := s.repo.GetByID(ctx, webhookID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListDeliveries(ctx, webhookID, filter)
+}
+
+func (s *webhookService) webhookFromInput(ctx context.Context, input *entity.WebhookInput) (*entity.Webhook, error) {
+	invalid := &entity.ValidationError{}
+	if err := s.targets.CheckURL(ctx, input.URL); errors.Is(err, webhook.ErrForbiddenTarget) {
+		invalid.Add("url", "must not point to a loopback, link-local, private or reserved address")
+	} else if err != nil {
+		invalid.Add("url", err.Error())
+	}
+	if len(input.Events) == 0 {
+		invalid.Add("events", "at least one event is required")
+	}
+	for _, event := range input.Events {
+		if !slices.Contains(entity.WebhookEvents, event) {
+			invalid.Add("events", fmt.Sprintf("unknown event %q", event))
+		}
+	}
+	if err := invalid.Err(); err != nil {
+		return nil, err
+	}
+
+	active := true
+	if input.Active != nil {
+		active = *input.Active
+	}
+
+	return &entity.Webhook{
+		URL:         input.URL,
+		Events:      input.Events,
+		Description: input.Description,
+		Active:      active,
+		Secret:      input.Secret,
+	}, nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,96 @@
+// Package tenant resolves the tenant a request acts on.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"people-enricher/internal/entity"
+	"sync"
+	"time"
+)
+
+// Header names the tenant of a request, metadata key "x-tenant-id" on gRPC
+const Header = "X-Tenant-ID"
+
+// cacheTTL is how long tenant settings are reused before being read again
+const cacheTTL = 30 * time.Second
+
+// Store looks tenants up
+type Store interface {
+	Get(ctx context.Context, id string) (*entity.Tenant, error)
+}
+
+type cached struct {
+	tenant *entity.Tenant
+	at     time.Time
+}
+
+// Resolver picks the tenant of a request from its principal or its tenant
+// header
+type Resolver struct {
+	store Store
+
+	mu      sync.Mutex
+	tenants map[string]cached
+}
+
+func NewResolver(store Store) *Resolver {
+	return &Resolver{
+		store:   store,
+		tenants: map[string]cached{},
+	}
+}
+
+// Resolve attaches the tenant of a request to ctx. Principals bound to a
+// tenant act on it, naming another one is forbidden. The others, the
+// bootstrap key or any caller when authentication is off, act on the
+// requested tenant or the default one. Unknown tenants are invalid input.
+func (r *Resolver) Resolve(ctx context.Context, requested string) (context.Context, error) {
+	id := requested
+	if principal := entity.PrincipalFrom(ctx); principal != nil && principal.Tenant != "" {
+		if requested != "" && requested != principal.Tenant {
+			return nil, fmt.Errorf("%w: credentials are bound to tenant %s", entity.ErrForbidden, principal.Tenant)
+		}
+		id = principal.Tenant
+	}
+	if id == "" {
+		id = entity.DefaultTenant
+	}
+
+	tenant, err := r.lookup(ctx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil, entity.NewValidationError("tenant", fmt.Sprintf("unknown tenant %q", id))
+		}
+		return nil, err
+	}
+	return entity.WithTenant(ctx, tenant), nil
+}
+
+func (r *Resolver) lookup(ctx context.Context, id string) (*entity.Tenant, error) {
+	r.mu.Lock()
+	entry, ok := r.tenants[id]
+	r.mu.Unlock()
+	if ok && time.Since(entry.at) < cacheTTL {
+		return entry.tenant, nil
+	}
+
+	tenant, err := r.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.tenants[id] = cached{tenant: tenant, at: time.Now()}
+	r.mu.Unlock()
+	return tenant, nil
+}
+
+// Forget drops the cached settings of a tenant, so changes apply right away
+// on this replica
+func (r *Resolver) Forget(id string) {
+	r.mu.Lock()
+	delete(r.tenants, id)
+	r.mu.Unlock()
+}
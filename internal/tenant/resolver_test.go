@@ -0,0 +1,110 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"people-enricher/internal/entity"
+)
+
+// mapStore holds tenants by ID and counts its lookups
+type mapStore struct {
+	tenants map[string]*entity.Tenant
+	gets    int
+	err     error
+}
+
+func (s *mapStore) Get(ctx context.Context, id string) (*entity.Tenant, error) {
+	s.gets++
+	if s.err != nil {
+		return nil, s.err
+	}
+	tenant, ok := s.tenants[id]
+	if !ok {
+		return nil, fmt.Errorf("tenant %s: %w", id, entity.ErrNotFound)
+	}
+	return tenant, nil
+}
+
+func newStore() *mapStore {
+	return &mapStore{tenants: map[string]*entity.Tenant{
+		entity.DefaultTenant: {ID: entity.DefaultTenant},
+		"acme":               {ID: "acme"},
+		"globex":             {ID: "globex"},
+	}}
+}
+
+func TestResolve(t *testing.T) {
+	bound := &entity.Principal{Subject: "api_key:1", Tenant: "acme"}
+	bootstrap := &entity.Principal{Subject: "bootstrap"}
+
+	tests := []struct {
+		name      string
+		principal *entity.Principal
+		requested string
+		want      string
+		wantErr   error
+	}{
+		{"bound principal", bound, "", "acme", nil},
+		{"bound principal naming its tenant", bound, "acme", "acme", nil},
+		{"bound principal naming another tenant", bound, "globex", "", entity.ErrForbidden},
+		{"bootstrap key naming a tenant", bootstrap, "globex", "globex", nil},
+		{"bootstrap key", bootstrap, "", entity.DefaultTenant, nil},
+		{"no authentication naming a tenant", nil, "acme", "acme", nil},
+		{"no authentication", nil, "", entity.DefaultTenant, nil},
+		{"unknown tenant", bootstrap, "initech", "", entity.ErrValidation},
+		{"principal bound to a removed tenant", &entity.Principal{Tenant: "initech"}, "", "", entity.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = entity.WithPrincipal(ctx, tt.principal)
+			}
+			ctx, err := NewResolver(newStore()).Resolve(ctx, tt.requested)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Resolve = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := entity.TenantID(ctx); got != tt.want {
+				t.Errorf("tenant = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveStoreFailing(t *testing.T) {
+	store := &mapStore{err: errors.New("connection refused")}
+	_, err := NewResolver(store).Resolve(context.Background(), "acme")
+	if err == nil || errors.Is(err, entity.ErrValidation) {
+		t.Errorf("Resolve = %v, want the store error", err)
+	}
+}
+
+func TestResolveCachesTenants(t *testing.T) {
+	store := newStore()
+	resolver := NewResolver(store)
+	for range 3 {
+		if _, err := resolver.Resolve(context.Background(), "acme"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.gets != 1 {
+		t.Errorf("store read %d times, want 1", store.gets)
+	}
+
+	resolver.Forget("acme")
+	if _, err := resolver.Resolve(context.Background(), "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if store.gets != 2 {
+		t.Errorf("store read %d times after Forget, want 2", store.gets)
+	}
+}
@@ -0,0 +1,155 @@
+// Package translit converts names written in Cyrillic or Greek to the Latin
+// alphabet, so enrichment APIs tuned for Latin input can match them.
+package translit
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Scheme is a transliteration system
+type Scheme string
+
+const (
+	// ISO9 is ISO 9:1995, reversible and one letter per letter, with diacritics
+	ISO9 Scheme = "iso9"
+	// BGN is the BGN/PCGN romanization used for geographic and personal names
+	BGN Scheme = "bgn"
+	// ICAO is the ASCII only scheme of ICAO Doc 9303 machine readable passports
+	ICAO Scheme = "icao"
+)
+
+// ParseScheme returns the scheme with the given name
+func ParseScheme(name string) (Scheme, error) {
+	switch scheme := Scheme(strings.ToLower(strings.TrimSpace(name))); scheme {
+	case ISO9, BGN, ICAO:
+		return scheme, nil
+	}
+	return "", fmt.Errorf("unknown transliteration scheme %q, expected iso9, bgn or icao", name)
+}
+
+// letters every scheme writes the same way
+var common = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'д': "d", 'з': "z", 'и': "i", 'к': "k",
+	'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s",
+	'т': "t", 'у': "u", 'ф': "f",
+
+	// Greek, ELOT 743 as used in Greek passports
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+var tables = map[Scheme]map[rune]string{
+	ISO9: {
+		'г': "g", 'е': "e", 'ё': "ë", 'ж': "ž", 'й': "j", 'х': "h", 'ц': "c",
+		'ч': "č", 'ш': "š", 'щ': "ŝ", 'ъ': "ʺ", 'ы': "y", 'ь': "ʹ", 'э': "è",
+		'ю': "û", 'я': "â",
+		// Ukrainian and Belarusian
+		'ґ': "g̀", 'є': "ê", 'і': "ì", 'ї': "ï", 'ў': "ŭ",
+	},
+	BGN: {
+		'г': "g", 'е': "e", 'ё': "ë", 'ж': "zh", 'й': "y", 'х': "kh", 'ц': "ts",
+		'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e",
+		'ю': "yu", 'я': "ya",
+		'ґ': "g", 'є': "ye", 'і': "i", 'ї': "yi", 'ў': "w",
+	},
+	ICAO: {
+		'г': "g", 'е': "e", 'ё': "e", 'ж': "zh", 'й': "i", 'х': "kh", 'ц': "ts",
+		'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e",
+		'ю': "iu", 'я': "ia",
+		'ґ': "g", 'є': "ie", 'і': "i", 'ї': "i", 'ў': "u",
+	},
+}
+
+// IsLatin reports whether s has no letters outside the Latin script
+func IsLatin(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Transliterate converts s to Latin with the given scheme. Latin letters and
+// other characters pass through unchanged, letters of scripts without a table
+// are kept as well. BGN drops the soft and hard signs, which it writes as
+// apostrophes in place names, as is usual for personal names.
+func Transliterate(s string, scheme Scheme) string {
+	table, ok := tables[scheme]
+	if !ok {
+		table = tables[BGN]
+	}
+
+	// Greek tonos and dialytika are dropped before the lookup
+	runes := []rune(norm.NFC.String(stripGreekAccents(s)))
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i, r := range runes {
+		lower := unicode.ToLower(r)
+		latin, ok := table[lower]
+		if !ok {
+			latin, ok = common[lower]
+		}
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+
+		// BGN writes е and ё as ye and yë at the start of a word and after
+		// vowels or signs
+		if scheme == BGN && (lower == 'е' || lower == 'ё') && (i == 0 || isIotating(runes[i-1])) {
+			latin = "y" + latin
+		}
+
+		switch {
+		case latin == "" || !unicode.IsUpper(r):
+		case isUpperWord(runes, i):
+			latin = strings.ToUpper(latin)
+		default:
+			first := []rune(latin)
+			first[0] = unicode.ToUpper(first[0])
+			latin = string(first)
+		}
+		b.WriteString(latin)
+	}
+	return norm.NFC.String(b.String())
+}
+
+// isIotating reports whether a BGN е after r is written ye
+func isIotating(r rune) bool {
+	return !unicode.IsLetter(r) || strings.ContainsRune("аеёиоуыэюяйъьАЕЁИОУЫЭЮЯЙЪЬ", r)
+}
+
+// isUpperWord reports whether the letter at i is part of a word written in
+// capitals, so multi-letter replacements are capitalized as a whole
+func isUpperWord(runes []rune, i int) bool {
+	if i+1 < len(runes) && unicode.IsLetter(runes[i+1]) {
+		return unicode.IsUpper(runes[i+1])
+	}
+	if i > 0 && unicode.IsLetter(runes[i-1]) {
+		return unicode.IsUpper(runes[i-1])
+	}
+	return false
+}
+
+// stripGreekAccents removes the accents of Greek letters, Cyrillic letters
+// such as ё and й keep theirs
+func stripGreekAccents(s string) string {
+	var b strings.Builder
+	greek := false
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) && greek {
+			continue
+		}
+		greek = unicode.Is(unicode.Greek, r)
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package translit
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		scheme Scheme
+		in     string
+		want   string
+	}{
+		{BGN, "Щербаков", "Shcherbakov"},
+		{BGN, "Евгений", "Yevgeniy"},
+		{BGN, "Алексей", "Aleksey"},
+		{BGN, "Пётр", "Pëtr"},
+		{BGN, "Юлия Ильина", "Yuliya Ilina"},
+		{BGN, "ЖУКОВ", "ZHUKOV"},
+		{BGN, "Жуков", "Zhukov"},
+		{BGN, "ОЛЬГА", "OLGA"},
+		{BGN, "Їжак", "Yizhak"},
+
+		{ISO9, "Щербаков", "Ŝerbakov"},
+		{ISO9, "Жанна", "Žanna"},
+		{ISO9, "Юлия", "Ûliâ"},
+		{ISO9, "Ольга", "Olʹga"},
+
+		{ICAO, "Юлия", "Iuliia"},
+		{ICAO, "Пётр", "Petr"},
+		{ICAO, "Подъячев", "Podieiachev"},
+		{ICAO, "Андрей", "Andrei"},
+
+		// Greek is the same in every scheme, accents are dropped
+		{BGN, "Γιώργος", "Giorgos"},
+		{ICAO, "Θεοδωρής", "Theodoris"},
+
+		// Latin text and other characters pass through
+		{BGN, "John O'Neil-Smith", "John O'Neil-Smith"},
+		{BGN, "Ivan Иванов", "Ivan Ivanov"},
+		{BGN, "", ""},
+
+		// Unknown schemes fall back to BGN
+		{Scheme("gost"), "Щука", "Shchuka"},
+	}
+	for _, tt := range tests {
+		if got := Transliterate(tt.in, tt.scheme); got != tt.want {
+			t.Errorf("Transliterate(%q, %s) = %q, want %q", tt.in, tt.scheme, got, tt.want)
+		}
+	}
+}
+
+func TestIsLatin(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"Ivan", true},
+		{"José", true},
+		{"O'Neil-Smith 2", true},
+		{"", true},
+		{"Иван", false},
+		{"Ivan Иванов", false},
+		{"Γιώργος", false},
+	}
+	for _, tt := range tests {
+		if got := IsLatin(tt.in); got != tt.want {
+			t.Errorf("IsLatin(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseScheme(t *testing.T) {
+	for name, want := range map[string]Scheme{"iso9": ISO9, " BGN ": BGN, "Icao": ICAO} {
+		if got, err := ParseScheme(name); err != nil || got != want {
+			t.Errorf("ParseScheme(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseScheme("gost"); err == nil {
+		t.Error("unknown scheme was accepted")
+	}
+}
@@ -0,0 +1,149 @@
+// Package validation normalizes and checks person input before it is stored
+// or sent to the enrichment APIs.
+package validation
+
+import (
+	"fmt"
+	"people-enricher/internal/entity"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Name length limits, in characters after normalization
+const (
+	MinNameLength = 1
+	MaxNameLength = 100
+)
+
+// NormalizePerson normalizes the name fields of p in place and validates
+// them. All invalid fields are reported together in an entity.ValidationError.
+// An empty patronymic is cleared.
+func NormalizePerson(p *entity.Person) error {
+	invalid := &entity.ValidationError{}
+
+	p.Name = NormalizeName(p.Name)
+	checkName(invalid, "name", p.Name)
+
+	p.Surname = NormalizeName(p.Surname)
+	checkName(invalid, "surname", p.Surname)
+
+	if p.Patronymic != nil {
+		patronymic := NormalizeName(*p.Patronymic)
+		if patronymic == "" {
+			p.Patronymic = nil
+		} else {
+			p.Patronymic = &patronymic
+			checkName(invalid, "patronymic", patronymic)
+		}
+	}
+
+	return invalid.Err()
+}
+
+// NormalizeName converts a name to NFC, trims it, collapses inner whitespace
+// to single spaces and title cases words written entirely in one case.
+// Mixed case words such as "McDonald" are kept as typed.
+func NormalizeName(name string) string {
+	name = norm.NFC.String(name)
+	name = strings.Join(strings.Fields(name), " ")
+	return titleCase(name)
+}
+
+func checkName(invalid *entity.ValidationError, field, value string) {
+	if value == "" {
+		invalid.Add(field, "is required")
+		return
+	}
+
+	length := utf8.RuneCountInString(value)
+	if length < MinNameLength || length > MaxNameLength {
+		invalid.Add(field, fmt.Sprintf("must be between %d and %d characters long", MinNameLength, MaxNameLength))
+		return
+	}
+
+	if msg := checkCharacters(value); msg != "" {
+		invalid.Add(field, msg)
+	}
+}
+
+// checkCharacters allows letters of any script with their combining marks,
+// separated by single spaces, hyphens or apostrophes
+func checkCharacters(value string) string {
+	var prev rune
+	for i, r := range value {
+		switch {
+		case unicode.IsLetter(r):
+		case unicode.Is(unicode.Mn, r):
+			if i == 0 {
+				return "must start with a letter"
+			}
+		case isSeparator(r):
+			if i == 0 {
+				return "must start with a letter"
+			}
+			if isSeparator(prev) {
+				return "must not contain consecutive separators"
+			}
+		case unicode.IsDigit(r):
+			return "must not contain digits"
+		default:
+			return fmt.Sprintf("contains a character that is not allowed: %q", r)
+		}
+		prev = r
+	}
+	if isSeparator(prev) {
+		return "must end with a letter"
+	}
+	return ""
+}
+
+func isSeparator(r rune) bool {
+	return r == ' ' || r == '-' || r == '\'' || r == '’'
+}
+
+// titleCase capitalizes every space or hyphen separated word that is written
+// all in lower or all in upper case. A one letter prefix before an
+// apostrophe starts a new word, so "o'neil" becomes "O'Neil".
+func titleCase(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+
+	word := []rune{}
+	flush := func() {
+		b.WriteString(titleWord(word))
+		word = word[:0]
+	}
+	for _, r := range name {
+		if r == ' ' || r == '-' {
+			flush()
+			b.WriteRune(r)
+			continue
+		}
+		word = append(word, r)
+	}
+	flush()
+	return b.String()
+}
+
+func titleWord(word []rune) string {
+	hasUpper, hasLower := false, false
+	for _, r := range word {
+		hasUpper = hasUpper || unicode.IsUpper(r)
+		hasLower = hasLower || unicode.IsLower(r)
+	}
+	if hasUpper && hasLower {
+		return string(word)
+	}
+
+	// A Caser is not safe for concurrent use, so each call gets its own
+	title := cases.Title(language.Und)
+	if len(word) > 3 && (word[1] == '\'' || word[1] == '’') {
+		return title.String(string(word[:2])) + title.String(string(word[2:]))
+	}
+	return title.String(string(word))
+}
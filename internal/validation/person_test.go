@@ -0,0 +1,97 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"people-enricher/internal/entity"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"  ivan  ", "Ivan"},
+		{"anna   maria", "Anna Maria"},
+		{"IVANOV-PETROV", "Ivanov-Petrov"},
+		{"иван", "Иван"},
+		{"McDonald", "McDonald"},
+		{"o'neil", "O'Neil"},
+		{"d’artagnan", "D’Artagnan"},
+		// Decomposed "é" is composed
+		{"Rene\u0301", "Ren\u00e9"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.name); got != tt.want {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePersonRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		message string
+	}{
+		{"empty", "   ", "is required"},
+		{"too long", strings.Repeat("a", MaxNameLength+1), "must be between 1 and 100 characters long"},
+		{"digits", "Ivan2", "must not contain digits"},
+		{"leading separator", "-Ivan", "must start with a letter"},
+		{"leading combining mark", "\u0301Ivan", "must start with a letter"},
+		{"consecutive separators", "Anna--Maria", "must not contain consecutive separators"},
+		{"trailing separator", "Ivan'", "must end with a letter"},
+		{"markup", "<script>", `contains a character that is not allowed: '<'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			person := &entity.Person{Name: tt.value, Surname: "Ivanov"}
+			err := NormalizePerson(person)
+
+			var invalid *entity.ValidationError
+			if !errors.As(err, &invalid) || !errors.Is(err, entity.ErrValidation) {
+				t.Fatalf("NormalizePerson = %v, want a validation error", err)
+			}
+			if len(invalid.Fields) != 1 || invalid.Fields[0].Field != "name" || invalid.Fields[0].Message != tt.message {
+				t.Errorf("fields = %+v, want name %q", invalid.Fields, tt.message)
+			}
+		})
+	}
+}
+
+func TestNormalizePersonReportsAllFields(t *testing.T) {
+	patronymic := "Ivan0vich"
+	err := NormalizePerson(&entity.Person{Name: "", Surname: "Petrov!", Patronymic: &patronymic})
+
+	var invalid *entity.ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("NormalizePerson = %v, want a validation error", err)
+	}
+	var fields []string
+	for _, field := range invalid.Fields {
+		fields = append(fields, field.Field)
+	}
+	if strings.Join(fields, ",") != "name,surname,patronymic" {
+		t.Errorf("invalid fields = %v, want name, surname and patronymic", fields)
+	}
+}
+
+func TestNormalizePerson(t *testing.T) {
+	patronymic := "  "
+	person := &entity.Person{Name: " ivan ", Surname: "PETROV", Patronymic: &patronymic}
+	if err := NormalizePerson(person); err != nil {
+		t.Fatal(err)
+	}
+	if person.Name != "Ivan" || person.Surname != "Petrov" {
+		t.Errorf("person = %s %s, want Ivan Petrov", person.Name, person.Surname)
+	}
+	// A blank patronymic is no patronymic
+	if person.Patronymic != nil {
+		t.Errorf("patronymic = %q, want none", *person.Patronymic)
+	}
+}
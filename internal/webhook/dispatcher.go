@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"context"
+	"people-enricher/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Store persists webhook deliveries
+type Store interface {
+	EnqueueDeliveries(ctx context.Context, event entity.Event) (int64, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.DueDelivery, error)
+	MarkSucceeded(ctx context.Context, id int64, responseStatus int) error
+	MarkFailed(ctx context.Context, id int64, responseStatus *int, reason string, nextAttempt *time.Time) error
+}
+
+// Dispatcher is an outbox sink that turns person events into pending
+// deliveries for subscribed webhooks. Sending is done by Worker.
+type Dispatcher struct {
+	store Store
+	log   *logrus.Entry
+}
+
+func NewDispatcher(store Store, log *logrus.Entry) *Dispatcher {
+	return &Dispatcher{
+		store: store,
+		log:   log.WithField("component", "webhook_dispatcher"),
+	}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, event entity.Event) error {
+	enqueued, err := d.store.EnqueueDeliveries(ctx, event)
+	if err != nil {
+		return err
+	}
+	if enqueued > 0 {
+		d.log.WithFields(logrus.Fields{
+			"event_id":   event.ID,
+			"event_type": event.Type,
+			"deliveries": enqueued,
+		}).Debug("Webhook deliveries enqueued")
+	}
+	return nil
+}
+
+func (d *Dispatcher) Close() error {
+	return nil
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrForbiddenTarget is returned for webhook targets in internal networks
+var ErrForbiddenTarget = errors.New("webhook target address is not allowed")
+
+// reservedNetworks are special purpose networks not covered by the netip
+// predicates, reaching them would let subscribers probe internal services
+var reservedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// Targets decides where webhooks may be sent. Loopback, link-local, private
+// and reserved addresses are refused unless AllowPrivate is set, both when a
+// webhook is registered and when a delivery connects, so a host name later
+// resolving to an internal address is refused too.
+type Targets struct {
+	AllowPrivate bool
+}
+
+// Allowed reports whether deliveries may connect to addr
+func (t Targets) Allowed(addr netip.Addr) bool {
+	if t.AllowPrivate {
+		return true
+	}
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL validates a webhook URL: it must be an absolute http(s) URL whose
+// host resolves to allowed addresses only
+func (t Targets) CheckURL(ctx context.Context, raw string) error {
+	target, err := url.Parse(raw)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return fmt.Errorf("must be an absolute http(s) URL")
+	}
+	if t.AllowPrivate {
+		return nil
+	}
+
+	host := target.Hostname()
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrForbiddenTarget
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("host %s can not be resolved", host)
+	}
+	for _, addr := range addrs {
+		if !t.Allowed(addr) {
+			return ErrForbiddenTarget
+		}
+	}
+	return nil
+}
+
+// control refuses connections to addresses that are not allowed. It runs
+// after name resolution, for every address dialed.
+func (t Targets) control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("parsing dialed address %s: %w", address, err)
+	}
+	if !t.Allowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, addrPort.Addr())
+	}
+	return nil
+}
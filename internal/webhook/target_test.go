@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"people-enricher/internal/config"
+	"people-enricher/internal/entity"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestTargetsAllowed(t *testing.T) {
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"100.64.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+		{"224.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := (Targets{}).Allowed(netip.MustParseAddr(tt.addr)); got != tt.allowed {
+				t.Errorf("Allowed(%s) = %v, want %v", tt.addr, got, tt.allowed)
+			}
+			if !(Targets{AllowPrivate: true}).Allowed(netip.MustParseAddr(tt.addr)) {
+				t.Errorf("Allowed(%s) = false with AllowPrivate", tt.addr)
+			}
+		})
+	}
+}
+
+func TestTargetsCheckURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		forbidden bool
+		invalid   bool
+	}{
+		{url: "https://93.184.216.34/hook"},
+		{url: "http://127.0.0.1:8080/hook", forbidden: true},
+		{url: "http://localhost/hook", forbidden: true},
+		{url: "http://api.localhost/hook", forbidden: true},
+		{url: "http://169.254.169.254/latest/meta-data", forbidden: true},
+		{url: "http://[::1]/hook", forbidden: true},
+		{url: "http://10.0.0.5/hook", forbidden: true},
+		{url: "ftp://93.184.216.34/hook", invalid: true},
+		{url: "file:///etc/passwd", invalid: true},
+		{url: "/relative", invalid: true},
+		{url: "http://", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := (Targets{}).CheckURL(context.Background(), tt.url)
+			switch {
+			case tt.forbidden && !errors.Is(err, ErrForbiddenTarget):
+				t.Errorf("CheckURL(%q) = %v, want ErrForbiddenTarget", tt.url, err)
+			case tt.invalid && (err == nil || errors.Is(err, ErrForbiddenTarget)):
+				t.Errorf("CheckURL(%q) = %v, want a validation error", tt.url, err)
+			case !tt.forbidden && !tt.invalid && err != nil:
+				t.Errorf("CheckURL(%q) = %v, want nil", tt.url, err)
+			}
+		})
+	}
+}
+
+func TestWorkerRefusesPrivateTargets(t *testing.T) {
+	hit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer server.Close()
+
+	worker := NewWorker(nil, config.WebhookCfg{Timeout: time.Second}, logrus.NewEntry(logrus.New()))
+	_, err := worker.send(context.Background(), &entity.DueDelivery{URL: server.URL, Payload: []byte("{}")})
+	if !errors.Is(err, ErrForbiddenTarget) {
+		t.Fatalf("send to %s = %v, want ErrForbiddenTarget", server.URL, err)
+	}
+	if hit {
+		t.Fatal("the private target was reached")
+	}
+
+	worker = NewWorker(nil, config.WebhookCfg{Timeout: time.Second, AllowPrivateTargets: true}, logrus.NewEntry(logrus.New()))
+	if _, err := worker.send(context.Background(), &entity.DueDelivery{URL: server.URL, Payload: []byte("{}")}); err != nil {
+		t.Fatalf("send with AllowPrivateTargets = %v", err)
+	}
+	if !hit {
+		t.Fatal("the target was not reached with AllowPrivateTargets")
+	}
+}
@@ -0,0 +1,193 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"people-enricher/internal/config"
+	"people-enricher/internal/entity"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign returns the signature header value for a payload: the hex HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the webhook secret, prefixed by "sha256=".
+// Receivers recompute it and should reject stale timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Worker sends due deliveries and schedules retries with exponential backoff.
+// After MaxAttempts failed attempts a delivery is moved to the dead state.
+type Worker struct {
+	store      Store
+	httpClient *http.Client
+	cfg        config.WebhookCfg
+	log        *logrus.Entry
+}
+
+func NewWorker(store Store, cfg config.WebhookCfg, log *logrus.Entry) *Worker {
+	targets := Targets{AllowPrivate: cfg.AllowPrivateTargets}
+	dialer := &net.Dialer{
+		Timeout: cfg.Timeout,
+		Control: targets.control,
+	}
+	return &Worker{
+		store: store,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+			// No proxy: the dialer has to see the subscriber's address
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: cfg.Timeout,
+				MaxIdleConnsPerHost: 2,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			// Redirects are answers like any other, not followed
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg: cfg,
+		log: log.WithField("component", "webhook_worker"),
+	}
+}
+
+// Run sends deliveries until ctx is cancelled. The batch in progress is
+// finished first, so its outcomes are recorded.
+func (w *Worker) Run(ctx context.Context) {
+	w.log.WithField("interval", w.cfg.PollInterval).Info("Starting webhook worker")
+
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := w.DeliverOnce(context.WithoutCancel(ctx))
+		if err != nil {
+			w.log.WithError(err).Error("Failed to deliver webhooks")
+		}
+		if err == nil && sent == w.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			w.log.Info("Webhook worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverOnce sends one batch of due deliveries and returns its size
+func (w *Worker) DeliverOnce(ctx context.Context) (int, error) {
+	// Deliveries are sent one after another, so the lease covers the whole
+	// batch timing out. A crashed worker's deliveries become due again once
+	// it runs out instead of getting stuck.
+	due, err := w.store.ClaimDue(ctx, w.cfg.BatchSize, w.lease())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range due {
+		w.deliver(ctx, delivery)
+	}
+	return len(due), nil
+}
+
+func (w *Worker) deliver(ctx context.Context, delivery *entity.DueDelivery) {
+	logger := w.log.WithFields(logrus.Fields{
+		"delivery_id": delivery.ID,
+		"webhook_id":  delivery.WebhookID,
+		"event_type":  delivery.EventType,
+		"attempt":     delivery.Attempts + 1,
+	})
+
+	status, err := w.send(ctx, delivery)
+	if err == nil {
+		if err := w.store.MarkSucceeded(ctx, delivery.ID, status); err != nil {
+			logger.WithError(err).Error("Failed to record webhook delivery")
+		}
+		logger.Debug("Webhook delivered")
+		return
+	}
+
+	var responseStatus *int
+	if status != 0 {
+		responseStatus = &status
+	}
+
+	var nextAttempt *time.Time
+	if delivery.Attempts+1 < w.cfg.MaxAttempts {
+		next := time.Now().Add(w.backoff(delivery.Attempts))
+		nextAttempt = &next
+		logger.WithError(err).WithField("next_attempt_at", next).Warn("Webhook delivery failed, will retry")
+	} else {
+		logger.WithError(err).Error("Webhook delivery failed permanently")
+	}
+
+	if err := w.store.MarkFailed(ctx, delivery.ID, responseStatus, err.Error(), nextAttempt); err != nil {
+		logger.WithError(err).Error("Failed to record webhook delivery")
+	}
+}
+
+func (w *Worker) send(ctx context.Context, delivery *entity.DueDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("creating request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "people-enricher-webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// lease is how long claimed deliveries are reserved for this worker
+func (w *Worker) lease() time.Duration {
+	return time.Duration(w.cfg.BatchSize)*w.cfg.Timeout + time.Minute
+}
+
+// backoff doubles the retry delay with every attempt up to RetryMax
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.cfg.RetryBase
+	for i := 0; i < attempts && delay < w.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, w.cfg.RetryMax)
+}
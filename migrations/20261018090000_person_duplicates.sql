@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_people_full_name_trgm ON people
+    USING gin ((lower(name || ' ' || surname || ' ' || coalesce(patronymic, ''))) gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS person_merges(
+    id SERIAL PRIMARY KEY,
+    source_id INT NOT NULL UNIQUE,
+    target_id INT NOT NULL,
+    source_snapshot JSONB NOT NULL,
+    merged_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_merges_target_id ON person_merges(target_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS person_merges;
+DROP INDEX IF EXISTS idx_people_full_name_trgm;
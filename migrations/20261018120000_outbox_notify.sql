@@ -0,0 +1,30 @@
+-- +goose Up
+-- IDs of concurrent transactions commit out of order, so streams resume from
+-- seq instead: it is assigned under a lock to events already committed, a
+-- higher seq is never visible before a lower one.
+CREATE SEQUENCE IF NOT EXISTS outbox_events_seq;
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS seq BIGINT UNIQUE;
+UPDATE outbox_events o SET seq = s.seq
+FROM (SELECT id, nextval('outbox_events_seq') AS seq FROM (SELECT id FROM outbox_events ORDER BY id) pending) s
+WHERE o.id = s.id;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unsequenced ON outbox_events(id) WHERE seq IS NULL;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_person_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('person_events', NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER outbox_events_notify
+    AFTER INSERT ON outbox_events
+    FOR EACH ROW EXECUTE FUNCTION notify_person_event();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS outbox_events_notify ON outbox_events;
+DROP FUNCTION IF EXISTS notify_person_event();
+DROP INDEX IF EXISTS idx_outbox_events_unsequenced;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS seq;
+DROP SEQUENCE IF EXISTS outbox_events_seq;
@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE people
+    ADD COLUMN IF NOT EXISTS name_latin TEXT,
+    ADD COLUMN IF NOT EXISTS surname_latin TEXT,
+    ADD COLUMN IF NOT EXISTS patronymic_latin TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_people_name_latin_trgm ON people USING gin (name_latin gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_people_surname_latin_trgm ON people USING gin (surname_latin gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_people_patronymic_latin_trgm ON people USING gin (patronymic_latin gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_people_patronymic_latin_trgm;
+DROP INDEX IF EXISTS idx_people_surname_latin_trgm;
+DROP INDEX IF EXISTS idx_people_name_latin_trgm;
+
+ALTER TABLE people
+    DROP COLUMN IF EXISTS patronymic_latin,
+    DROP COLUMN IF EXISTS surname_latin,
+    DROP COLUMN IF EXISTS name_latin;
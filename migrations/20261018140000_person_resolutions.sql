@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE people ADD COLUMN IF NOT EXISTS resolutions JSONB;
+
+-- +goose Down
+ALTER TABLE people DROP COLUMN IF EXISTS resolutions;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS api_key_usage(
+    provider VARCHAR(64) NOT NULL,
+    key_id VARCHAR(16) NOT NULL,
+    day DATE NOT NULL,
+    requests INT NOT NULL DEFAULT 0,
+    daily_limit INT NOT NULL DEFAULT 0,
+    exhausted_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    PRIMARY KEY (provider, key_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_usage_day ON api_key_usage(day);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_key_usage;
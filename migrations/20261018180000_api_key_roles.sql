@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'reader';
+
+-- +goose Down
+ALTER TABLE api_keys DROP COLUMN IF EXISTS role;
@@ -0,0 +1,826 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: person/v1/person.proto
+
+package personv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Person struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Id                     int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                   string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Surname                string                 `protobuf:"bytes,3,opt,name=surname,proto3" json:"surname,omitempty"`
+	Patronymic             *string                `protobuf:"bytes,4,opt,name=patronymic,proto3,oneof" json:"patronymic,omitempty"`
+	Age                    *int32                 `protobuf:"varint,5,opt,name=age,proto3,oneof" json:"age,omitempty"`
+	Gender                 *string                `protobuf:"bytes,6,opt,name=gender,proto3,oneof" json:"gender,omitempty"`
+	Nationality            *string                `protobuf:"bytes,7,opt,name=nationality,proto3,oneof" json:"nationality,omitempty"`
+	NationalityProbability *float64               `protobuf:"fixed64,8,opt,name=nationality_probability,json=nationalityProbability,proto3,oneof" json:"nationality_probability,omitempty"`
+	CreatedAt              *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt              *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Latin transliterations of names written in another script
+	NameLatin       *string `protobuf:"bytes,11,opt,name=name_latin,json=nameLatin,proto3,oneof" json:"name_latin,omitempty"`
+	SurnameLatin    *string `protobuf:"bytes,12,opt,name=surname_latin,json=surnameLatin,proto3,oneof" json:"surname_latin,omitempty"`
+	PatronymicLatin *string `protobuf:"bytes,13,opt,name=patronymic_latin,json=patronymicLatin,proto3,oneof" json:"patronymic_latin,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Person) Reset() {
+	*x = Person{}
+	mi := &file_person_v1_person_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Person) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Person) ProtoMessage() {}
+
+func (x *Person) ProtoReflect() protoreflect.Message {
+	mi := &file_person_v1_person_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Person.ProtoReflect.Descriptor instead.
+func (*Person) Descriptor() ([]byte, []int) {
+	return file_person_v1_person_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Person) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Person) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Person) GetSurname() string {
+	if x != nil {
+		return x.Surname
+	}
+	return ""
+}
+
+func (x *Person) GetPatronymic() string {
+	if x != nil && x.Patronymic != nil {
+		return *x.Patronymic
+	}
+	return ""
+}
+
+func (x *Person) GetAge() int32 {
+	if x != nil && x.Age != nil {
+		return *x.Age
+	}
+	return 0
+}
+
+func (x *Person) GetGender() string {
+	if x != nil && x.Gender != nil {
+		return *x.Gender
+	}
+	return ""
+}
+
+func (x *Person) GetNationality() string {
+	if x != nil && x.Nationality != nil {
+		return *x.Nationality
+	}
+	return ""
+}
+
+func (x *Person) GetNationalityProbability() float64 {
+	if x != nil && x.NationalityProbability != nil {
+		return *x.NationalityProbability
+	}
+	return 0
+}
+
+func (x *Person) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Person) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Person) GetNameLatin() string {
+	if x != nil && x.NameLatin != nil {
+		return *x.NameLatin
+	}
+	return ""
+}
+
+func (x *Person) GetSurnameLatin() string {
+	if x != nil && x.SurnameLatin != nil {
+		return *x.SurnameLatin
+	}
+	return ""
+}
+
+func (x *Person) GetPatronymicLatin() string {
+	if x != nil && x.PatronymicLatin != nil {
+		return *x.PatronymicLatin
+	}
+	return ""
+}
+
+type CreatePersonRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Name       string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Surname    string                 `protobuf:"bytes,2,opt,name=surname,proto3" json:"surname,omitempty"`
+	Patronymic *string                `protobuf:"bytes,3,opt,name=patronymic,proto3,oneof" json:"patronymic,omitempty"`
+	// Create even if possible duplicates exist.
+	Force         bool `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePersonRequest) Reset() {
+	*x = CreatePersonRequest{}
+	mi := &file_person_v1_person_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePersonRequest) ProtoMessage() {}
+
+func (x *CreatePersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_person_v1_person_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePersonRequest.ProtoReflect.Descriptor instead.
+func (*CreatePersonRequest) Descriptor() ([]byte, []int) {
+	return file_person_v1_person_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreatePersonRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreatePersonRequest) GetSurname() string {
+	if x != nil {
+		return x.Surname
+	}
+	return ""
+}
+
+func (x *CreatePersonRequest) GetPatronymic() string {
+	if x != nil && x.Patronymic != nil {
+		return *x.Patronymic
+	}
+	return ""
+}
+
+func (x *CreatePersonRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type GetPersonRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPersonRequest) Reset() {
+	*x = GetPersonRequest{}
+	mi := &file_person_v1_person_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPersonRequest) ProtoMessage() {}
+
+func (x *GetPersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_person_v1_person_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPersonRequest.ProtoReflect.Descriptor instead.
+func (*GetPersonRequest) Descriptor() ([]byte, []int) {
+	return file_person_v1_person_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetPersonRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type UpdatePersonRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Surname       string                 `protobuf:"bytes,3,opt,name=surname,proto3" json:"surname,omitempty"`
+	Patronymic    *string                `protobuf:"bytes,4,opt,name=patronymic,proto3,oneof" json:"patronymic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePersonRequest) Reset() {
+	*x = UpdatePersonRequest{}
+	mi := &file_person_v1_person_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePersonRequest) ProtoMessage() {}
+
+func (x *UpdatePersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_person_v1_person_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePersonRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePersonRequest) Descriptor() ([]byte, []int) {
+	return file_person_v1_person_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UpdatePersonRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdatePersonRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdatePersonRequest) GetSurname() string {
+	if x != nil {
+		return x.Surname
+	}
+	return ""
+}
+
+func (x *UpdatePersonRequest) GetPatronymic() string {
+	if x != nil && x.Patronymic != nil {
+		return *x.Patronymic
+	}
+	return ""
+}
+
+type DeletePersonRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePersonRequest) Reset() {
+	*x = DeletePersonRequest{}
+	mi := &file_person_v1_person_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePersonRequest) ProtoMessage() {}
+
+func (x *DeletePersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_person_v1_person_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePersonRequest.ProtoReflect.Descriptor instead.
+func (*DeletePersonRequest) Descriptor() ([]byte, []int) {
+	return file_person_v1_person_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeletePersonRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeletePersonResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePersonResponse) Reset() {
+	*x = DeletePersonResponse{}
+	mi := &file_person_v1_person_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePersonResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePersonResponse) ProtoMessage() {}
+
+func (x *DeletePersonResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_person_v1_person_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePersonResponse.ProtoReflect.Descriptor instead.
+func (*DeletePersonResponse) Descriptor() ([]byte, []int) {
+	return file_person_v1_person_proto_rawDescGZIP(), []int{5}
+}
+
+type ListPersonsRequest struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	Name                      *string                `protobuf:"bytes,1,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Surname                   *string                `protobuf:"bytes,2,opt,name=surname,proto3,oneof" json:"surname,omitempty"`
+	Patronymic                *string                `protobuf:"bytes,3,opt,name=patronymic,proto3,oneof" json:"patronymic,omitempty"`
+	Gender                    []string               `protobuf:"bytes,4,rep,name=gender,proto3" json:"gender,omitempty"`
+	Nationality               []string               `protobuf:"bytes,5,rep,name=nationality,proto3" json:"nationality,omitempty"`
+	AgeMin                    *int32                 `protobuf:"varint,6,opt,name=age_min,json=ageMin,proto3,oneof" json:"age_min,omitempty"`
+	AgeMax                    *int32                 `protobuf:"varint,7,opt,name=age_max,json=ageMax,proto3,oneof" json:"age_max,omitempty"`
+	NationalityProbabilityMin *float64               `protobuf:"fixed64,8,opt,name=nationality_probability_min,json=nationalityProbabilityMin,proto3,oneof" json:"nationality_probability_min,omitempty"`
+	NationalityProbabilityMax *float64               `protobuf:"fixed64,9,opt,name=nationality_probability_max,json=nationalityProbabilityMax,proto3,oneof" json:"nationality_probability_max,omitempty"`
+	CreatedAfter              *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore             *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	UpdatedAfter              *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=updated_after,json=updatedAfter,proto3" json:"updated_after,omitempty"`
+	UpdatedBefore             *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_before,json=updatedBefore,proto3" json:"updated_before,omitempty"`
+	// Fields that must be null, e.g. "age" for people that were not enriched.
+	NullFields    []string `protobuf:"bytes,14,rep,name=null_fields,json=nullFields,proto3" json:"null_fields,omitempty"`
+	NotNullFields []string `protobuf:"bytes,15,rep,name=not_null_fields,json=notNullFields,proto3" json:"not_null_fields,omitempty"`
+	Page          int32    `protobuf:"varint,16,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32    `protobuf:"varint,17,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Sort field, e.g. "age"; prefix with "-" for descending. Newest first by default.
+	Sort          string `protobuf:"bytes,18,opt,name=sort,proto3" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPersonsRequest) Reset() {
+	*x = ListPersonsRequest{}
+	mi := &file_person_v1_person_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPersonsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPersonsRequest) ProtoMessage() {}
+
+func (x *ListPersonsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_person_v1_person_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPersonsRequest.ProtoReflect.Descriptor instead.
+func (*ListPersonsRequest) Descriptor() ([]byte, []int) {
+	return file_person_v1_person_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListPersonsRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *ListPersonsRequest) GetSurname() string {
+	if x != nil && x.Surname != nil {
+		return *x.Surname
+	}
+	return ""
+}
+
+func (x *ListPersonsRequest) GetPatronymic() string {
+	if x != nil && x.Patronymic != nil {
+		return *x.Patronymic
+	}
+	return ""
+}
+
+func (x *ListPersonsRequest) GetGender() []string {
+	if x != nil {
+		return x.Gender
+	}
+	return nil
+}
+
+func (x *ListPersonsRequest) GetNationality() []string {
+	if x != nil {
+		return x.Nationality
+	}
+	return nil
+}
+
+func (x *ListPersonsRequest) GetAgeMin() int32 {
+	if x != nil && x.AgeMin != nil {
+		return *x.AgeMin
+	}
+	return 0
+}
+
+func (x *ListPersonsRequest) GetAgeMax() int32 {
+	if x != nil && x.AgeMax != nil {
+		return *x.AgeMax
+	}
+	return 0
+}
+
+func (x *ListPersonsRequest) GetNationalityProbabilityMin() float64 {
+	if x != nil && x.NationalityProbabilityMin != nil {
+		return *x.NationalityProbabilityMin
+	}
+	return 0
+}
+
+func (x *ListPersonsRequest) GetNationalityProbabilityMax() float64 {
+	if x != nil && x.NationalityProbabilityMax != nil {
+		return *x.NationalityProbabilityMax
+	}
+	return 0
+}
+
+func (x *ListPersonsRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *ListPersonsRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *ListPersonsRequest) GetUpdatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAfter
+	}
+	return nil
+}
+
+func (x *ListPersonsRequest) GetUpdatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedBefore
+	}
+	return nil
+}
+
+func (x *ListPersonsRequest) GetNullFields() []string {
+	if x != nil {
+		return x.NullFields
+	}
+	return nil
+}
+
+func (x *ListPersonsRequest) GetNotNullFields() []string {
+	if x != nil {
+		return x.NotNullFields
+	}
+	return nil
+}
+
+func (x *ListPersonsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListPersonsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListPersonsRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type EnrichPersonRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnrichPersonRequest) Reset() {
+	*x = EnrichPersonRequest{}
+	mi := &file_person_v1_person_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnrichPersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnrichPersonRequest) ProtoMessage() {}
+
+func (x *EnrichPersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_person_v1_person_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnrichPersonRequest.ProtoReflect.Descriptor instead.
+func (*EnrichPersonRequest) Descriptor() ([]byte, []int) {
+	return file_person_v1_person_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *EnrichPersonRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+var File_person_v1_person_proto protoreflect.FileDescriptor
+
+const file_person_v1_person_proto_rawDesc = "" +
+	"\n" +
+	"\x16person/v1/person.proto\x12\tperson.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xfc\x04\n" +
+	"\x06Person\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\asurname\x18\x03 \x01(\tR\asurname\x12#\n" +
+	"\n" +
+	"patronymic\x18\x04 \x01(\tH\x00R\n" +
+	"patronymic\x88\x01\x01\x12\x15\n" +
+	"\x03age\x18\x05 \x01(\x05H\x01R\x03age\x88\x01\x01\x12\x1b\n" +
+	"\x06gender\x18\x06 \x01(\tH\x02R\x06gender\x88\x01\x01\x12%\n" +
+	"\vnationality\x18\a \x01(\tH\x03R\vnationality\x88\x01\x01\x12<\n" +
+	"\x17nationality_probability\x18\b \x01(\x01H\x04R\x16nationalityProbability\x88\x01\x01\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\"\n" +
+	"\n" +
+	"name_latin\x18\v \x01(\tH\x05R\tnameLatin\x88\x01\x01\x12(\n" +
+	"\rsurname_latin\x18\f \x01(\tH\x06R\fsurnameLatin\x88\x01\x01\x12.\n" +
+	"\x10patronymic_latin\x18\r \x01(\tH\aR\x0fpatronymicLatin\x88\x01\x01B\r\n" +
+	"\v_patronymicB\x06\n" +
+	"\x04_ageB\t\n" +
+	"\a_genderB\x0e\n" +
+	"\f_nationalityB\x1a\n" +
+	"\x18_nationality_probabilityB\r\n" +
+	"\v_name_latinB\x10\n" +
+	"\x0e_surname_latinB\x13\n" +
+	"\x11_patronymic_latin\"\x8d\x01\n" +
+	"\x13CreatePersonRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\asurname\x18\x02 \x01(\tR\asurname\x12#\n" +
+	"\n" +
+	"patronymic\x18\x03 \x01(\tH\x00R\n" +
+	"patronymic\x88\x01\x01\x12\x14\n" +
+	"\x05force\x18\x04 \x01(\bR\x05forceB\r\n" +
+	"\v_patronymic\"\"\n" +
+	"\x10GetPersonRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x87\x01\n" +
+	"\x13UpdatePersonRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\asurname\x18\x03 \x01(\tR\asurname\x12#\n" +
+	"\n" +
+	"patronymic\x18\x04 \x01(\tH\x00R\n" +
+	"patronymic\x88\x01\x01B\r\n" +
+	"\v_patronymic\"%\n" +
+	"\x13DeletePersonRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x16\n" +
+	"\x14DeletePersonResponse\"\x83\a\n" +
+	"\x12ListPersonsRequest\x12\x17\n" +
+	"\x04name\x18\x01 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x1d\n" +
+	"\asurname\x18\x02 \x01(\tH\x01R\asurname\x88\x01\x01\x12#\n" +
+	"\n" +
+	"patronymic\x18\x03 \x01(\tH\x02R\n" +
+	"patronymic\x88\x01\x01\x12\x16\n" +
+	"\x06gender\x18\x04 \x03(\tR\x06gender\x12 \n" +
+	"\vnationality\x18\x05 \x03(\tR\vnationality\x12\x1c\n" +
+	"\aage_min\x18\x06 \x01(\x05H\x03R\x06ageMin\x88\x01\x01\x12\x1c\n" +
+	"\aage_max\x18\a \x01(\x05H\x04R\x06ageMax\x88\x01\x01\x12C\n" +
+	"\x1bnationality_probability_min\x18\b \x01(\x01H\x05R\x19nationalityProbabilityMin\x88\x01\x01\x12C\n" +
+	"\x1bnationality_probability_max\x18\t \x01(\x01H\x06R\x19nationalityProbabilityMax\x88\x01\x01\x12?\n" +
+	"\rcreated_after\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\x12?\n" +
+	"\rupdated_after\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\fupdatedAfter\x12A\n" +
+	"\x0eupdated_before\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\rupdatedBefore\x12\x1f\n" +
+	"\vnull_fields\x18\x0e \x03(\tR\n" +
+	"nullFields\x12&\n" +
+	"\x0fnot_null_fields\x18\x0f \x03(\tR\rnotNullFields\x12\x12\n" +
+	"\x04page\x18\x10 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x11 \x01(\x05R\bpageSize\x12\x12\n" +
+	"\x04sort\x18\x12 \x01(\tR\x04sortB\a\n" +
+	"\x05_nameB\n" +
+	"\n" +
+	"\b_surnameB\r\n" +
+	"\v_patronymicB\n" +
+	"\n" +
+	"\b_age_minB\n" +
+	"\n" +
+	"\b_age_maxB\x1e\n" +
+	"\x1c_nationality_probability_minB\x1e\n" +
+	"\x1c_nationality_probability_max\"%\n" +
+	"\x13EnrichPersonRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id2\xa9\x03\n" +
+	"\rPersonService\x12A\n" +
+	"\fCreatePerson\x12\x1e.person.v1.CreatePersonRequest\x1a\x11.person.v1.Person\x12;\n" +
+	"\tGetPerson\x12\x1b.person.v1.GetPersonRequest\x1a\x11.person.v1.Person\x12A\n" +
+	"\fUpdatePerson\x12\x1e.person.v1.UpdatePersonRequest\x1a\x11.person.v1.Person\x12O\n" +
+	"\fDeletePerson\x12\x1e.person.v1.DeletePersonRequest\x1a\x1f.person.v1.DeletePersonResponse\x12A\n" +
+	"\vListPersons\x12\x1d.person.v1.ListPersonsRequest\x1a\x11.person.v1.Person0\x01\x12A\n" +
+	"\fEnrichPerson\x12\x1e.person.v1.EnrichPersonRequest\x1a\x11.person.v1.PersonB,Z*people-enricher/pkg/api/person/v1;personv1b\x06proto3"
+
+var (
+	file_person_v1_person_proto_rawDescOnce sync.Once
+	file_person_v1_person_proto_rawDescData []byte
+)
+
+func file_person_v1_person_proto_rawDescGZIP() []byte {
+	file_person_v1_person_proto_rawDescOnce.Do(func() {
+		file_person_v1_person_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_person_v1_person_proto_rawDesc), len(file_person_v1_person_proto_rawDesc)))
+	})
+	return file_person_v1_person_proto_rawDescData
+}
+
+var file_person_v1_person_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_person_v1_person_proto_goTypes = []any{
+	(*Person)(nil),                // 0: person.v1.Person
+	(*CreatePersonRequest)(nil),   // 1: person.v1.CreatePersonRequest
+	(*GetPersonRequest)(nil),      // 2: person.v1.GetPersonRequest
+	(*UpdatePersonRequest)(nil),   // 3: person.v1.UpdatePersonRequest
+	(*DeletePersonRequest)(nil),   // 4: person.v1.DeletePersonRequest
+	(*DeletePersonResponse)(nil),  // 5: person.v1.DeletePersonResponse
+	(*ListPersonsRequest)(nil),    // 6: person.v1.ListPersonsRequest
+	(*EnrichPersonRequest)(nil),   // 7: person.v1.EnrichPersonRequest
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+}
+var file_person_v1_person_proto_depIdxs = []int32{
+	8,  // 0: person.v1.Person.created_at:type_name -> google.protobuf.Timestamp
+	8,  // 1: person.v1.Person.updated_at:type_name -> google.protobuf.Timestamp
+	8,  // 2: person.v1.ListPersonsRequest.created_after:type_name -> google.protobuf.Timestamp
+	8,  // 3: person.v1.ListPersonsRequest.created_before:type_name -> google.protobuf.Timestamp
+	8,  // 4: person.v1.ListPersonsRequest.updated_after:type_name -> google.protobuf.Timestamp
+	8,  // 5: person.v1.ListPersonsRequest.updated_before:type_name -> google.protobuf.Timestamp
+	1,  // 6: person.v1.PersonService.CreatePerson:input_type -> person.v1.CreatePersonRequest
+	2,  // 7: person.v1.PersonService.GetPerson:input_type -> person.v1.GetPersonRequest
+	3,  // 8: person.v1.PersonService.UpdatePerson:input_type -> person.v1.UpdatePersonRequest
+	4,  // 9: person.v1.PersonService.DeletePerson:input_type -> person.v1.DeletePersonRequest
+	6,  // 10: person.v1.PersonService.ListPersons:input_type -> person.v1.ListPersonsRequest
+	7,  // 11: person.v1.PersonService.EnrichPerson:input_type -> person.v1.EnrichPersonRequest
+	0,  // 12: person.v1.PersonService.CreatePerson:output_type -> person.v1.Person
+	0,  // 13: person.v1.PersonService.GetPerson:output_type -> person.v1.Person
+	0,  // 14: person.v1.PersonService.UpdatePerson:output_type -> person.v1.Person
+	5,  // 15: person.v1.PersonService.DeletePerson:output_type -> person.v1.DeletePersonResponse
+	0,  // 16: person.v1.PersonService.ListPersons:output_type -> person.v1.Person
+	0,  // 17: person.v1.PersonService.EnrichPerson:output_type -> person.v1.Person
+	12, // [12:18] is the sub-list for method output_type
+	6,  // [6:12] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_person_v1_person_proto_init() }
+func file_person_v1_person_proto_init() {
+	if File_person_v1_person_proto != nil {
+		return
+	}
+	file_person_v1_person_proto_msgTypes[0].OneofWrappers = []any{}
+	file_person_v1_person_proto_msgTypes[1].OneofWrappers = []any{}
+	file_person_v1_person_proto_msgTypes[3].OneofWrappers = []any{}
+	file_person_v1_person_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_person_v1_person_proto_rawDesc), len(file_person_v1_person_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_person_v1_person_proto_goTypes,
+		DependencyIndexes: file_person_v1_person_proto_depIdxs,
+		MessageInfos:      file_person_v1_person_proto_msgTypes,
+	}.Build()
+	File_person_v1_person_proto = out.File
+	file_person_v1_person_proto_goTypes = nil
+	file_person_v1_person_proto_depIdxs = nil
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"people-enricher/internal/config"
+	"people-enricher/internal/entity"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 )
@@ -28,6 +30,22 @@ func NewPool(ctx context.Context, cfg *config.DBCfg, logger *logrus.Entry) (*pgx
 		logger.WithError(err).Error("Failed to parse config")
 		return nil, fmt.Errorf("error parsing config: %w", err)
 	}
+	// Connections are handed out with the tenant of the acquiring context,
+	// for the row-level security policies to check against. The setting
+	// lasts for the session, it is replaced on every acquire. Contexts of
+	// background work without a tenant act on the default one unless marked
+	// by entity.WithAllTenants.
+	poolConfig.BeforeAcquire = func(ctx context.Context, conn *pgx.Conn) bool {
+		tenantID := entity.TenantID(ctx)
+		if entity.AllTenants(ctx) {
+			tenantID = "*"
+		}
+		if _, err := conn.Exec(ctx, "SELECT set_config('app.tenant_id', $1, false)", tenantID); err != nil {
+			logger.WithError(err).Warn("Failed to set tenant of connection")
+			return false
+		}
+		return true
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -39,6 +57,32 @@ func NewPool(ctx context.Context, cfg *config.DBCfg, logger *logrus.Entry) (*pgx
 		logger.WithError(err).Error("Failed to checking connect")
 		return nil, fmt.Errorf("checking connection db: %w", err)
 	}
+
+	if err := checkRowSecurity(ctx, pool, cfg, logger); err != nil {
+		pool.Close()
+		return nil, err
+	}
 	logger.Info("successfully connecting db using with pgx")
 	return pool, nil
 }
+
+// checkRowSecurity refuses roles the row-level security policies do not
+// apply to, as every tenant would see the data of the others
+func checkRowSecurity(ctx context.Context, pool *pgxpool.Pool, cfg *config.DBCfg, logger *logrus.Entry) error {
+	var bypass bool
+	err := pool.QueryRow(ctx,
+		"SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user",
+	).Scan(&bypass)
+	if err != nil {
+		logger.WithError(err).Error("Failed to check role of connection")
+		return fmt.Errorf("checking database role: %w", err)
+	}
+	if !bypass {
+		return nil
+	}
+	if !cfg.AllowRLSBypass {
+		return fmt.Errorf("database role %s is a superuser or has BYPASSRLS, connect as a role without them or set DB_ALLOW_RLS_BYPASS", cfg.User)
+	}
+	logger.WithField("username", cfg.User).Warn("Database role bypasses row-level security, tenants are not isolated")
+	return nil
+}
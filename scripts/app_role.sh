@@ -0,0 +1,23 @@
+#!/bin/bash
+
+set -e
+
+# Creates the role the API connects as, DB_USER with DB_PASSWORD. Unlike the
+# owner running the migrations it is no superuser and has no BYPASSRLS, so
+# the row-level security policies apply to it. Run after the migrations, and
+# again after those adding tables.
+
+echo "Creating application role...."
+
+source .env
+psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -v role="$DB_USER" -v password="$DB_PASSWORD" <<'SQL'
+SELECT NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = :'role') AS create_role \gset
+\if :create_role
+CREATE ROLE :"role" LOGIN NOSUPERUSER NOBYPASSRLS PASSWORD :'password';
+\endif
+GRANT USAGE ON SCHEMA public TO :"role";
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO :"role";
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO :"role";
+SQL
+
+echo "Application role created successfully"